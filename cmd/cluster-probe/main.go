@@ -4,8 +4,13 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
@@ -17,6 +22,7 @@ import (
 	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	"github.com/punasusi/cluster-probe/pkg/probe/report"
 	"github.com/punasusi/cluster-probe/pkg/probe/storage"
+	"github.com/punasusi/cluster-probe/pkg/server"
 	"github.com/punasusi/cluster-probe/pkg/setup"
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
@@ -30,6 +36,12 @@ const (
 	ExitInternalErr	= 4
 )
 
+var (
+	version	= "dev"
+	commit	= "none"
+	date	= "unknown"
+)
+
 var (
 	kubeconfig	string
 	noContainer	bool
@@ -37,8 +49,37 @@ var (
 	forceSetup	bool
 	outputFormat	string
 	noDiff		bool
+	noStore		bool
 	initConfig	bool
 	networkTest	bool
+	networkLatency	bool
+	templatePath	string
+	reportStyle	string
+	onlyChanged	bool
+	caCert		string
+	insecureTLS	bool
+	emitSummary	bool
+	formatVersion	int
+	groupBy		string
+	sortBy		string
+	includeOK	bool
+	redactClusterName	bool
+	clusterNameOverride	string
+	strictMode	bool
+	crdOnly		bool
+	baselinePath	string
+	setupNamespace	string
+	writeConfigMap	string
+	fixScriptPath	string
+	scanRecordPath	string
+	pushgatewayURL	string
+	pushgatewayJob	string
+	excludeNamespaces	[]string
+	maxResults	int
+	resourceBudget	int
+	waitForReady	time.Duration
+	serveAddr	string
+	serveInterval	time.Duration
 )
 
 func init() {
@@ -49,25 +90,174 @@ func init() {
 }
 
 func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		if stdoutFormat() == report.FormatJSON {
+			report.WriteError(os.Stdout, err.Error(), ExitInternalErr)
+		}
+		os.Exit(ExitInternalErr)
+	}
+}
+
+func newRootCommand() *cobra.Command {
 	rootCmd := &cobra.Command{
-		Use:	"cluster-probe",
-		Short:	"Kubernetes cluster diagnostic tool",
-		Long:	"A read-only diagnostic tool that analyzes Kubernetes cluster health and provides actionable remediation suggestions.",
-		RunE:	run,
+		Use:		"cluster-probe",
+		Short:		"Kubernetes cluster diagnostic tool",
+		Long:		"A read-only diagnostic tool that analyzes Kubernetes cluster health and provides actionable remediation suggestions.",
+		Version:	version,
+		RunE:		run,
 	}
+	rootCmd.SetVersionTemplate(versionInfo())
 
 	rootCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file")
 	rootCmd.Flags().BoolVar(&noContainer, "no-container", false, "Run without container isolation")
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.Flags().BoolVar(&includeOK, "include-ok", false, "Also list OK results in the non-verbose text report")
+	rootCmd.Flags().BoolVar(&redactClusterName, "redact-cluster-name", false, "Replace the cluster name in reports with a short hash")
+	rootCmd.Flags().StringVar(&clusterNameOverride, "cluster-name", "", "Name the cluster in reports and scan history instead of deriving it from the kubeconfig context")
+	rootCmd.Flags().BoolVar(&strictMode, "strict", false, "Report every warning as critical (affects the report and the exit code)")
+	rootCmd.Flags().BoolVar(&crdOnly, "crd-only", false, "Only scan custom resources for stalled state, skipping the core checks")
 	rootCmd.Flags().BoolVar(&forceSetup, "setup", false, "Force setup mode to create read-only credentials")
-	rootCmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text, json")
+	rootCmd.Flags().StringVar(&setupNamespace, "setup-namespace", setup.ServiceAccountNamespace, "Namespace for the read-only service account created by setup")
+	rootCmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output formats: text, json, comma-separated with optional :path (e.g. text,json:report.json)")
 	rootCmd.Flags().BoolVar(&noDiff, "no-diff", false, "Skip comparison with previous scan")
+	rootCmd.Flags().BoolVar(&noStore, "no-store", false, "Never read or write scan history under .probe (for read-only filesystems)")
+	rootCmd.Flags().StringArrayVar(&excludeNamespaces, "exclude-namespace", nil, "Ignore results from this namespace, in addition to ignore.namespaces in the config (repeatable)")
+	rootCmd.Flags().IntVar(&maxResults, "max-results", probe.DefaultMaxResults, "Maximum findings reported per check; the rest are summarized (0 for no limit)")
+	rootCmd.Flags().IntVar(&resourceBudget, "resource-budget", 0, "Maximum pods and events a single check may fetch; larger lists are sampled (0 for no limit)")
+	rootCmd.Flags().StringVar(&baselinePath, "baseline", "", "Compare against a saved scan file instead of the previous scan")
 	rootCmd.Flags().BoolVar(&initConfig, "init-config", false, "Create example config file at .probe/config.yaml")
 	rootCmd.Flags().BoolVar(&networkTest, "network-test", false, "Run network connectivity tests (creates temporary pods on each node)")
+	rootCmd.Flags().BoolVar(&networkLatency, "network-latency", false, "Also measure pod-to-pod round-trip latency during --network-test (adds runtime)")
+	rootCmd.Flags().StringVar(&caCert, "ca-cert", "", "Path to a CA certificate bundle for verifying the API server")
+	rootCmd.Flags().DurationVar(&waitForReady, "wait-for-ready", 0, "Retry connecting to the API server for up to this long before giving up (e.g. 2m)")
+	rootCmd.Flags().BoolVar(&insecureTLS, "insecure-skip-tls-verify", false, "Skip API server certificate verification (insecure)")
+	rootCmd.Flags().BoolVar(&onlyChanged, "only-changed", false, "Only save the scan when its issues differ from the last saved scan")
+	rootCmd.Flags().StringVar(&templatePath, "template", "", "Render the text report with a custom Go template file")
+	rootCmd.Flags().StringVar(&reportStyle, "report-style", "", "Report preset: compact (criticals only), detailed (full tree), ci (issues grouped by namespace)")
+	rootCmd.Flags().BoolVar(&emitSummary, "emit-summary-stderr", false, "Also print a one-line JSON severity summary to stderr")
+	rootCmd.Flags().IntVar(&formatVersion, "format-version", report.CurrentFormatVersion, "JSON report format version to emit (1 for the legacy shape)")
+	rootCmd.Flags().StringVar(&groupBy, "group-by", report.GroupByCheck, "Group the report by: check, namespace")
+	rootCmd.Flags().StringVar(&sortBy, "sort", report.SortSeverity, "Order results within each check by: severity, insertion")
+	rootCmd.Flags().StringVar(&fixScriptPath, "emit-fix-script", "", "Write the findings' remediation commands, commented out, to a shell script at this path")
+	rootCmd.Flags().StringVar(&scanRecordPath, "emit-scan-record", "", "Also write the fingerprinted scan record (the format stored in .probe/last-scan.json) to this path")
+	rootCmd.Flags().StringVar(&writeConfigMap, "write-configmap", "", "Also store the JSON report in a ConfigMap (namespace/name)")
+	rootCmd.Flags().StringVar(&pushgatewayURL, "pushgateway-url", "", "Push check metrics to a Prometheus Pushgateway at this URL")
+	rootCmd.Flags().StringVar(&pushgatewayJob, "pushgateway-job", report.DefaultPushgatewayJob, "Job label for metrics pushed to the Pushgateway")
+
+	rootCmd.AddCommand(newVersionCommand())
+	rootCmd.AddCommand(newServeCommand())
+	rootCmd.AddCommand(newCompareCommand())
+
+	return rootCmd
+}
 
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(ExitInternalErr)
+func newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:	"version",
+		Short:	"Print version and build information",
+		Args:	cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprint(cmd.OutOrStdout(), versionInfo())
+		},
+	}
+}
+
+func newServeCommand() *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:	"serve",
+		Short:	"Run continuously and expose results over HTTP",
+		Long:	"Re-scans the cluster on an interval and serves /healthz, /metrics (Prometheus) and /report (JSON of the latest scan).",
+		Args:	cobra.NoArgs,
+		RunE:	runServe,
+	}
+
+	serveCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to in-cluster credentials)")
+	serveCmd.Flags().StringVar(&caCert, "ca-cert", "", "Path to a CA certificate bundle for verifying the API server")
+	serveCmd.Flags().DurationVar(&waitForReady, "wait-for-ready", 0, "Retry connecting to the API server for up to this long before giving up (e.g. 2m)")
+	serveCmd.Flags().BoolVar(&insecureTLS, "insecure-skip-tls-verify", false, "Skip API server certificate verification (insecure)")
+	serveCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	serveCmd.Flags().BoolVar(&redactClusterName, "redact-cluster-name", false, "Replace the cluster name in /report with a short hash")
+	serveCmd.Flags().StringVar(&clusterNameOverride, "cluster-name", "", "Name the cluster in /report instead of deriving it from the kubeconfig context")
+	serveCmd.Flags().BoolVar(&strictMode, "strict", false, "Report every warning as critical")
+	serveCmd.Flags().StringArrayVar(&excludeNamespaces, "exclude-namespace", nil, "Ignore results from this namespace, in addition to ignore.namespaces in the config (repeatable)")
+	serveCmd.Flags().IntVar(&maxResults, "max-results", probe.DefaultMaxResults, "Maximum findings reported per check; the rest are summarized (0 for no limit)")
+	serveCmd.Flags().IntVar(&resourceBudget, "resource-budget", 0, "Maximum pods and events a single check may fetch; larger lists are sampled (0 for no limit)")
+	serveCmd.Flags().StringVar(&serveAddr, "addr", server.DefaultAddr, "Address for the HTTP server to listen on")
+	serveCmd.Flags().DurationVar(&serveInterval, "interval", server.DefaultInterval, "Time between scans")
+	serveCmd.Flags().BoolVar(&networkTest, "network-test", false, "Also run network connectivity tests on each scan (creates temporary pods on each node)")
+	serveCmd.Flags().BoolVar(&networkLatency, "network-latency", false, "Also measure pod-to-pod round-trip latency during --network-test")
+
+	return serveCmd
+}
+
+func newCompareCommand() *cobra.Command {
+	compareCmd := &cobra.Command{
+		Use:	"compare <old.json> <new.json>",
+		Short:	"Show what changed between two saved scans",
+		Long:	"Compares two saved scan files (.probe/last-scan.json or JSON reports from -o json) and prints new and resolved issues without contacting a cluster.",
+		Args:	cobra.ExactArgs(2),
+		RunE:	runCompare,
+	}
+
+	compareCmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text, json")
+
+	return compareCmd
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	previous, err := report.LoadScan(args[0])
+	if err != nil {
+		return err
+	}
+	current, err := report.LoadScan(args[1])
+	if err != nil {
+		return err
+	}
+
+	format := report.FormatText
+	if outputFormat == "json" {
+		format = report.FormatJSON
+	}
+
+	diff := storage.ComputeDiff(current, previous)
+	return report.NewWriter(cmd.OutOrStdout(), format, false).WriteComparison(previous, current, diff)
+}
+
+func versionInfo() string {
+	clientGoVersion := dependencyVersion("k8s.io/client-go")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "cluster-probe %s\n", version)
+	fmt.Fprintf(&b, "  Commit:      %s\n", commit)
+	fmt.Fprintf(&b, "  Built:       %s\n", date)
+	fmt.Fprintf(&b, "  Go version:  %s\n", runtime.Version())
+	fmt.Fprintf(&b, "  Platform:    %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "  client-go:   %s\n", clientGoVersion)
+	fmt.Fprintf(&b, "  Kubernetes:  %s\n", kubernetesVersion(clientGoVersion))
+	return b.String()
+}
+
+func dependencyVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
 	}
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
+
+func kubernetesVersion(clientGoVersion string) string {
+	if strings.HasPrefix(clientGoVersion, "v0.") {
+		return "v1." + strings.TrimPrefix(clientGoVersion, "v0.")
+	}
+	return "unknown"
 }
 
 func run(cmd *cobra.Command, args []string) error {
@@ -103,6 +293,80 @@ func run(cmd *cobra.Command, args []string) error {
 	return runProbe(ctx, false)
 }
 
+func runServe(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	store := storage.NewStorage("")
+	cfg, err := config.LoadConfig(store.ConfigPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load config: %v\n", err)
+		cfg = config.DefaultConfig()
+	}
+	cfg.AddIgnoredNamespaces(excludeNamespaces)
+	redactClusterName = redactClusterName || cfg.RedactClusterName
+
+	client, err := newClient(k8s.DiscoverKubeconfig(kubeconfig, false), false)
+	if err != nil {
+		exitWithError(ExitNoConnect, "Error: %v", err)
+	}
+	if err := testConnection(ctx, client); err != nil {
+		exitWithError(ExitNoConnect, "Error: %v", err)
+	}
+
+	clusterInfo := resolveClusterInfo(clusterNameOverride, func() (string, error) {
+		return client.ClusterInfo(ctx)
+	})
+
+	engine := probe.NewEngine(verbose)
+	engine.SetConfig(cfg)
+	engine.SetMaxResults(maxResults)
+	engine.SetResourceBudget(resourceBudget)
+	engine.SetDynamicClients(client.DynamicClient(), client.DiscoveryClient())
+	engine.SetMetadataClient(client.MetadataClient())
+	checks.RegisterDefaults(engine)
+
+	scan := func(ctx context.Context) ([]probe.CheckResult, error) {
+		results, err := engine.Run(ctx, client.Clientset())
+		if err != nil {
+			return nil, err
+		}
+
+		if networkTest {
+			nt := nettest.New(client.Clientset(), client.RESTConfig(), verbose, networkTestTimeouts(cfg))
+			nt.SetMeasureLatency(networkLatency)
+			testReport, err := nt.Run(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("network test failed: %w", err)
+			}
+			results = append(results, convertNetworkReport(testReport, cfg)...)
+		}
+
+		if strictMode {
+			escalateWarnings(results)
+		}
+		return results, nil
+	}
+
+	if redactClusterName {
+		clusterInfo = report.RedactClusterName(clusterInfo)
+	}
+
+	srv := server.New(serveAddr, serveInterval, clusterInfo, scan)
+	srv.SetVerbose(verbose)
+	if err := srv.Run(ctx); err != nil {
+		exitWithError(ExitInternalErr, "Error: %v", err)
+	}
+	return nil
+}
+
 func runProbe(ctx context.Context, inContainer bool) error {
 
 	store := storage.NewStorage("")
@@ -110,12 +374,10 @@ func runProbe(ctx context.Context, inContainer bool) error {
 	if initConfig {
 		configPath := store.ConfigPath()
 		if err := store.EnsureProbeDir(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating .probe directory: %v\n", err)
-			os.Exit(ExitInternalErr)
+			exitWithError(ExitInternalErr, "Error creating .probe directory: %v", err)
 		}
 		if err := config.SaveExample(configPath); err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating config file: %v\n", err)
-			os.Exit(ExitInternalErr)
+			exitWithError(ExitInternalErr, "Error creating config file: %v", err)
 		}
 		fmt.Printf("Created example config at: %s\n", configPath)
 		os.Exit(ExitOK)
@@ -133,17 +395,35 @@ func runProbe(ctx context.Context, inContainer bool) error {
 		return runNetworkTest(ctx, inContainer)
 	}
 
+	targets, err := parseOutputs(outputFormat)
+	if err != nil {
+		exitWithError(ExitInternalErr, "Error: %v", err)
+	}
+
+	var configMapNamespace, configMapName string
+	if writeConfigMap != "" {
+		var err error
+		configMapNamespace, configMapName, err = report.ParseConfigMapRef(writeConfigMap)
+		if err != nil {
+			exitWithError(ExitInternalErr, "Error: %v", err)
+		}
+	}
+
 	cfg, err := config.LoadConfig(store.ConfigPath())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to load config: %v\n", err)
 		cfg = config.DefaultConfig()
 	}
+	cfg.AddIgnoredNamespaces(excludeNamespaces)
+	redactClusterName = redactClusterName || cfg.RedactClusterName
 
-	var previousScan *storage.ScanRecord
-	if !noDiff {
-		previousScan, err = store.LoadLastScan()
-		if err != nil && verbose {
-			fmt.Fprintf(os.Stderr, "Warning: failed to load previous scan: %v\n", err)
+	lastScan := loadLastScan(store)
+
+	previousScan := lastScan
+	if baselinePath != "" && !crdOnly {
+		previousScan, err = storage.LoadScanFile(k8s.ResolveHostPath(baselinePath, inContainer))
+		if err != nil {
+			exitWithError(ExitInternalErr, "Error: %v", err)
 		}
 	}
 
@@ -151,93 +431,118 @@ func runProbe(ctx context.Context, inContainer bool) error {
 		fmt.Fprintf(os.Stderr, "Using probe kubeconfig: %s\n", probeKubeconfigPath)
 	}
 
-	client, err := k8s.NewClient(probeKubeconfigPath)
+	client, err := newClient(probeKubeconfigPath, inContainer)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(ExitNoConnect)
+		exitWithError(ExitNoConnect, "Error: %v", err)
 	}
 
-	if err := client.TestConnection(ctx); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(ExitNoConnect)
+	if err := testConnection(ctx, client); err != nil {
+		exitWithError(ExitNoConnect, "Error: %v", err)
 	}
 
-	clusterInfo, err := client.ClusterInfo(ctx)
-	if err != nil {
-		clusterInfo = "unknown"
-	}
+	clusterInfo := resolveClusterInfo(clusterNameOverride, func() (string, error) {
+		return client.ClusterInfo(ctx)
+	})
 
 	engine := probe.NewEngine(verbose)
 	engine.SetConfig(cfg)
+	engine.SetMaxResults(maxResults)
+	engine.SetResourceBudget(resourceBudget)
 	engine.SetDynamicClients(client.DynamicClient(), client.DiscoveryClient())
+	engine.SetMetadataClient(client.MetadataClient())
 
-	engine.Register(checks.NewNodeStatus())
-	engine.Register(checks.NewControlPlane())
-	engine.Register(checks.NewCriticalPods())
-	engine.Register(checks.NewCertificates())
-
-	engine.Register(checks.NewPodStatus())
-	engine.Register(checks.NewDeploymentStatus())
-	engine.Register(checks.NewPVCStatus())
-	engine.Register(checks.NewJobFailures())
-	engine.Register(checks.NewStalledResources())
-
-	engine.Register(checks.NewResourceRequests())
-	engine.Register(checks.NewNodeCapacity())
-	engine.Register(checks.NewStorageHealth())
-	engine.Register(checks.NewQuotaUsage())
-
-	engine.Register(checks.NewServiceEndpoints())
-	engine.Register(checks.NewIngressStatus())
-	engine.Register(checks.NewNetworkPolicies())
-	engine.Register(checks.NewDNSResolution())
-
-	engine.Register(checks.NewRBACAudit())
-	engine.Register(checks.NewPodSecurity())
-	engine.Register(checks.NewSecretsUsage())
-	engine.Register(checks.NewServiceAccounts())
+	if crdOnly {
+		engine.Register(checks.NewStalledCustomResources())
+	} else {
+		checks.RegisterDefaults(engine)
+	}
 
 	results, err := engine.Run(ctx, client.Clientset())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error running checks: %v\n", err)
-		os.Exit(ExitInternalErr)
+		exitWithError(ExitInternalErr, "Error running checks: %v", err)
 	}
 
 	currentScan := buildScanRecord(results, clusterInfo)
+	storage.TrackOccurrences(currentScan, loadHistory(store))
+	annotateOpenSince(results, currentScan)
+	if escalateAfter := cfg.GetThreshold("escalate_after_scans"); escalateAfter > 0 {
+		escalatePersistentIssues(results, currentScan, escalateAfter)
+	}
+	if strictMode {
+		escalateWarnings(results)
+	}
 
 	var diff *storage.ScanDiff
 	if previousScan != nil {
 		diff = storage.ComputeDiff(currentScan, previousScan)
 	}
 
-	if !noDiff {
-		if err := store.SaveScan(currentScan); err != nil && verbose {
-			fmt.Fprintf(os.Stderr, "Warning: failed to save scan: %v\n", err)
+	storeScan(store, currentScan)
+
+	if scanRecordPath != "" {
+		if err := writeScanRecord(scanRecordPath, currentScan); err != nil {
+			exitWithError(ExitInternalErr, "Error: %v", err)
 		}
 	}
 
-	format := report.FormatText
-	if outputFormat == "json" {
-		format = report.FormatJSON
+	if err := writeReports(os.Stdout, targets, results, clusterInfo, diff); err != nil {
+		exitWithError(ExitInternalErr, "Error writing report: %v", err)
 	}
+	writeSummaryLine(results)
 
-	writer := report.NewWriter(os.Stdout, format, verbose)
-	writer.SetDiff(diff)
-	if err := writer.Write(results, clusterInfo); err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
-		os.Exit(ExitInternalErr)
+	if fixScriptPath != "" {
+		if err := writeFixScript(fixScriptPath, results, clusterInfo); err != nil {
+			exitWithError(ExitInternalErr, "Error: %v", err)
+		}
+	}
+
+	if writeConfigMap != "" {
+		writer, err := newReportWriter(io.Discard, report.FormatJSON)
+		if err != nil {
+			exitWithError(ExitInternalErr, "Error: %v", err)
+		}
+		writer.SetDiff(diff)
+		if err := writer.WriteConfigMap(ctx, client.Clientset(), configMapNamespace, configMapName, results, clusterInfo); err != nil {
+			exitWithError(ExitInternalErr, "Error: %v", err)
+		}
+	}
+
+	if pushgatewayURL != "" {
+		if err := report.PushMetrics(pushgatewayURL, pushgatewayJob, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	os.Exit(severityExitCode(engine, results, cfg))
+
+	return nil
+}
+
+func severityExitCode(engine *probe.Engine, results []probe.CheckResult, cfg *config.Config) int {
+	counted := make([]probe.CheckResult, 0, len(results))
+	for _, r := range results {
+		if !cfg.IsExitCodeExcluded(r.Name) {
+			counted = append(counted, r)
+		}
 	}
 
-	switch engine.MaxSeverity(results) {
+	switch engine.MaxSeverity(counted) {
 	case probe.SeverityCritical:
-		os.Exit(ExitCritical)
+		return ExitCritical
 	case probe.SeverityWarning:
-		os.Exit(ExitWarning)
+		return ExitWarning
 	default:
-		os.Exit(ExitOK)
+		return ExitOK
 	}
+}
 
-	return nil
+func writeScanRecord(path string, record *storage.ScanRecord) error {
+	if redactClusterName {
+		redacted := *record
+		redacted.Cluster = report.RedactClusterName(record.Cluster)
+		record = &redacted
+	}
+	return storage.WriteScanRecord(path, record)
 }
 
 func buildScanRecord(results []probe.CheckResult, clusterInfo string) *storage.ScanRecord {
@@ -254,20 +559,30 @@ func buildScanRecord(results []probe.CheckResult, clusterInfo string) *storage.S
 			record.Summary.Critical++
 		case probe.SeverityWarning:
 			record.Summary.Warning++
-		case probe.SeverityOK:
+		case probe.SeverityOK, probe.SeverityInfo:
 			record.Summary.OK++
 		}
 		record.Summary.Total++
 
+		if cr.Errored() {
+			record.Issues = append(record.Issues, storage.StoredIssue{
+				CheckName:	cr.Name,
+				Severity:	probe.SeverityCritical.String(),
+				Message:	"Check failed to execute",
+				Fingerprint:	storage.GenerateFingerprint(cr.Name, "", "Check failed to execute"),
+			})
+		}
+
 		for _, r := range cr.Results {
-			if r.Severity == probe.SeverityOK {
+			if !r.Severity.IsIssue() {
 				continue
 			}
 			issue := storage.StoredIssue{
 				CheckName:	cr.Name,
 				Severity:	r.Severity.String(),
+				Namespace:	r.Namespace,
 				Message:	r.Message,
-				Fingerprint:	storage.GenerateFingerprint(cr.Name, r.Severity.String(), r.Message),
+				Fingerprint:	storage.GenerateFingerprint(cr.Name, r.Namespace, r.Message),
 			}
 			record.Issues = append(record.Issues, issue)
 		}
@@ -276,6 +591,286 @@ func buildScanRecord(results []probe.CheckResult, clusterInfo string) *storage.S
 	return record
 }
 
+func annotateOpenSince(results []probe.CheckResult, record *storage.ScanRecord) {
+	issues := make(map[string]storage.StoredIssue, len(record.Issues))
+	for _, issue := range record.Issues {
+		issues[issue.Fingerprint] = issue
+	}
+
+	for i := range results {
+		for j := range results[i].Results {
+			r := &results[i].Results[j]
+			if !r.Severity.IsIssue() {
+				continue
+			}
+			issue, ok := issues[storage.GenerateFingerprint(results[i].Name, r.Namespace, r.Message)]
+			if !ok || issue.Occurrences <= 1 || issue.FirstSeen.IsZero() {
+				continue
+			}
+			r.OpenSince = issue.FirstSeen
+		}
+	}
+}
+
+func escalatePersistentIssues(results []probe.CheckResult, record *storage.ScanRecord, after int) {
+	occurrences := make(map[string]int, len(record.Issues))
+	for _, issue := range record.Issues {
+		occurrences[issue.Fingerprint] = issue.Occurrences
+	}
+
+	for i := range results {
+		for j := range results[i].Results {
+			r := &results[i].Results[j]
+			if r.Severity != probe.SeverityWarning {
+				continue
+			}
+			count := occurrences[storage.GenerateFingerprint(results[i].Name, r.Namespace, r.Message)]
+			if count <= after {
+				continue
+			}
+			r.Severity = probe.SeverityCritical
+			r.Message = fmt.Sprintf("%s (persisting for %d scans)", r.Message, count)
+		}
+	}
+}
+
+func escalateWarnings(results []probe.CheckResult) {
+	for i := range results {
+		for j := range results[i].Results {
+			r := &results[i].Results[j]
+			if r.Severity != probe.SeverityWarning {
+				continue
+			}
+			r.Severity = probe.SeverityCritical
+			r.Details = append(r.Details, "Reported as critical by --strict (originally a warning)")
+		}
+	}
+}
+
+func newClient(kubeconfigPath string, inContainer bool) (*k8s.Client, error) {
+	opts := k8s.ClientOptions{InsecureSkipTLSVerify: insecureTLS}
+	if caCert != "" {
+		opts.CAFile = k8s.ResolveHostPath(caCert, inContainer)
+	}
+
+	if insecureTLS {
+		fmt.Fprintln(os.Stderr, "WARNING: TLS certificate verification is disabled. The API server's identity is NOT being verified;")
+		fmt.Fprintln(os.Stderr, "WARNING: credentials and cluster data may be exposed to a man-in-the-middle. Use --ca-cert instead when possible.")
+	}
+
+	return k8s.NewClientWithOptions(kubeconfigPath, opts)
+}
+
+func resolveClusterInfo(override string, lookup func() (string, error)) string {
+	if override != "" {
+		return override
+	}
+	clusterInfo, err := lookup()
+	if err != nil {
+		return "unknown"
+	}
+	return clusterInfo
+}
+
+func testConnection(ctx context.Context, client *k8s.Client) error {
+	if waitForReady <= 0 {
+		return client.TestConnection(ctx)
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Waiting up to %s for the API server to respond\n", waitForReady)
+	}
+	return client.WaitForReady(ctx, waitForReady)
+}
+
+func loadLastScan(store *storage.Storage) *storage.ScanRecord {
+	if noDiff || noStore || crdOnly {
+		return nil
+	}
+	lastScan, err := store.LoadLastScan()
+	if err != nil && verbose {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load previous scan: %v\n", err)
+	}
+	return lastScan
+}
+
+func loadHistory(store *storage.Storage) *storage.ScanRecord {
+	if noDiff || noStore || crdOnly {
+		return nil
+	}
+	history, err := store.LoadHistory()
+	if err != nil && verbose {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load scan history: %v\n", err)
+	}
+	return history
+}
+
+func storeScan(store *storage.Storage, record *storage.ScanRecord) {
+	if noDiff || noStore || crdOnly {
+		return
+	}
+	if err := saveScan(store, record); err != nil && verbose {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save scan: %v\n", err)
+	}
+}
+
+func saveScan(store *storage.Storage, record *storage.ScanRecord) error {
+	if !onlyChanged {
+		return store.SaveScan(record)
+	}
+
+	saved, err := store.SaveIfChanged(record)
+	if err == nil && !saved && verbose {
+		fmt.Fprintln(os.Stderr, "Scan unchanged since last save, keeping previous baseline")
+	}
+	return err
+}
+
+type outputTarget struct {
+	format	report.Format
+	path	string
+}
+
+func parseOutputs(spec string) ([]outputTarget, error) {
+	var targets []outputTarget
+	toStdout := 0
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, path, _ := strings.Cut(entry, ":")
+		format := report.Format(name)
+		if format != report.FormatText && format != report.FormatJSON {
+			return nil, fmt.Errorf("unsupported output format %q (supported: text, json)", name)
+		}
+		if path == "" {
+			toStdout++
+		}
+		targets = append(targets, outputTarget{format: format, path: path})
+	}
+
+	if len(targets) == 0 {
+		return []outputTarget{{format: report.FormatText}}, nil
+	}
+	if toStdout > 1 {
+		return nil, fmt.Errorf("only one output format can be written to stdout; give the others a :path")
+	}
+	return targets, nil
+}
+
+func stdoutFormat() report.Format {
+	targets, err := parseOutputs(outputFormat)
+	if err != nil {
+		return report.FormatText
+	}
+	for _, target := range targets {
+		if target.path == "" {
+			return target.format
+		}
+	}
+	return ""
+}
+
+func writeReports(stdout io.Writer, targets []outputTarget, results []probe.CheckResult, clusterInfo string, diff *storage.ScanDiff) error {
+	for _, target := range targets {
+		if target.path == "" {
+			if err := writeReport(stdout, target.format, results, clusterInfo, diff); err != nil {
+				return err
+			}
+			continue
+		}
+
+		file, err := os.Create(target.path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", target.path, err)
+		}
+		if err := writeReport(file, target.format, results, clusterInfo, diff); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target.path, err)
+		}
+	}
+	return nil
+}
+
+func writeFixScript(path string, results []probe.CheckResult, clusterInfo string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	writer, err := newReportWriter(file, report.FormatText)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.WriteFixScript(results, clusterInfo); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func writeReport(w io.Writer, format report.Format, results []probe.CheckResult, clusterInfo string, diff *storage.ScanDiff) error {
+	writer, err := newReportWriter(w, format)
+	if err != nil {
+		return err
+	}
+	writer.SetDiff(diff)
+	return writer.Write(results, clusterInfo)
+}
+
+func newReportWriter(w io.Writer, format report.Format) (*report.Writer, error) {
+	writer := report.NewWriter(w, format, verbose)
+	if err := writer.SetFormatVersion(formatVersion); err != nil {
+		return nil, err
+	}
+	if err := writer.SetGroupBy(groupBy); err != nil {
+		return nil, err
+	}
+	if err := writer.SetSortBy(sortBy); err != nil {
+		return nil, err
+	}
+	writer.SetIncludeOK(includeOK)
+	writer.SetRedactCluster(redactClusterName)
+	if err := writer.SetStyle(reportStyle); err != nil {
+		return nil, err
+	}
+	if templatePath != "" {
+		tmpl, err := report.LoadTemplate(templatePath)
+		if err != nil {
+			return nil, err
+		}
+		writer.SetTemplate(tmpl)
+	}
+	return writer, nil
+}
+
+func exitWithError(code int, format string, args ...interface{}) {
+	writeFatalError(os.Stdout, os.Stderr, code, fmt.Sprintf(format, args...))
+	os.Exit(code)
+}
+
+func writeFatalError(stdout, stderr io.Writer, code int, message string) {
+	fmt.Fprintln(stderr, message)
+	if stdoutFormat() == report.FormatJSON {
+		report.WriteError(stdout, strings.TrimPrefix(message, "Error: "), code)
+	}
+}
+
+func writeSummaryLine(results []probe.CheckResult) {
+	if !emitSummary {
+		return
+	}
+	if err := report.WriteSummaryLine(os.Stderr, results); err != nil {
+		exitWithError(ExitInternalErr, "Error writing summary: %v", err)
+	}
+}
+
 func runSetup(ctx context.Context, inContainer bool, outputPath string) error {
 	fmt.Println("═══════════════════════════════════════════════════════════════")
 	fmt.Println("                    CLUSTER PROBE SETUP")
@@ -286,42 +881,30 @@ func runSetup(ctx context.Context, inContainer bool, outputPath string) error {
 
 	kubeconfigPath := k8s.DiscoverKubeconfig(kubeconfig, inContainer)
 	if kubeconfigPath == "" {
-		fmt.Fprintln(os.Stderr, "Error: could not find kubeconfig for setup")
-		os.Exit(ExitNoConnect)
+		exitWithError(ExitNoConnect, "Error: could not find kubeconfig for setup")
 	}
 
 	if verbose {
 		fmt.Fprintf(os.Stderr, "Using host kubeconfig for setup: %s\n", kubeconfigPath)
 	}
 
-	client, err := k8s.NewClient(kubeconfigPath)
+	client, err := newClient(kubeconfigPath, inContainer)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(ExitNoConnect)
+		exitWithError(ExitNoConnect, "Error: %v", err)
 	}
 
-	if err := client.TestConnection(ctx); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(ExitNoConnect)
+	if err := testConnection(ctx, client); err != nil {
+		exitWithError(ExitNoConnect, "Error: %v", err)
 	}
 
-	s := setup.NewSetup(client.Clientset(), kubeconfigPath, verbose)
+	s := setup.NewSetup(client.Clientset(), client.RESTConfig(), kubeconfigPath, setupNamespace, verbose)
 
-	var setupErr error
-	for i := 0; i < 5; i++ {
-		setupErr = s.Run(ctx, outputPath)
-		if setupErr == nil {
-			break
-		}
-		if verbose {
-			fmt.Fprintf(os.Stderr, "[setup] Attempt %d failed: %v, retrying...\n", i+1, setupErr)
-		}
-		time.Sleep(time.Second)
+	if err := s.Preflight(ctx); err != nil {
+		exitWithError(ExitInternalErr, "Error during setup preflight: %v", err)
 	}
 
-	if setupErr != nil {
-		fmt.Fprintf(os.Stderr, "Error during setup: %v\n", setupErr)
-		os.Exit(ExitInternalErr)
+	if err := s.Run(ctx, outputPath); err != nil {
+		exitWithError(ExitInternalErr, "Error during setup: %v", err)
 	}
 
 	fmt.Println()
@@ -342,59 +925,67 @@ func runNetworkTest(ctx context.Context, inContainer bool) error {
 
 	kubeconfigPath := k8s.DiscoverKubeconfig(kubeconfig, inContainer)
 	if kubeconfigPath == "" {
-		fmt.Fprintln(os.Stderr, "Error: could not find kubeconfig for network test")
-		os.Exit(ExitNoConnect)
+		exitWithError(ExitNoConnect, "Error: could not find kubeconfig for network test")
 	}
 
 	if verbose {
 		fmt.Fprintf(os.Stderr, "[network-test] Using kubeconfig: %s\n", kubeconfigPath)
 	}
 
-	client, err := k8s.NewClient(kubeconfigPath)
+	client, err := newClient(kubeconfigPath, inContainer)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(ExitNoConnect)
+		exitWithError(ExitNoConnect, "Error: %v", err)
 	}
 
-	if err := client.TestConnection(ctx); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(ExitNoConnect)
+	if err := testConnection(ctx, client); err != nil {
+		exitWithError(ExitNoConnect, "Error: %v", err)
 	}
 
-	clusterInfo, err := client.ClusterInfo(ctx)
+	clusterInfo := resolveClusterInfo(clusterNameOverride, func() (string, error) {
+		return client.ClusterInfo(ctx)
+	})
+
+	cfg, err := config.LoadConfig(storage.NewStorage("").ConfigPath())
 	if err != nil {
-		clusterInfo = "unknown"
+		fmt.Fprintf(os.Stderr, "Warning: failed to load config: %v\n", err)
+		cfg = config.DefaultConfig()
 	}
+	redactClusterName = redactClusterName || cfg.RedactClusterName
 
-	nt := nettest.New(client.Clientset(), client.RESTConfig(), verbose)
+	nt := nettest.New(client.Clientset(), client.RESTConfig(), verbose, networkTestTimeouts(cfg))
+	nt.SetMeasureLatency(networkLatency)
 
 	testReport, err := nt.Run(ctx)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Network test failed: %v\n", err)
-		os.Exit(ExitInternalErr)
+		exitWithError(ExitInternalErr, "Network test failed: %v", err)
 	}
 
-	results := convertNetworkReport(testReport)
-
-	format := report.FormatText
-	if outputFormat == "json" {
-		format = report.FormatJSON
+	results := convertNetworkReport(testReport, cfg)
+	if strictMode {
+		escalateWarnings(results)
 	}
 
-	writer := report.NewWriter(os.Stdout, format, verbose)
-	if err := writer.Write(results, clusterInfo); err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
-		os.Exit(ExitInternalErr)
+	targets, err := parseOutputs(outputFormat)
+	if err != nil {
+		exitWithError(ExitInternalErr, "Error: %v", err)
 	}
-
-	if testReport.Summary.Failed > 0 {
-		os.Exit(ExitCritical)
+	if err := writeReports(os.Stdout, targets, results, clusterInfo, nil); err != nil {
+		exitWithError(ExitInternalErr, "Error writing report: %v", err)
 	}
-	os.Exit(ExitOK)
+	writeSummaryLine(results)
+
+	os.Exit(severityExitCode(probe.NewEngine(false), results, cfg))
 	return nil
 }
 
-func convertNetworkReport(r *nettest.NetworkTestReport) []probe.CheckResult {
+func networkTestTimeouts(cfg *config.Config) nettest.Timeouts {
+	return nettest.Timeouts{
+		Test:    time.Duration(cfg.NetworkTest.TestTimeoutSeconds) * time.Second,
+		Overall: time.Duration(cfg.NetworkTest.DeadlineSeconds) * time.Second,
+	}
+}
+
+func convertNetworkReport(r *nettest.NetworkTestReport, cfg *config.Config) []probe.CheckResult {
 	typeNames := map[string]string{
 		"coredns":      "CoreDNS Connectivity",
 		"dns":          "DNS Resolution",
@@ -425,22 +1016,33 @@ func convertNetworkReport(r *nettest.NetworkTestReport) []probe.CheckResult {
 
 		passed := 0
 		failed := 0
+		skipped := 0
+		var latencies []string
+		var totalLatency float64
 		for _, tr := range typeResults {
-			if tr.Success {
+			if tr.Skipped {
+				skipped++
+			} else if tr.Success {
 				passed++
+				if tr.LatencyMs > 0 {
+					latencies = append(latencies, fmt.Sprintf("%s -> %s: %.2f ms", tr.SourceNode, tr.Target, tr.LatencyMs))
+					totalLatency += tr.LatencyMs
+				}
 			} else {
 				failed++
+				remediation, commands := getNetworkRemediation(testType)
 				checkResult.Results = append(checkResult.Results, probe.Result{
-					CheckName:   checkResult.Name,
-					Severity:    probe.SeverityCritical,
-					Message:     fmt.Sprintf("%s: %s -> %s failed", typeNames[testType], tr.SourceNode, tr.Target),
-					Details:     []string{tr.Error},
-					Remediation: getNetworkRemediation(testType),
+					CheckName:           checkResult.Name,
+					Severity:            networkFailureSeverity(cfg, testType),
+					Message:             fmt.Sprintf("%s: %s -> %s failed", typeNames[testType], tr.SourceNode, tr.Target),
+					Details:             []string{tr.Error},
+					Remediation:         remediation,
+					RemediationCommands: commands,
 				})
 			}
 		}
 
-		if failed == 0 && passed > 0 {
+		if failed == 0 && skipped == 0 && passed > 0 {
 			checkResult.Results = append(checkResult.Results, probe.Result{
 				CheckName: checkResult.Name,
 				Severity:  probe.SeverityOK,
@@ -448,25 +1050,54 @@ func convertNetworkReport(r *nettest.NetworkTestReport) []probe.CheckResult {
 			})
 		}
 
+		if skipped > 0 {
+			checkResult.Results = append(checkResult.Results, probe.Result{
+				CheckName: checkResult.Name,
+				Severity:  probe.SeverityWarning,
+				Message:   fmt.Sprintf("%s: %d tests skipped after the network test deadline", typeNames[testType], skipped),
+				Details: []string{
+					fmt.Sprintf("Completed: %d passed, %d failed of %d tests", passed, failed, len(typeResults)),
+				},
+				Remediation: "Raise network_test.deadline_seconds in the config, or lower network_test.test_timeout_seconds, to run every test",
+			})
+		}
+
+		if len(latencies) > 0 {
+			sort.Strings(latencies)
+			checkResult.Results = append(checkResult.Results, probe.Result{
+				CheckName: checkResult.Name,
+				Severity:  probe.SeverityOK,
+				Message:   fmt.Sprintf("%s: average round-trip %.2f ms across %d pairs", typeNames[testType], totalLatency/float64(len(latencies)), len(latencies)),
+				Details:   latencies,
+			})
+		}
+
 		results = append(results, checkResult)
 	}
 
 	return results
 }
 
-func getNetworkRemediation(testType string) string {
+func networkFailureSeverity(cfg *config.Config, testType string) probe.Severity {
+	if cfg.NetworkTestSeverity(testType) == "warning" {
+		return probe.SeverityWarning
+	}
+	return probe.SeverityCritical
+}
+
+func getNetworkRemediation(testType string) (string, []string) {
 	switch testType {
 	case "coredns":
-		return "Check CoreDNS pod status and network policies: kubectl get pods -n kube-system -l k8s-app=kube-dns"
+		return "Check CoreDNS pod status and network policies", []string{"kubectl get pods -n kube-system -l k8s-app=kube-dns"}
 	case "dns":
-		return "Verify DNS resolution works and external DNS is reachable"
+		return "Verify DNS resolution works and external DNS is reachable", nil
 	case "external-tcp":
-		return "Check firewall rules and network egress policies for external connectivity"
+		return "Check firewall rules and network egress policies for external connectivity", nil
 	case "kubelet":
-		return "Verify node-to-node connectivity and firewall rules allow port 10250"
+		return "Verify node-to-node connectivity and firewall rules allow port 10250", nil
 	case "pod-to-pod":
-		return "Check CNI plugin status and network policies between namespaces"
+		return "Check CNI plugin status and network policies between namespaces", nil
 	default:
-		return "Check network configuration and policies"
+		return "Check network configuration and policies", nil
 	}
 }
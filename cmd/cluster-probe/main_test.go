@@ -0,0 +1,575 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/punasusi/cluster-probe/pkg/nettest"
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	"github.com/punasusi/cluster-probe/pkg/probe/report"
+	"github.com/punasusi/cluster-probe/pkg/probe/storage"
+)
+
+func TestVersionCommand(t *testing.T) {
+	cmd := newRootCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"version"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("version command failed: %v", err)
+	}
+
+	output := out.String()
+	for _, want := range []string{"cluster-probe dev", "Commit:", "Built:", "Go version:", "client-go:", "Kubernetes:"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestVersionFlag(t *testing.T) {
+	cmd := newRootCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--version"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("--version failed: %v", err)
+	}
+
+	if !strings.HasPrefix(out.String(), "cluster-probe dev") {
+		t.Errorf("unexpected --version output: %s", out.String())
+	}
+}
+
+func TestKubernetesVersion(t *testing.T) {
+	if got := kubernetesVersion("v0.29.0"); got != "v1.29.0" {
+		t.Errorf("expected v1.29.0, got %s", got)
+	}
+	if got := kubernetesVersion("(devel)"); got != "unknown" {
+		t.Errorf("expected unknown, got %s", got)
+	}
+}
+
+func TestEscalatePersistentIssues(t *testing.T) {
+	newResults := func() []probe.CheckResult {
+		return []probe.CheckResult{
+			{
+				Name: "pod-status",
+				Tier: 2,
+				Results: []probe.Result{
+					{Severity: probe.SeverityWarning, Message: "Pod app/web is restarting"},
+					{Severity: probe.SeverityOK, Message: "10 pods running"},
+				},
+			},
+		}
+	}
+
+	var last *storage.ScanRecord
+	var results []probe.CheckResult
+	for scan := 1; scan <= 4; scan++ {
+		results = newResults()
+		record := buildScanRecord(results, "test-cluster")
+		storage.TrackOccurrences(record, last)
+		escalatePersistentIssues(results, record, 3)
+
+		warning := results[0].Results[0]
+		if scan <= 3 {
+			if warning.Severity != probe.SeverityWarning {
+				t.Fatalf("scan %d: issue escalated too early", scan)
+			}
+		}
+		last = record
+	}
+
+	escalated := results[0].Results[0]
+	if escalated.Severity != probe.SeverityCritical {
+		t.Errorf("expected escalation to critical after 4 scans, got %s", escalated.Severity)
+	}
+	if escalated.Message != "Pod app/web is restarting (persisting for 4 scans)" {
+		t.Errorf("unexpected message: %q", escalated.Message)
+	}
+	if results[0].Results[1].Severity != probe.SeverityOK {
+		t.Error("OK results must not be escalated")
+	}
+	if last.Issues[0].Severity != "WARNING" {
+		t.Errorf("stored issue should keep its original severity, got %s", last.Issues[0].Severity)
+	}
+}
+
+func TestEscalatePersistentIssuesWithOnlyChanged(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	onlyChanged = true
+	defer func() { onlyChanged = false }()
+
+	var results []probe.CheckResult
+	for scan := 1; scan <= 4; scan++ {
+		results = []probe.CheckResult{
+			{
+				Name: "stalled-resources",
+				Results: []probe.Result{
+					{Severity: probe.SeverityWarning, Namespace: "app", Message: fmt.Sprintf("Pod app/web pending for %dm", scan*10)},
+				},
+			},
+		}
+		record := buildScanRecord(results, "test-cluster")
+		storage.TrackOccurrences(record, loadHistory(store))
+		escalatePersistentIssues(results, record, 3)
+		storeScan(store, record)
+	}
+
+	escalated := results[0].Results[0]
+	if escalated.Severity != probe.SeverityCritical {
+		t.Errorf("expected escalation to critical after 4 scans, got %s", escalated.Severity)
+	}
+	if escalated.Message != "Pod app/web pending for 40m (persisting for 4 scans)" {
+		t.Errorf("unexpected message: %q", escalated.Message)
+	}
+}
+
+func TestClusterNameOverrideFlowsThrough(t *testing.T) {
+	clusterInfo := resolveClusterInfo("ci-ephemeral", func() (string, error) {
+		t.Fatal("the kubeconfig context should not be consulted when --cluster-name is set")
+		return "", nil
+	})
+	if clusterInfo != "ci-ephemeral" {
+		t.Fatalf("expected the override, got %q", clusterInfo)
+	}
+
+	results := []probe.CheckResult{
+		{Name: "pod-status", Tier: 2, Results: []probe.Result{{Severity: probe.SeverityWarning, Message: "Pod app/web is restarting"}}},
+	}
+	if record := buildScanRecord(results, clusterInfo); record.Cluster != "ci-ephemeral" {
+		t.Errorf("scan record should be keyed by the override, got %q", record.Cluster)
+	}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, report.FormatJSON, results, clusterInfo, nil); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	var out report.Report
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if out.Cluster != "ci-ephemeral" {
+		t.Errorf("report should name the override, got %q", out.Cluster)
+	}
+
+	fallback := resolveClusterInfo("", func() (string, error) { return "", os.ErrNotExist })
+	if fallback != "unknown" {
+		t.Errorf("expected unknown when the lookup fails, got %q", fallback)
+	}
+}
+
+func TestEmitScanRecordWritesFingerprints(t *testing.T) {
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{Severity: probe.SeverityWarning, Message: "Pod app/web is restarting", Namespace: "app"},
+				{Severity: probe.SeverityCritical, Message: "Pod app/db is crash looping", Namespace: "app"},
+				{Severity: probe.SeverityOK, Message: "10 pods running"},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "record.json")
+	if err := storage.WriteScanRecord(path, buildScanRecord(results, "test-cluster")); err != nil {
+		t.Fatalf("WriteScanRecord failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record storage.ScanRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("record is not valid JSON: %v", err)
+	}
+	if record.Cluster != "test-cluster" || len(record.Issues) != 2 {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+	if record.Issues[0].Fingerprint == "" || record.Issues[0].Fingerprint == record.Issues[1].Fingerprint {
+		t.Errorf("expected distinct fingerprints, got %q and %q", record.Issues[0].Fingerprint, record.Issues[1].Fingerprint)
+	}
+}
+
+func TestBuildScanRecordCountsInfoOnlyChecksAsOK(t *testing.T) {
+	results := []probe.CheckResult{
+		{Name: "storage-health", Tier: 3, Results: []probe.Result{{Severity: probe.SeverityInfo, Message: "2 CSI drivers installed"}}},
+		{Name: "pod-status", Tier: 2, Results: []probe.Result{{Severity: probe.SeverityOK, Message: "10 pods running"}}},
+	}
+
+	record := buildScanRecord(results, "test-cluster")
+	summary := record.Summary
+	if summary.Total != 2 || summary.OK != 2 || summary.Warning != 0 || summary.Critical != 0 {
+		t.Errorf("an info-only check should be counted as OK, got %+v", summary)
+	}
+	if len(record.Issues) != 0 {
+		t.Errorf("info results are not issues, got %+v", record.Issues)
+	}
+}
+
+func TestScanRecordFromReportMatchesBuildScanRecord(t *testing.T) {
+	results := []probe.CheckResult{
+		{Name: "pod-status", Tier: 2, Results: []probe.Result{{Severity: probe.SeverityWarning, Message: "Pod app/web is restarting"}}},
+		{Name: "node-status", Tier: 1, Results: []probe.Result{}, Error: "failed to list nodes: forbidden"},
+		{Name: "storage-health", Tier: 3, Results: []probe.Result{{Severity: probe.SeverityInfo, Message: "2 CSI drivers installed"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := report.NewWriter(&buf, report.FormatJSON, false).Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var rep report.Report
+	if err := json.Unmarshal(buf.Bytes(), &rep); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+
+	fromReport := report.ScanRecordFromReport(&rep)
+	built := buildScanRecord(results, "test-cluster")
+	if fromReport.Summary != built.Summary {
+		t.Errorf("summaries differ: report %+v, scan record %+v", fromReport.Summary, built.Summary)
+	}
+	fingerprints := func(record *storage.ScanRecord) map[string]bool {
+		set := make(map[string]bool)
+		for _, issue := range record.Issues {
+			set[issue.Fingerprint] = true
+		}
+		return set
+	}
+	want := fingerprints(built)
+	got := fingerprints(fromReport)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d issues, got %+v", len(want), fromReport.Issues)
+	}
+	for fingerprint := range want {
+		if !got[fingerprint] {
+			t.Errorf("issue %s from the scan record is missing from the report's record: %+v", fingerprint, fromReport.Issues)
+		}
+	}
+}
+
+func TestStrictModeWarningOnlyExitsCritical(t *testing.T) {
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{Severity: probe.SeverityWarning, Message: "Pod app/web is restarting"},
+				{Severity: probe.SeverityOK, Message: "10 pods running"},
+			},
+		},
+	}
+	engine := probe.NewEngine(false)
+	cfg := config.DefaultConfig()
+
+	if code := severityExitCode(engine, results, cfg); code != ExitWarning {
+		t.Fatalf("expected warning exit code before strict mode, got %d", code)
+	}
+
+	escalateWarnings(results)
+
+	if code := severityExitCode(engine, results, cfg); code != ExitCritical {
+		t.Errorf("expected critical exit code under strict mode, got %d", code)
+	}
+	if results[0].Results[0].Severity != probe.SeverityCritical {
+		t.Errorf("warning should be reported as critical, got %s", results[0].Results[0].Severity)
+	}
+	if results[0].Results[1].Severity != probe.SeverityOK {
+		t.Error("OK results must not be escalated")
+	}
+}
+
+func TestWriteFatalErrorJSON(t *testing.T) {
+	previous := outputFormat
+	defer func() { outputFormat = previous }()
+	outputFormat = "json"
+
+	var stdout, stderr bytes.Buffer
+	writeFatalError(&stdout, &stderr, ExitNoConnect, "Error: failed to connect to cluster: connection refused")
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("expected JSON on stdout, got %q: %v", stdout.String(), err)
+	}
+	if len(got) != 2 {
+		t.Errorf("expected only error and code fields, got %v", got)
+	}
+	if got["error"] != "failed to connect to cluster: connection refused" {
+		t.Errorf("unexpected error field: %v", got["error"])
+	}
+	if got["code"] != float64(ExitNoConnect) {
+		t.Errorf("expected code %d, got %v", ExitNoConnect, got["code"])
+	}
+	if !strings.Contains(stderr.String(), "connection refused") {
+		t.Errorf("expected human-readable error on stderr, got %q", stderr.String())
+	}
+}
+
+func TestWriteFatalErrorText(t *testing.T) {
+	previous := outputFormat
+	defer func() { outputFormat = previous }()
+	outputFormat = "text"
+
+	var stdout, stderr bytes.Buffer
+	writeFatalError(&stdout, &stderr, ExitNoConnect, "Error: connection refused")
+
+	if stdout.Len() != 0 {
+		t.Errorf("expected no stdout in text mode, got %q", stdout.String())
+	}
+	if stderr.String() != "Error: connection refused\n" {
+		t.Errorf("unexpected stderr: %q", stderr.String())
+	}
+}
+
+func TestSeverityExitCodeExcludedChecks(t *testing.T) {
+	results := []probe.CheckResult{
+		{
+			Name:    "network-policies",
+			Tier:    3,
+			Results: []probe.Result{{Severity: probe.SeverityCritical, Message: "No network policies defined"}},
+		},
+		{
+			Name:    "pod-status",
+			Tier:    2,
+			Results: []probe.Result{{Severity: probe.SeverityWarning, Message: "Pod app/web is restarting"}},
+		},
+	}
+	engine := probe.NewEngine(false)
+	cfg := config.DefaultConfig()
+
+	if code := severityExitCode(engine, results, cfg); code != ExitCritical {
+		t.Errorf("expected exit code %d without exclusions, got %d", ExitCritical, code)
+	}
+
+	cfg.ExitCodeExcludeChecks = []string{"network-policies"}
+	if code := severityExitCode(engine, results, cfg); code != ExitWarning {
+		t.Errorf("expected exit code %d with network-policies excluded, got %d", ExitWarning, code)
+	}
+
+	cfg.ExitCodeExcludeChecks = []string{"network-policies", "pod-status"}
+	if code := severityExitCode(engine, results, cfg); code != ExitOK {
+		t.Errorf("expected exit code %d with all checks excluded, got %d", ExitOK, code)
+	}
+}
+
+func TestConvertNetworkReportConfiguredSeverity(t *testing.T) {
+	testReport := &nettest.NetworkTestReport{
+		TestResults: []nettest.TestResult{
+			{TestType: "external-tcp", SourceNode: "node-a", Target: "github.com:443", Error: "connection timed out"},
+			{TestType: "dns", SourceNode: "node-a", Target: "github.com", Error: "no such host"},
+		},
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.NetworkTest.Severity = map[string]string{"external-tcp": "warning"}
+
+	severities := make(map[string]probe.Severity)
+	for _, cr := range convertNetworkReport(testReport, cfg) {
+		severities[cr.Name] = cr.MaxSeverity()
+	}
+	if severities["network-test-external-tcp"] != probe.SeverityWarning {
+		t.Errorf("expected external-tcp failures to be warnings, got %s", severities["network-test-external-tcp"])
+	}
+	if severities["network-test-dns"] != probe.SeverityCritical {
+		t.Errorf("expected dns failures to stay critical, got %s", severities["network-test-dns"])
+	}
+}
+
+func TestCompareCommand(t *testing.T) {
+	cmd := newRootCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"compare", "testdata/old-scan.json", "testdata/new-report.json", "-o", "json"})
+	defer func() { outputFormat = "text" }()
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("compare command failed: %v", err)
+	}
+
+	var got report.Comparison
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode comparison: %v\n%s", err, out.String())
+	}
+
+	if got.Cluster != "prod-cluster" {
+		t.Errorf("unexpected cluster: %q", got.Cluster)
+	}
+	if len(got.Diff.NewIssues) != 1 || got.Diff.NewIssues[0].Check != "resource-requests" {
+		t.Errorf("expected one new resource-requests issue, got %+v", got.Diff.NewIssues)
+	}
+	if len(got.Diff.ResolvedIssues) != 1 || got.Diff.ResolvedIssues[0].Message != "Node worker-2 is NotReady" {
+		t.Errorf("expected the NotReady node to be resolved, got %+v", got.Diff.ResolvedIssues)
+	}
+	if got.Diff.CriticalDelta != -1 || got.Diff.WarningDelta != 1 {
+		t.Errorf("unexpected deltas: critical %d, warning %d", got.Diff.CriticalDelta, got.Diff.WarningDelta)
+	}
+}
+
+func TestCompareCommandText(t *testing.T) {
+	cmd := newRootCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"compare", "testdata/old-scan.json", "testdata/new-report.json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("compare command failed: %v", err)
+	}
+
+	output := out.String()
+	for _, want := range []string{
+		"Previous: 2024-05-01 10:00:00 UTC",
+		"[resource-requests] Deployment app/api has containers without resource requests",
+		"✓ [node-status] Node worker-2 is NotReady",
+		"(-1 critical, +1 warning since last scan)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Pod app/web is restarting") {
+		t.Errorf("unchanged issue should not be listed:\n%s", output)
+	}
+}
+
+func TestCompareCommandMissingFile(t *testing.T) {
+	cmd := newRootCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"compare", "testdata/old-scan.json", "testdata/missing.json"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for a missing scan file")
+	}
+}
+
+func TestNoStoreSkipsScanHistory(t *testing.T) {
+	dir := t.TempDir()
+	store := storage.NewStorage(dir)
+	noStore = true
+	defer func() { noStore = false }()
+
+	if lastScan := loadLastScan(store); lastScan != nil {
+		t.Errorf("expected no previous scan with --no-store, got %+v", lastScan)
+	}
+	storeScan(store, &storage.ScanRecord{Cluster: "test-cluster"})
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no files to be written, found %d entries", len(entries))
+	}
+
+	noStore = false
+	storeScan(store, &storage.ScanRecord{Cluster: "test-cluster"})
+	if lastScan := loadLastScan(store); lastScan == nil || lastScan.Cluster != "test-cluster" {
+		t.Errorf("expected the scan to round-trip without --no-store, got %+v", lastScan)
+	}
+}
+
+func TestCRDOnlySkipsScanHistory(t *testing.T) {
+	dir := t.TempDir()
+	store := storage.NewStorage(dir)
+	storeScan(store, &storage.ScanRecord{Cluster: "full-scan"})
+
+	crdOnly = true
+	defer func() { crdOnly = false }()
+
+	if lastScan := loadLastScan(store); lastScan != nil {
+		t.Errorf("a --crd-only scan should not be diffed against history, got %+v", lastScan)
+	}
+	storeScan(store, &storage.ScanRecord{Cluster: "crd-only-scan"})
+
+	crdOnly = false
+	if lastScan := loadLastScan(store); lastScan == nil || lastScan.Cluster != "full-scan" {
+		t.Errorf("a --crd-only scan should not replace the last full scan, got %+v", lastScan)
+	}
+}
+
+func TestWriteScanRecordRedactsClusterName(t *testing.T) {
+	redactClusterName = true
+	defer func() { redactClusterName = false }()
+
+	record := &storage.ScanRecord{Cluster: "prod-eu-1"}
+	path := filepath.Join(t.TempDir(), "scan.json")
+	if err := writeScanRecord(path, record); err != nil {
+		t.Fatalf("writeScanRecord failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written storage.ScanRecord
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatal(err)
+	}
+	if written.Cluster != report.RedactClusterName("prod-eu-1") {
+		t.Errorf("expected a redacted cluster name, got %q", written.Cluster)
+	}
+	if record.Cluster != "prod-eu-1" {
+		t.Errorf("the in-memory record should keep the real cluster name, got %q", record.Cluster)
+	}
+}
+
+func TestParseOutputs(t *testing.T) {
+	targets, err := parseOutputs("text,json:out/report.json")
+	if err != nil {
+		t.Fatalf("parseOutputs failed: %v", err)
+	}
+	if len(targets) != 2 || targets[0] != (outputTarget{format: report.FormatText}) || targets[1] != (outputTarget{format: report.FormatJSON, path: "out/report.json"}) {
+		t.Errorf("unexpected targets: %+v", targets)
+	}
+
+	for _, spec := range []string{"yaml", "text,json", "json:a.json,xml:b.xml"} {
+		if _, err := parseOutputs(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
+
+func TestWriteReportsMultipleFormats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	targets, err := parseOutputs("text,json:" + path)
+	if err != nil {
+		t.Fatalf("parseOutputs failed: %v", err)
+	}
+
+	results := []probe.CheckResult{{
+		Name:    "pod-status",
+		Tier:    2,
+		Results: []probe.Result{{CheckName: "pod-status", Severity: probe.SeverityWarning, Message: "Pod app/web is restarting"}},
+	}}
+
+	var stdout bytes.Buffer
+	if err := writeReports(&stdout, targets, results, "test-cluster", nil); err != nil {
+		t.Fatalf("writeReports failed: %v", err)
+	}
+
+	if !strings.Contains(stdout.String(), "CLUSTER PROBE REPORT") || !strings.Contains(stdout.String(), "Summary: ⚠ 1 warning") {
+		t.Errorf("expected the text report on stdout, got:\n%s", stdout.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read JSON report: %v", err)
+	}
+	var got report.Report
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("expected a JSON report in %s: %v", path, err)
+	}
+	if got.Cluster != "test-cluster" || got.Summary.Warning != 1 {
+		t.Errorf("unexpected JSON report: %+v", got)
+	}
+}
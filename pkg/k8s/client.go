@@ -3,24 +3,43 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+const (
+	readyInitialBackoff = 500 * time.Millisecond
+	readyMaxBackoff     = 10 * time.Second
+)
+
 type Client struct {
 	clientset       *kubernetes.Clientset
 	dynamicClient   dynamic.Interface
 	discoveryClient *discovery.DiscoveryClient
+	metadataClient  metadata.Interface
 	config          clientcmd.ClientConfig
 	restConfig      *rest.Config
 }
 
+type ClientOptions struct {
+	CAFile                string
+	InsecureSkipTLSVerify bool
+}
+
 func NewClient(kubeconfigPath string) (*Client, error) {
-	loadingRules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath}
+	return NewClientWithOptions(kubeconfigPath, ClientOptions{})
+}
+
+func NewClientWithOptions(kubeconfigPath string, opts ClientOptions) (*Client, error) {
+	loadingRules := LoadingRules(kubeconfigPath)
 	configOverrides := &clientcmd.ConfigOverrides{}
 	config := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
 
@@ -29,6 +48,10 @@ func NewClient(kubeconfigPath string) (*Client, error) {
 		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
+	if err := applyTLSOptions(restConfig, opts); err != nil {
+		return nil, err
+	}
+
 	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
@@ -44,15 +67,44 @@ func NewClient(kubeconfigPath string) (*Client, error) {
 		return nil, fmt.Errorf("failed to create discovery client: %w", err)
 	}
 
+	metadataClient, err := metadata.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata client: %w", err)
+	}
+
 	return &Client{
 		clientset:       clientset,
 		dynamicClient:   dynamicClient,
 		discoveryClient: discoveryClient,
+		metadataClient:  metadataClient,
 		config:          config,
 		restConfig:      restConfig,
 	}, nil
 }
 
+func applyTLSOptions(restConfig *rest.Config, opts ClientOptions) error {
+	if opts.CAFile != "" && opts.InsecureSkipTLSVerify {
+		return fmt.Errorf("cannot use a CA certificate together with insecure TLS verification")
+	}
+
+	if opts.CAFile != "" {
+		caData, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		restConfig.TLSClientConfig.CAFile = opts.CAFile
+		restConfig.TLSClientConfig.CAData = caData
+	}
+
+	if opts.InsecureSkipTLSVerify {
+		restConfig.TLSClientConfig.Insecure = true
+		restConfig.TLSClientConfig.CAFile = ""
+		restConfig.TLSClientConfig.CAData = nil
+	}
+
+	return nil
+}
+
 func (c *Client) RESTConfig() *rest.Config {
 	return c.restConfig
 }
@@ -69,14 +121,50 @@ func (c *Client) DiscoveryClient() discovery.DiscoveryInterface {
 	return c.discoveryClient
 }
 
+func (c *Client) MetadataClient() metadata.Interface {
+	return c.metadataClient
+}
+
 func (c *Client) TestConnection(ctx context.Context) error {
-	_, err := c.clientset.Discovery().ServerVersion()
+	_, err := c.clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
 	if err != nil {
 		return fmt.Errorf("failed to connect to cluster: %w", err)
 	}
 	return nil
 }
 
+func (c *Client) WaitForReady(ctx context.Context, timeout time.Duration) error {
+	return waitForConnection(ctx, c.TestConnection, timeout, readyInitialBackoff)
+}
+
+func waitForConnection(ctx context.Context, test func(context.Context) error, timeout, backoff time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		err := test(ctx)
+		if err == nil {
+			return nil
+		}
+		if apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("cluster not ready after %s: %w", timeout, err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > readyMaxBackoff {
+			backoff = readyMaxBackoff
+		}
+	}
+}
+
 func (c *Client) ClusterInfo(ctx context.Context) (string, error) {
 	rawConfig, err := c.config.RawConfig()
 	if err != nil {
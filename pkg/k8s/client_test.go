@@ -1,9 +1,26 @@
 package k8s
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestNewClient_InvalidPath(t *testing.T) {
@@ -64,3 +81,245 @@ users:
 		t.Error("expected error for missing cluster in kubeconfig")
 	}
 }
+
+func TestNewClient_MergedKubeconfigPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	first := `apiVersion: v1
+kind: Config
+current-context: second-context
+clusters:
+- name: first-cluster
+  cluster:
+    server: https://first.example.com:6443
+contexts:
+- name: first-context
+  context:
+    cluster: first-cluster
+    user: first-user
+users:
+- name: first-user
+  user:
+    token: first-token
+`
+	second := `apiVersion: v1
+kind: Config
+clusters:
+- name: second-cluster
+  cluster:
+    server: https://second.example.com:6443
+contexts:
+- name: second-context
+  context:
+    cluster: second-cluster
+    user: second-user
+users:
+- name: second-user
+  user:
+    token: second-token
+`
+	firstPath := filepath.Join(tmpDir, "first")
+	secondPath := filepath.Join(tmpDir, "second")
+	if err := os.WriteFile(firstPath, []byte(first), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(secondPath, []byte(second), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(firstPath + string(filepath.ListSeparator) + secondPath)
+	if err != nil {
+		t.Fatalf("NewClient failed for merged paths: %v", err)
+	}
+
+	if client.RESTConfig().Host != "https://second.example.com:6443" {
+		t.Errorf("expected host from second file, got %q", client.RESTConfig().Host)
+	}
+	if client.RESTConfig().BearerToken != "second-token" {
+		t.Errorf("expected token from second file, got %q", client.RESTConfig().BearerToken)
+	}
+}
+
+func TestNewClientWithOptions_CAFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	content := `apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test-cluster
+  cluster:
+    server: https://internal.example.com:6443
+contexts:
+- name: test
+  context:
+    cluster: test-cluster
+    user: test-user
+users:
+- name: test-user
+  user:
+    token: test-token
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	caPath := filepath.Join(tmpDir, "ca.crt")
+	caData := generateTestCA(t)
+	if err := os.WriteFile(caPath, caData, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClientWithOptions(configPath, ClientOptions{CAFile: caPath})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+
+	tlsConfig := client.RESTConfig().TLSClientConfig
+	if tlsConfig.CAFile != caPath {
+		t.Errorf("expected CA file %q, got %q", caPath, tlsConfig.CAFile)
+	}
+	if string(tlsConfig.CAData) != string(caData) {
+		t.Error("expected CA data to be loaded from the CA file")
+	}
+	if tlsConfig.Insecure {
+		t.Error("insecure should not be set")
+	}
+
+	if _, err := NewClientWithOptions(configPath, ClientOptions{CAFile: filepath.Join(tmpDir, "missing.crt")}); err == nil {
+		t.Error("expected error for missing CA file")
+	}
+	if _, err := NewClientWithOptions(configPath, ClientOptions{CAFile: caPath, InsecureSkipTLSVerify: true}); err == nil {
+		t.Error("expected error when combining CA file with insecure")
+	}
+
+	insecure, err := NewClientWithOptions(configPath, ClientOptions{InsecureSkipTLSVerify: true})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed with insecure: %v", err)
+	}
+	if !insecure.RESTConfig().TLSClientConfig.Insecure {
+		t.Error("expected insecure to be set")
+	}
+}
+
+func generateTestCA(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestWaitForConnectionRetriesUntilReady(t *testing.T) {
+	attempts := 0
+	test := func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	if err := waitForConnection(context.Background(), test, 5*time.Second, time.Millisecond); err != nil {
+		t.Fatalf("expected the connection to succeed, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestWaitForConnectionTimeout(t *testing.T) {
+	test := func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}
+
+	err := waitForConnection(context.Background(), test, 50*time.Millisecond, time.Millisecond)
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected the last connection error to be kept, got %v", err)
+	}
+}
+
+func TestWaitForConnectionFailsFastOnAuthErrors(t *testing.T) {
+	for _, authErr := range []error{
+		apierrors.NewUnauthorized("token expired"),
+		apierrors.NewForbidden(schema.GroupResource{}, "", errors.New("access denied")),
+	} {
+		attempts := 0
+		test := func(ctx context.Context) error {
+			attempts++
+			return fmt.Errorf("failed to connect to cluster: %w", authErr)
+		}
+
+		err := waitForConnection(context.Background(), test, 5*time.Second, time.Millisecond)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if attempts != 1 {
+			t.Errorf("expected a single attempt for %v, got %d", authErr, attempts)
+		}
+	}
+}
+
+func TestTestConnectionHonoursContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "config")
+	content := `apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: ` + server.URL + `
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test-user
+users:
+- name: test-user
+  user:
+    token: test-token
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(configPath)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := client.TestConnection(ctx); err == nil {
+		t.Fatal("expected the connection test to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("TestConnection ignored the context deadline, took %s", elapsed)
+	}
+}
@@ -3,14 +3,14 @@ package k8s
 import (
 	"os"
 	"path/filepath"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 func DiscoverKubeconfig(flagValue string, inContainer bool) string {
 	if flagValue != "" {
-		if inContainer && !hasPrefix(flagValue, "/host/") {
-			return "/host" + flagValue
-		}
-		return flagValue
+		return ResolveHostPath(flagValue, inContainer)
 	}
 
 	if env := os.Getenv("KUBECONFIG"); env != "" {
@@ -39,6 +39,27 @@ func DiscoverKubeconfig(flagValue string, inContainer bool) string {
 	return ""
 }
 
+func ResolveHostPath(path string, inContainer bool) string {
+	if inContainer && !hasPrefix(path, "/host/") {
+		return "/host" + path
+	}
+	return path
+}
+
 func hasPrefix(s, prefix string) bool {
 	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
 }
+
+func LoadingRules(kubeconfigPath string) *clientcmd.ClientConfigLoadingRules {
+	if kubeconfigPath == "" {
+		return clientcmd.NewDefaultClientConfigLoadingRules()
+	}
+
+	if strings.ContainsRune(kubeconfigPath, filepath.ListSeparator) {
+		rules := clientcmd.NewDefaultClientConfigLoadingRules()
+		rules.Precedence = filepath.SplitList(kubeconfigPath)
+		return rules
+	}
+
+	return &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath}
+}
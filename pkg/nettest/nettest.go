@@ -3,6 +3,7 @@ package nettest
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"sync"
 	"time"
@@ -14,9 +15,19 @@ import (
 )
 
 type NetworkTest struct {
-	client     kubernetes.Interface
-	restConfig *rest.Config
-	verbose    bool
+	client         kubernetes.Interface
+	restConfig     *rest.Config
+	verbose        bool
+	measureLatency bool
+	readyInterval  time.Duration
+	testTimeout    time.Duration
+	deadline       time.Duration
+	exec           func(ctx context.Context, podName, namespace string, cmd []string) (string, string, error)
+}
+
+type Timeouts struct {
+	Test    time.Duration
+	Overall time.Duration
 }
 
 type TestResult struct {
@@ -25,13 +36,34 @@ type TestResult struct {
 	TestType   string
 	Target     string
 	Success    bool
+	Skipped    bool
 	Error      string
+	LatencyMs  float64
+}
+
+type NodeIPs struct {
+	IPv4 string
+	IPv6 string
+}
+
+func (ips NodeIPs) For(sourceIP string) string {
+	if isIPv6(sourceIP) {
+		if ips.IPv6 != "" {
+			return ips.IPv6
+		}
+		return ips.IPv4
+	}
+	if ips.IPv4 != "" {
+		return ips.IPv4
+	}
+	return ips.IPv6
 }
 
 type TestSummary struct {
-	Total  int
-	Passed int
-	Failed int
+	Total   int
+	Passed  int
+	Failed  int
+	Skipped int
 }
 
 type NetworkTestReport struct {
@@ -42,12 +74,21 @@ type NetworkTestReport struct {
 	Summary     TestSummary
 }
 
-func New(client kubernetes.Interface, restConfig *rest.Config, verbose bool) *NetworkTest {
-	return &NetworkTest{
-		client:     client,
-		restConfig: restConfig,
-		verbose:    verbose,
+func New(client kubernetes.Interface, restConfig *rest.Config, verbose bool, timeouts Timeouts) *NetworkTest {
+	n := &NetworkTest{
+		client:        client,
+		restConfig:    restConfig,
+		verbose:       verbose,
+		readyInterval: podReadyPollInterval,
+		testTimeout:   timeouts.Test,
+		deadline:      timeouts.Overall,
 	}
+	n.exec = n.ExecInPod
+	return n
+}
+
+func (n *NetworkTest) SetMeasureLatency(enabled bool) {
+	n.measureLatency = enabled
 }
 
 func (n *NetworkTest) Run(ctx context.Context) (*NetworkTestReport, error) {
@@ -56,6 +97,13 @@ func (n *NetworkTest) Run(ctx context.Context) (*NetworkTestReport, error) {
 		TestResults: []TestResult{},
 	}
 
+	testCtx := ctx
+	if n.deadline > 0 {
+		var cancel context.CancelFunc
+		testCtx, cancel = context.WithDeadline(ctx, report.Timestamp.Add(n.deadline))
+		defer cancel()
+	}
+
 	if n.verbose {
 		fmt.Fprintln(os.Stderr, "[network-test] Cleaning up any previous test resources...")
 	}
@@ -126,18 +174,25 @@ func (n *NetworkTest) Run(ctx context.Context) (*NetworkTestReport, error) {
 	if n.verbose {
 		fmt.Fprintln(os.Stderr, "[network-test] Running tests...")
 	}
-	results := n.RunAllTests(ctx, testPods, coreDNSIPs, nodeIPs)
+	results := n.RunAllTests(testCtx, testPods, coreDNSIPs, nodeIPs)
 	report.TestResults = results
 
 	for _, r := range results {
 		report.Summary.Total++
-		if r.Success {
+		switch {
+		case r.Skipped:
+			report.Summary.Skipped++
+		case r.Success:
 			report.Summary.Passed++
-		} else {
+		default:
 			report.Summary.Failed++
 		}
 	}
 
+	if report.Summary.Skipped > 0 && n.verbose {
+		fmt.Fprintf(os.Stderr, "[network-test] Deadline of %s passed; skipped %d remaining tests\n", n.deadline, report.Summary.Skipped)
+	}
+
 	return report, nil
 }
 
@@ -187,20 +242,35 @@ func (n *NetworkTest) DiscoverCoreDNSPods(ctx context.Context) ([]string, error)
 	return dnsIPs, nil
 }
 
-func (n *NetworkTest) GetNodeInternalIPs(nodes []corev1.Node) map[string]string {
-	nodeIPs := make(map[string]string)
+func (n *NetworkTest) GetNodeInternalIPs(nodes []corev1.Node) map[string]NodeIPs {
+	nodeIPs := make(map[string]NodeIPs)
 	for _, node := range nodes {
+		var ips NodeIPs
 		for _, addr := range node.Status.Addresses {
-			if addr.Type == corev1.NodeInternalIP {
-				nodeIPs[node.Name] = addr.Address
-				break
+			if addr.Type != corev1.NodeInternalIP {
+				continue
+			}
+			if isIPv6(addr.Address) {
+				if ips.IPv6 == "" {
+					ips.IPv6 = addr.Address
+				}
+			} else if ips.IPv4 == "" {
+				ips.IPv4 = addr.Address
 			}
 		}
+		if ips.IPv4 != "" || ips.IPv6 != "" {
+			nodeIPs[node.Name] = ips
+		}
 	}
 	return nodeIPs
 }
 
-func (n *NetworkTest) RunAllTests(ctx context.Context, pods []TestPod, coreDNSIPs []string, nodeIPs map[string]string) []TestResult {
+func isIPv6(address string) bool {
+	ip := net.ParseIP(address)
+	return ip != nil && ip.To4() == nil
+}
+
+func (n *NetworkTest) RunAllTests(ctx context.Context, pods []TestPod, coreDNSIPs []string, nodeIPs map[string]NodeIPs) []TestResult {
 	var results []TestResult
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -226,7 +296,7 @@ func (n *NetworkTest) RunAllTests(ctx context.Context, pods []TestPod, coreDNSIP
 	return results
 }
 
-func (n *NetworkTest) RunPodTests(ctx context.Context, pod TestPod, coreDNSIPs []string, nodeIPs map[string]string, allPods []TestPod) []TestResult {
+func (n *NetworkTest) RunPodTests(ctx context.Context, pod TestPod, coreDNSIPs []string, nodeIPs map[string]NodeIPs, allPods []TestPod) []TestResult {
 	var results []TestResult
 
 	results = append(results, n.TestCoreDNSConnectivity(ctx, pod, coreDNSIPs)...)
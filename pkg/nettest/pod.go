@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -17,6 +20,8 @@ const (
 	testPodPrefix  = "nettest-"
 	testImage      = "busybox:1.36"
 	testListenPort = 8080
+
+	podReadyPollInterval = 2 * time.Second
 )
 
 type TestPod struct {
@@ -44,101 +49,132 @@ func (n *NetworkTest) EnsureNamespace(ctx context.Context) error {
 }
 
 func (n *NetworkTest) CreateTestPods(ctx context.Context, nodes []corev1.Node) ([]TestPod, error) {
-	var testPods []TestPod
+	testPods := make([]TestPod, len(nodes))
+	errs := make([]error, len(nodes))
+	var wg sync.WaitGroup
 
-	for _, node := range nodes {
-		podName := testPodPrefix + sanitizeNodeName(node.Name)
+	for i, node := range nodes {
+		testPods[i] = TestPod{
+			Name:     testPodPrefix + sanitizeNodeName(node.Name),
+			NodeName: node.Name,
+		}
 
-		if n.verbose {
-			fmt.Fprintf(os.Stderr, "[network-test] Creating test pod on node %s...\n", node.Name)
+		wg.Add(1)
+		go func(i int, pod TestPod) {
+			defer wg.Done()
+			errs[i] = n.createTestPod(ctx, pod)
+		}(i, testPods[i])
+	}
+
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		pod := &corev1.Pod{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      podName,
-				Namespace: testNamespace,
-				Labels: map[string]string{
-					"app.kubernetes.io/name":      "cluster-probe",
-					"app.kubernetes.io/component": "network-test",
-					"cluster-probe/node":          node.Name,
-				},
+	return testPods, nil
+}
+
+func (n *NetworkTest) createTestPod(ctx context.Context, testPod TestPod) error {
+	if n.verbose {
+		fmt.Fprintf(os.Stderr, "[network-test] Creating test pod on node %s...\n", testPod.NodeName)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testPod.Name,
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":      "cluster-probe",
+				"app.kubernetes.io/component": "network-test",
+				"cluster-probe/node":          testPod.NodeName,
 			},
-			Spec: corev1.PodSpec{
-				NodeName:      node.Name,
-				RestartPolicy: corev1.RestartPolicyNever,
-				Containers: []corev1.Container{
-					{
-						Name:    "nettest",
-						Image:   testImage,
-						Command: []string{"sleep", "3600"},
-					},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      testPod.NodeName,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "nettest",
+					Image:   testImage,
+					Command: []string{"sleep", "3600"},
 				},
-				Tolerations: []corev1.Toleration{
-					{
-						Operator: corev1.TolerationOpExists,
-					},
+			},
+			Tolerations: []corev1.Toleration{
+				{
+					Operator: corev1.TolerationOpExists,
 				},
 			},
-		}
+		},
+	}
 
-		_, err := n.client.CoreV1().Pods(testNamespace).Create(ctx, pod, metav1.CreateOptions{})
-		if err != nil {
-			if errors.IsAlreadyExists(err) {
-				if n.verbose {
-					fmt.Fprintf(os.Stderr, "[network-test] Pod %s already exists, reusing\n", podName)
-				}
-			} else {
-				return nil, fmt.Errorf("failed to create pod on node %s: %w", node.Name, err)
-			}
+	_, err := n.client.CoreV1().Pods(testNamespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create pod on node %s: %w", testPod.NodeName, err)
+		}
+		if n.verbose {
+			fmt.Fprintf(os.Stderr, "[network-test] Pod %s already exists, reusing\n", testPod.Name)
 		}
-
-		testPods = append(testPods, TestPod{
-			Name:     podName,
-			NodeName: node.Name,
-		})
 	}
-
-	return testPods, nil
+	return nil
 }
 
 func (n *NetworkTest) WaitForPodsReady(ctx context.Context, pods []TestPod, timeout time.Duration) error {
-	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
+	pending := make(map[string]*TestPod, len(pods))
 	for i := range pods {
-		pod := &pods[i]
-
-		err := wait.PollUntilContextCancel(timeoutCtx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
-			p, err := n.client.CoreV1().Pods(testNamespace).Get(ctx, pod.Name, metav1.GetOptions{})
-			if err != nil {
-				return false, nil
-			}
+		pending[pods[i].Name] = &pods[i]
+	}
 
-			if p.Status.Phase != corev1.PodRunning {
-				return false, nil
-			}
+	err := wait.PollUntilContextTimeout(ctx, n.readyInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		list, err := n.client.CoreV1().Pods(testNamespace).List(ctx, metav1.ListOptions{
+			LabelSelector: "app.kubernetes.io/component=network-test",
+		})
+		if err != nil {
+			return false, nil
+		}
 
-			for _, cs := range p.Status.ContainerStatuses {
-				if !cs.Ready {
-					return false, nil
-				}
+		for _, p := range list.Items {
+			pod, ok := pending[p.Name]
+			if !ok || !podReady(&p) {
+				continue
 			}
 
 			pod.PodIP = p.Status.PodIP
+			delete(pending, p.Name)
 			if n.verbose {
 				fmt.Fprintf(os.Stderr, "[network-test] Pod %s ready (%s)\n", pod.Name, pod.PodIP)
 			}
-			return true, nil
-		})
+		}
 
-		if err != nil {
-			return fmt.Errorf("pod %s failed to become ready: %w", pod.Name, err)
+		return len(pending) == 0, nil
+	})
+
+	if err != nil {
+		names := make([]string, 0, len(pending))
+		for name := range pending {
+			names = append(names, name)
 		}
+		sort.Strings(names)
+		return fmt.Errorf("pods %s failed to become ready: %w", strings.Join(names, ", "), err)
 	}
 
 	return nil
 }
 
+func podReady(p *corev1.Pod) bool {
+	if p.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, cs := range p.Status.ContainerStatuses {
+		if !cs.Ready {
+			return false
+		}
+	}
+	return true
+}
+
 func (n *NetworkTest) CleanupTestPods(ctx context.Context) error {
 	propagation := metav1.DeletePropagationForeground
 	err := n.client.CoreV1().Namespaces().Delete(ctx, testNamespace, metav1.DeleteOptions{
@@ -161,7 +197,7 @@ func (n *NetworkTest) CleanupTestPods(ctx context.Context) error {
 func (n *NetworkTest) StartListeners(ctx context.Context, pods []TestPod) {
 	for _, pod := range pods {
 		cmd := []string{"sh", "-c", fmt.Sprintf("nc -l -p %d &", testListenPort)}
-		_, _, _ = n.ExecInPod(ctx, pod.Name, testNamespace, cmd)
+		_, _, _ = n.exec(ctx, pod.Name, testNamespace, cmd)
 	}
 	time.Sleep(time.Second)
 }
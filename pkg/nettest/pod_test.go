@@ -0,0 +1,136 @@
+package nettest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testNodes(names ...string) []corev1.Node {
+	nodes := make([]corev1.Node, 0, len(names))
+	for _, name := range names {
+		nodes = append(nodes, corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	return nodes
+}
+
+func markPodReady(t *testing.T, n *NetworkTest, name, ip string) {
+	t.Helper()
+	pod, err := n.client.CoreV1().Pods(testNamespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("failed to get pod %s: %v", name, err)
+		return
+	}
+	pod.Status.Phase = corev1.PodRunning
+	pod.Status.PodIP = ip
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "nettest", Ready: true}}
+	if _, err := n.client.CoreV1().Pods(testNamespace).UpdateStatus(context.Background(), pod, metav1.UpdateOptions{}); err != nil {
+		t.Errorf("failed to update pod %s: %v", name, err)
+	}
+}
+
+func TestCreateTestPodsAndWaitForReady(t *testing.T) {
+	n := New(fake.NewSimpleClientset(), nil, false, Timeouts{})
+	n.readyInterval = 10 * time.Millisecond
+
+	pods, err := n.CreateTestPods(context.Background(), testNodes("node-a", "node-b", "node-c"))
+	if err != nil {
+		t.Fatalf("CreateTestPods failed: %v", err)
+	}
+	if len(pods) != 3 {
+		t.Fatalf("expected 3 test pods, got %d", len(pods))
+	}
+	for i, want := range []string{"node-a", "node-b", "node-c"} {
+		if pods[i].NodeName != want || pods[i].Name != testPodPrefix+want {
+			t.Errorf("pod %d: expected node %s in order, got %+v", i, want, pods[i])
+		}
+	}
+
+	go func() {
+		for i, pod := range pods {
+			time.Sleep(20 * time.Millisecond)
+			markPodReady(t, n, pod.Name, fmt.Sprintf("10.0.0.%d", i+1))
+		}
+	}()
+
+	if err := n.WaitForPodsReady(context.Background(), pods, 5*time.Second); err != nil {
+		t.Fatalf("WaitForPodsReady failed: %v", err)
+	}
+	for i, pod := range pods {
+		if want := fmt.Sprintf("10.0.0.%d", i+1); pod.PodIP != want {
+			t.Errorf("pod %s: expected PodIP %s, got %q", pod.Name, want, pod.PodIP)
+		}
+	}
+}
+
+func TestWaitForPodsReadyTimeout(t *testing.T) {
+	n := New(fake.NewSimpleClientset(), nil, false, Timeouts{})
+	n.readyInterval = 10 * time.Millisecond
+
+	pods, err := n.CreateTestPods(context.Background(), testNodes("node-a", "node-b"))
+	if err != nil {
+		t.Fatalf("CreateTestPods failed: %v", err)
+	}
+	markPodReady(t, n, pods[0].Name, "10.0.0.1")
+
+	err = n.WaitForPodsReady(context.Background(), pods, 100*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if !strings.Contains(err.Error(), pods[1].Name) || strings.Contains(err.Error(), pods[0].Name) {
+		t.Errorf("expected error to name only the pod that is not ready, got %v", err)
+	}
+	if pods[0].PodIP != "10.0.0.1" {
+		t.Errorf("expected ready pod to keep its PodIP, got %q", pods[0].PodIP)
+	}
+}
+
+func TestGetNodeInternalIPsDualStack(t *testing.T) {
+	nodes := []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "dual"},
+			Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: "dual"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: corev1.NodeInternalIP, Address: "fd00::1"},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "v6only"},
+			Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "fd00::2"},
+			}},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "none"}},
+	}
+
+	ips := New(fake.NewSimpleClientset(), nil, false, Timeouts{}).GetNodeInternalIPs(nodes)
+	if len(ips) != 2 {
+		t.Fatalf("expected addresses for 2 nodes, got %v", ips)
+	}
+	if got := ips["dual"]; got.IPv4 != "10.0.0.1" || got.IPv6 != "fd00::1" {
+		t.Errorf("dual-stack node: got %+v", got)
+	}
+
+	tests := []struct {
+		node   string
+		source string
+		want   string
+	}{
+		{"dual", "10.42.0.5", "10.0.0.1"},
+		{"dual", "fd42::5", "fd00::1"},
+		{"v6only", "10.42.0.5", "fd00::2"},
+		{"v6only", "fd42::5", "fd00::2"},
+	}
+	for _, tt := range tests {
+		if got := ips[tt.node].For(tt.source); got != tt.want {
+			t.Errorf("%s.For(%s) = %s, want %s", tt.node, tt.source, got, tt.want)
+		}
+	}
+}
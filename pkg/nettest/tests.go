@@ -3,33 +3,94 @@ package nettest
 import (
 	"context"
 	"fmt"
+	"math"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const (
+	latencyPingCount       = 5
+	defaultProbeTimeout    = 3 * time.Second
+	defaultExternalTimeout = 5 * time.Second
+	defaultDNSTimeout      = 10 * time.Second
+	execOverhead           = 5 * time.Second
+)
+
+func (n *NetworkTest) timeout(fallback time.Duration) time.Duration {
+	if n.testTimeout > 0 {
+		return n.testTimeout
+	}
+	return fallback
+}
+
+func waitSeconds(timeout time.Duration) string {
+	seconds := int(math.Ceil(timeout.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
+
+func (n *NetworkTest) runTest(ctx context.Context, podName string, cmd []string, timeout time.Duration, result *TestResult) {
+	if ctx.Err() != nil {
+		markSkipped(result)
+		return
+	}
+
+	testCtx, cancel := context.WithTimeout(ctx, timeout+execOverhead)
+	defer cancel()
+
+	_, _, err := n.exec(testCtx, podName, testNamespace, cmd)
+	if err == nil {
+		result.Success = true
+		return
+	}
+	if ctx.Err() != nil {
+		markSkipped(result)
+		return
+	}
+	if testCtx.Err() != nil {
+		result.Error = fmt.Sprintf("timed out after %s", timeout+execOverhead)
+		return
+	}
+	result.Error = err.Error()
+}
+
+func markSkipped(result *TestResult) {
+	result.Success = false
+	result.Skipped = true
+	result.Error = "skipped: network test deadline passed"
+}
+
+func statusLabel(result TestResult) string {
+	switch {
+	case result.Skipped:
+		return "SKIPPED"
+	case result.Success:
+		return "OK"
+	default:
+		return "FAILED"
+	}
+}
+
 func (n *NetworkTest) TestCoreDNSConnectivity(ctx context.Context, pod TestPod, dnsIPs []string) []TestResult {
 	var results []TestResult
 
 	for _, ip := range dnsIPs {
-		cmd := []string{"nc", "-z", "-w", "3", ip, "53"}
-		_, _, err := n.ExecInPod(ctx, pod.Name, testNamespace, cmd)
-
+		timeout := n.timeout(defaultProbeTimeout)
 		result := TestResult{
 			SourceNode: pod.NodeName,
 			SourcePod:  pod.Name,
 			TestType:   "coredns",
-			Target:     fmt.Sprintf("%s:53", ip),
-			Success:    err == nil,
-		}
-
-		if err != nil {
-			result.Error = err.Error()
+			Target:     net.JoinHostPort(ip, "53"),
 		}
+		n.runTest(ctx, pod.Name, []string{"nc", "-z", "-w", waitSeconds(timeout), ip, "53"}, timeout, &result)
 
 		if n.verbose {
-			status := "OK"
-			if !result.Success {
-				status = "FAILED"
-			}
+			status := statusLabel(result)
 			fmt.Fprintf(os.Stderr, "[network-test]   %s: CoreDNS %s - %s\n", pod.NodeName, result.Target, status)
 		}
 
@@ -40,26 +101,16 @@ func (n *NetworkTest) TestCoreDNSConnectivity(ctx context.Context, pod TestPod,
 }
 
 func (n *NetworkTest) TestDNSResolution(ctx context.Context, pod TestPod) TestResult {
-	cmd := []string{"nslookup", "github.com"}
-	_, _, err := n.ExecInPod(ctx, pod.Name, testNamespace, cmd)
-
 	result := TestResult{
 		SourceNode: pod.NodeName,
 		SourcePod:  pod.Name,
 		TestType:   "dns",
 		Target:     "github.com",
-		Success:    err == nil,
-	}
-
-	if err != nil {
-		result.Error = err.Error()
 	}
+	n.runTest(ctx, pod.Name, []string{"nslookup", "github.com"}, n.timeout(defaultDNSTimeout), &result)
 
 	if n.verbose {
-		status := "OK"
-		if !result.Success {
-			status = "FAILED"
-		}
+		status := statusLabel(result)
 		fmt.Fprintf(os.Stderr, "[network-test]   %s: DNS %s - %s\n", pod.NodeName, result.Target, status)
 	}
 
@@ -67,60 +118,43 @@ func (n *NetworkTest) TestDNSResolution(ctx context.Context, pod TestPod) TestRe
 }
 
 func (n *NetworkTest) TestExternalTCP(ctx context.Context, pod TestPod) TestResult {
-	cmd := []string{"nc", "-z", "-w", "5", "github.com", "443"}
-	_, _, err := n.ExecInPod(ctx, pod.Name, testNamespace, cmd)
-
+	timeout := n.timeout(defaultExternalTimeout)
 	result := TestResult{
 		SourceNode: pod.NodeName,
 		SourcePod:  pod.Name,
 		TestType:   "external-tcp",
 		Target:     "github.com:443",
-		Success:    err == nil,
-	}
-
-	if err != nil {
-		result.Error = err.Error()
 	}
+	n.runTest(ctx, pod.Name, []string{"nc", "-z", "-w", waitSeconds(timeout), "github.com", "443"}, timeout, &result)
 
 	if n.verbose {
-		status := "OK"
-		if !result.Success {
-			status = "FAILED"
-		}
+		status := statusLabel(result)
 		fmt.Fprintf(os.Stderr, "[network-test]   %s: TCP %s - %s\n", pod.NodeName, result.Target, status)
 	}
 
 	return result
 }
 
-func (n *NetworkTest) TestKubeletConnectivity(ctx context.Context, pod TestPod, nodeIPs map[string]string) []TestResult {
+func (n *NetworkTest) TestKubeletConnectivity(ctx context.Context, pod TestPod, nodeIPs map[string]NodeIPs) []TestResult {
 	var results []TestResult
 
-	for nodeName, nodeIP := range nodeIPs {
+	for nodeName, ips := range nodeIPs {
 		if nodeName == pod.NodeName {
 			continue
 		}
+		nodeIP := ips.For(pod.PodIP)
 
-		cmd := []string{"nc", "-z", "-w", "3", nodeIP, "10250"}
-		_, _, err := n.ExecInPod(ctx, pod.Name, testNamespace, cmd)
-
+		timeout := n.timeout(defaultProbeTimeout)
 		result := TestResult{
 			SourceNode: pod.NodeName,
 			SourcePod:  pod.Name,
 			TestType:   "kubelet",
-			Target:     fmt.Sprintf("%s:10250 (%s)", nodeIP, nodeName),
-			Success:    err == nil,
-		}
-
-		if err != nil {
-			result.Error = err.Error()
+			Target:     fmt.Sprintf("%s (%s)", net.JoinHostPort(nodeIP, "10250"), nodeName),
 		}
+		n.runTest(ctx, pod.Name, []string{"nc", "-z", "-w", waitSeconds(timeout), nodeIP, "10250"}, timeout, &result)
 
 		if n.verbose {
-			status := "OK"
-			if !result.Success {
-				status = "FAILED"
-			}
+			status := statusLabel(result)
 			fmt.Fprintf(os.Stderr, "[network-test]   %s: Kubelet %s - %s\n", pod.NodeName, result.Target, status)
 		}
 
@@ -138,25 +172,23 @@ func (n *NetworkTest) TestPodToPod(ctx context.Context, sourcePod TestPod, allPo
 			continue
 		}
 
-		cmd := []string{"nc", "-z", "-w", "3", targetPod.PodIP, fmt.Sprintf("%d", testListenPort)}
-		_, _, err := n.ExecInPod(ctx, sourcePod.Name, testNamespace, cmd)
-
+		timeout := n.timeout(defaultProbeTimeout)
 		result := TestResult{
 			SourceNode: sourcePod.NodeName,
 			SourcePod:  sourcePod.Name,
 			TestType:   "pod-to-pod",
-			Target:     fmt.Sprintf("%s:%d (%s)", targetPod.PodIP, testListenPort, targetPod.NodeName),
-			Success:    err == nil,
+			Target:     fmt.Sprintf("%s (%s)", net.JoinHostPort(targetPod.PodIP, strconv.Itoa(testListenPort)), targetPod.NodeName),
 		}
+		n.runTest(ctx, sourcePod.Name, []string{"nc", "-z", "-w", waitSeconds(timeout), targetPod.PodIP, strconv.Itoa(testListenPort)}, timeout, &result)
 
-		if err != nil {
-			result.Error = err.Error()
+		if result.Success && n.measureLatency {
+			result.LatencyMs = n.measurePodLatency(ctx, sourcePod, targetPod)
 		}
 
 		if n.verbose {
-			status := "OK"
-			if !result.Success {
-				status = "FAILED"
+			status := statusLabel(result)
+			if result.LatencyMs > 0 {
+				status = fmt.Sprintf("%s (%.2f ms)", status, result.LatencyMs)
 			}
 			fmt.Fprintf(os.Stderr, "[network-test]   %s: Pod %s - %s\n", sourcePod.NodeName, result.Target, status)
 		}
@@ -166,3 +198,49 @@ func (n *NetworkTest) TestPodToPod(ctx context.Context, sourcePod TestPod, allPo
 
 	return results
 }
+
+func (n *NetworkTest) measurePodLatency(ctx context.Context, sourcePod, targetPod TestPod) float64 {
+	cmd := []string{"ping", "-c", strconv.Itoa(latencyPingCount), "-W", "2", targetPod.PodIP}
+	stdout, _, err := n.exec(ctx, sourcePod.Name, testNamespace, cmd)
+	if err != nil {
+		if n.verbose {
+			fmt.Fprintf(os.Stderr, "[network-test]   %s: latency to %s unavailable: %v\n", sourcePod.NodeName, targetPod.PodIP, err)
+		}
+		return 0
+	}
+
+	latency, err := parsePingLatency(stdout)
+	if err != nil {
+		if n.verbose {
+			fmt.Fprintf(os.Stderr, "[network-test]   %s: latency to %s unavailable: %v\n", sourcePod.NodeName, targetPod.PodIP, err)
+		}
+		return 0
+	}
+	return latency
+}
+
+func parsePingLatency(output string) (float64, error) {
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.Contains(line, "min/avg/max") {
+			continue
+		}
+
+		_, values, found := strings.Cut(line, "=")
+		if !found {
+			break
+		}
+
+		fields := strings.Split(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(values), "ms")), "/")
+		if len(fields) < 3 {
+			break
+		}
+
+		avg, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid average round-trip time %q: %w", fields[1], err)
+		}
+		return avg, nil
+	}
+
+	return 0, fmt.Errorf("no round-trip summary in ping output")
+}
@@ -0,0 +1,107 @@
+package nettest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParsePingLatency(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    float64
+		wantErr bool
+	}{
+		{
+			name: "busybox",
+			output: `PING 10.42.1.7 (10.42.1.7): 56 data bytes
+64 bytes from 10.42.1.7: seq=0 ttl=62 time=0.612 ms
+64 bytes from 10.42.1.7: seq=1 ttl=62 time=0.488 ms
+
+--- 10.42.1.7 ping statistics ---
+2 packets transmitted, 2 packets received, 0% packet loss
+round-trip min/avg/max = 0.488/0.550/0.612 ms
+`,
+			want: 0.550,
+		},
+		{
+			name: "iputils",
+			output: `--- 10.42.1.7 ping statistics ---
+5 packets transmitted, 5 received, 0% packet loss, time 4005ms
+rtt min/avg/max/mdev = 1.021/1.254/1.610/0.201 ms
+`,
+			want: 1.254,
+		},
+		{
+			name: "all packets lost",
+			output: `--- 10.42.1.7 ping statistics ---
+5 packets transmitted, 0 packets received, 100% packet loss
+`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed summary",
+			output:  "round-trip min/avg/max = 0.4/fast/0.6 ms\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePingLatency(tt.output)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPodTestsSkipsAfterDeadline(t *testing.T) {
+	n := New(fake.NewSimpleClientset(), nil, false, Timeouts{Test: time.Second})
+	calls := 0
+	n.exec = func(ctx context.Context, podName, namespace string, cmd []string) (string, string, error) {
+		calls++
+		if calls == 1 {
+			return "", "", nil
+		}
+		<-ctx.Done()
+		return "", "", ctx.Err()
+	}
+
+	pods := []TestPod{
+		{Name: "nettest-a", NodeName: "node-a", PodIP: "10.0.0.1"},
+		{Name: "nettest-b", NodeName: "node-b", PodIP: "10.0.0.2"},
+		{Name: "nettest-c", NodeName: "node-c", PodIP: "10.0.0.3"},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	results := n.RunPodTests(ctx, pods[0], nil, nil, pods)
+
+	if len(results) != 4 {
+		t.Fatalf("expected dns, external-tcp and two pod-to-pod results, got %+v", results)
+	}
+	if !results[0].Success || results[0].Skipped {
+		t.Errorf("test finished before the deadline should pass, got %+v", results[0])
+	}
+	for _, r := range results[1:] {
+		if !r.Skipped || r.Success {
+			t.Errorf("%s %s: expected skipped after the deadline, got %+v", r.TestType, r.Target, r)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("tests after the deadline should not run, got %d exec calls", calls)
+	}
+}
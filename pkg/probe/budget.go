@@ -0,0 +1,102 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const ListPageSize = 500
+
+type budgetKey struct{}
+
+type resourceBudget struct {
+	mu      sync.Mutex
+	limit   int
+	fetched int
+	sampled []string
+}
+
+func withResourceBudget(ctx context.Context, limit int) (context.Context, *resourceBudget) {
+	budget := &resourceBudget{limit: limit}
+	return context.WithValue(ctx, budgetKey{}, budget), budget
+}
+
+func (b *resourceBudget) take(want int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	granted := b.limit - b.fetched
+	if granted < 0 {
+		granted = 0
+	}
+	if want < granted {
+		granted = want
+	}
+	b.fetched += granted
+	return granted
+}
+
+func (b *resourceBudget) record(note string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sampled = append(b.sampled, note)
+}
+
+func (b *resourceBudget) results(checkName string) []Result {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	results := make([]Result, 0, len(b.sampled))
+	for _, note := range b.sampled {
+		results = append(results, Result{
+			CheckName: checkName,
+			Severity:  SeverityOK,
+			Message:   note,
+			Details: []string{
+				fmt.Sprintf("This check may fetch at most %d objects per scan; findings cover only the sampled objects", b.limit),
+				"Raise --resource-budget, or set it to 0, for a complete scan",
+			},
+		})
+	}
+	return results
+}
+
+func ListAll[T any](ctx context.Context, resource string, list func(opts metav1.ListOptions) ([]T, metav1.ListMeta, error)) ([]T, metav1.ListMeta, error) {
+	budget, _ := ctx.Value(budgetKey{}).(*resourceBudget)
+
+	var items []T
+	opts := metav1.ListOptions{Limit: ListPageSize}
+	for {
+		page, meta, err := list(opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+
+		if budget != nil {
+			if granted := budget.take(len(page)); granted < len(page) {
+				remaining := int64(len(page) - granted)
+				total := fmt.Sprintf("more than %d", len(items)+len(page))
+				if meta.RemainingItemCount != nil {
+					remaining += *meta.RemainingItemCount
+					total = fmt.Sprintf("%d", len(items)+len(page)+int(*meta.RemainingItemCount))
+				} else if meta.Continue == "" {
+					total = fmt.Sprintf("%d", len(items)+len(page))
+				}
+				items = append(items, page[:granted]...)
+				budget.record(fmt.Sprintf("Sampled %d of %s %s (resource budget reached)", len(items), total, resource))
+				return items, metav1.ListMeta{RemainingItemCount: &remaining}, nil
+			}
+		}
+
+		items = append(items, page...)
+		if meta.Continue == "" {
+			return items, metav1.ListMeta{}, nil
+		}
+		opts.Continue = meta.Continue
+	}
+}
+
+func IsSampled(meta metav1.ListMeta) bool {
+	return meta.RemainingItemCount != nil && *meta.RemainingItemCount > 0
+}
@@ -0,0 +1,447 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var caInjectionAnnotations = []string{
+	"cert-manager.io/inject-ca-from",
+	"cert-manager.io/inject-ca-from-secret",
+	"cert-manager.io/inject-apiserver-ca",
+	"service.beta.openshift.io/inject-cabundle",
+}
+
+const olmOwnerLabel = "olm.owner"
+
+type webhookInfo struct {
+	configKind        string
+	configName        string
+	name              string
+	timeoutSeconds    *int32
+	failurePolicy     *admissionregistrationv1.FailurePolicyType
+	sideEffects       *admissionregistrationv1.SideEffectClass
+	reinvocation      *admissionregistrationv1.ReinvocationPolicyType
+	rules             []admissionregistrationv1.RuleWithOperations
+	namespaceSelector *metav1.LabelSelector
+	objectSelector    *metav1.LabelSelector
+	clientConfig      admissionregistrationv1.WebhookClientConfig
+	caInjected        bool
+}
+
+type AdmissionWebhooks struct {
+	timeoutThreshold int32
+}
+
+func NewAdmissionWebhooks() *AdmissionWebhooks {
+	return &AdmissionWebhooks{
+		timeoutThreshold: 10,
+	}
+}
+
+func (c *AdmissionWebhooks) Name() string {
+	return "admission-webhooks"
+}
+
+func (c *AdmissionWebhooks) Tier() int {
+	return 1
+}
+
+func (c *AdmissionWebhooks) Configure(cfg *config.Config) {
+	c.timeoutThreshold = int32(cfg.GetThreshold("webhook_timeout_seconds"))
+}
+
+func (c *AdmissionWebhooks) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	validating, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validating webhook configurations: %w", err)
+	}
+
+	mutating, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mutating webhook configurations: %w", err)
+	}
+
+	webhooks := []webhookInfo{}
+	for _, cfg := range validating.Items {
+		for _, wh := range cfg.Webhooks {
+			webhooks = append(webhooks, webhookInfo{
+				configKind:     "ValidatingWebhookConfiguration",
+				configName:     cfg.Name,
+				name:           wh.Name,
+				timeoutSeconds: wh.TimeoutSeconds,
+				failurePolicy:  wh.FailurePolicy,
+				sideEffects:    wh.SideEffects,
+				rules:          wh.Rules,
+				clientConfig:   wh.ClientConfig,
+				caInjected:     hasCAInjection(cfg.ObjectMeta),
+			})
+		}
+	}
+	for _, cfg := range mutating.Items {
+		for _, wh := range cfg.Webhooks {
+			webhooks = append(webhooks, webhookInfo{
+				configKind:        "MutatingWebhookConfiguration",
+				configName:        cfg.Name,
+				name:              wh.Name,
+				timeoutSeconds:    wh.TimeoutSeconds,
+				failurePolicy:     wh.FailurePolicy,
+				sideEffects:       wh.SideEffects,
+				reinvocation:      wh.ReinvocationPolicy,
+				rules:             wh.Rules,
+				namespaceSelector: wh.NamespaceSelector,
+				objectSelector:    wh.ObjectSelector,
+				clientConfig:      wh.ClientConfig,
+				caInjected:        hasCAInjection(cfg.ObjectMeta),
+			})
+		}
+	}
+
+	issues := 0
+	for _, wh := range webhooks {
+		if c.checkWebhook(wh, result) {
+			issues++
+		}
+	}
+
+	overlapping := c.checkMutatingOverlap(webhooks, result)
+
+	severity := probe.SeverityOK
+	if issues > 0 || overlapping > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Admission webhooks: %d total, %d misconfigured", len(webhooks), issues),
+		Details: []string{
+			fmt.Sprintf("Validating configurations: %d", len(validating.Items)),
+			fmt.Sprintf("Mutating configurations: %d", len(mutating.Items)),
+			fmt.Sprintf("Resources mutated by several webhooks: %d", overlapping),
+		},
+	})
+
+	return result, nil
+}
+
+func (c *AdmissionWebhooks) checkWebhook(wh webhookInfo, result *probe.CheckResult) bool {
+	timeout := int32(10)
+	if wh.timeoutSeconds != nil {
+		timeout = *wh.timeoutSeconds
+	}
+
+	failurePolicy := admissionregistrationv1.Fail
+	if wh.failurePolicy != nil {
+		failurePolicy = *wh.failurePolicy
+	}
+
+	details := []string{
+		fmt.Sprintf("%s: %s", wh.configKind, wh.configName),
+		fmt.Sprintf("Failure policy: %s", failurePolicy),
+		fmt.Sprintf("Timeout: %ds", timeout),
+	}
+	details = append(details, formatWebhookRules(wh.rules)...)
+
+	flagged := false
+
+	if failurePolicy == admissionregistrationv1.Fail && timeout > c.timeoutThreshold {
+		flagged = true
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("Webhook %s has a %ds timeout with failurePolicy Fail", wh.name, timeout),
+			Details:     details,
+			Remediation: fmt.Sprintf("Lower timeoutSeconds to %d or less, or use failurePolicy Ignore if the webhook is not security-critical", c.timeoutThreshold),
+		})
+	}
+
+	if wh.sideEffects == nil || *wh.sideEffects == admissionregistrationv1.SideEffectClassUnknown || *wh.sideEffects == admissionregistrationv1.SideEffectClassSome {
+		flagged = true
+		sideEffects := "unset"
+		if wh.sideEffects != nil {
+			sideEffects = string(*wh.sideEffects)
+		}
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("Webhook %s has sideEffects %s", wh.name, sideEffects),
+			Details:     details,
+			Remediation: "Set sideEffects to None or NoneOnDryRun so dry-run requests are not blocked",
+		})
+	}
+
+	if service := wh.clientConfig.Service; service != nil && len(wh.clientConfig.CABundle) == 0 && !wh.caInjected {
+		flagged = true
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityWarning,
+			Message:   fmt.Sprintf("Webhook %s has an empty caBundle", wh.name),
+			Details: append(details,
+				fmt.Sprintf("Service: %s/%s", service.Namespace, service.Name),
+				"No cert-manager, OpenShift service CA or OLM CA injection is configured"),
+			Remediation: "The API server cannot verify the webhook's serving certificate without a caBundle, so every call fails with a TLS error. " +
+				"Reinstall the webhook, or add a CA injection annotation such as cert-manager.io/inject-ca-from",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl get %s %s -o yaml", strings.ToLower(wh.configKind), wh.configName),
+			},
+		})
+	}
+
+	return flagged
+}
+
+func hasCAInjection(meta metav1.ObjectMeta) bool {
+	for _, annotation := range caInjectionAnnotations {
+		if meta.Annotations[annotation] != "" {
+			return true
+		}
+	}
+	return meta.Labels[olmOwnerLabel] != ""
+}
+
+func (c *AdmissionWebhooks) checkMutatingOverlap(webhooks []webhookInfo, result *probe.CheckResult) int {
+	var mutating []webhookInfo
+	for _, wh := range webhooks {
+		if wh.configKind == "MutatingWebhookConfiguration" {
+			mutating = append(mutating, wh)
+		}
+	}
+
+	mutators := make(map[string][]webhookInfo)
+	seen := make(map[string]bool)
+	add := func(target string, wh webhookInfo) {
+		key := target + "\x00" + wh.configName + "\x00" + wh.name
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		mutators[target] = append(mutators[target], wh)
+	}
+	for i := range mutating {
+		for j := i + 1; j < len(mutating); j++ {
+			a, b := mutating[i], mutating[j]
+			if selectorsDisjoint(a.namespaceSelector, b.namespaceSelector) || selectorsDisjoint(a.objectSelector, b.objectSelector) {
+				continue
+			}
+			for _, target := range overlappingTargets(a.rules, b.rules) {
+				add(target, a)
+				add(target, b)
+			}
+		}
+	}
+
+	targets := make([]string, 0, len(mutators))
+	for target, whs := range mutators {
+		if len(whs) > 1 {
+			targets = append(targets, target)
+		}
+	}
+	sort.Strings(targets)
+
+	for _, target := range targets {
+		details := make([]string, 0, len(mutators[target]))
+		for _, wh := range mutators[target] {
+			reinvocation := admissionregistrationv1.NeverReinvocationPolicy
+			if wh.reinvocation != nil {
+				reinvocation = *wh.reinvocation
+			}
+			details = append(details, fmt.Sprintf("%s (MutatingWebhookConfiguration %s, reinvocationPolicy %s)", wh.name, wh.configName, reinvocation))
+		}
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("%d mutating webhooks modify %s", len(mutators[target]), target),
+			Details:     details,
+			Remediation: "The API server does not guarantee the order mutating webhooks run in, so overlapping patches can produce different objects from one request to the next. Make the webhooks touch disjoint fields, narrow their rules or objectSelectors, or set reinvocationPolicy IfNeeded so each sees the others' changes",
+		})
+	}
+
+	return len(targets)
+}
+
+func overlappingTargets(a, b []admissionregistrationv1.RuleWithOperations) []string {
+	var targets []string
+	for _, ra := range a {
+		for _, rb := range b {
+			if !operationsOverlap(ra.Operations, rb.Operations) || !scopesOverlap(ra.Scope, rb.Scope) {
+				continue
+			}
+			for _, ga := range ra.APIGroups {
+				for _, gb := range rb.APIGroups {
+					group, ok := intersectWildcard(ga, gb)
+					if !ok {
+						continue
+					}
+					for _, resA := range ra.Resources {
+						for _, resB := range rb.Resources {
+							if resource, ok := intersectResource(resA, resB); ok {
+								targets = append(targets, formatGroupResource(group, resource))
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return targets
+}
+
+func operationsOverlap(a, b []admissionregistrationv1.OperationType) bool {
+	for _, opA := range a {
+		for _, opB := range b {
+			if _, ok := intersectWildcard(string(opA), string(opB)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func scopesOverlap(a, b *admissionregistrationv1.ScopeType) bool {
+	scopeA, scopeB := string(admissionregistrationv1.AllScopes), string(admissionregistrationv1.AllScopes)
+	if a != nil {
+		scopeA = string(*a)
+	}
+	if b != nil {
+		scopeB = string(*b)
+	}
+	_, ok := intersectWildcard(scopeA, scopeB)
+	return ok
+}
+
+func intersectWildcard(a, b string) (string, bool) {
+	switch {
+	case a == b:
+		return a, true
+	case a == "*":
+		return b, true
+	case b == "*":
+		return a, true
+	}
+	return "", false
+}
+
+func intersectResource(a, b string) (string, bool) {
+	if a == "*/*" {
+		return b, true
+	}
+	if b == "*/*" {
+		return a, true
+	}
+	resA, subA, _ := strings.Cut(a, "/")
+	resB, subB, _ := strings.Cut(b, "/")
+
+	resource, ok := intersectWildcard(resA, resB)
+	if !ok {
+		return "", false
+	}
+	if (subA == "") != (subB == "") {
+		return "", false
+	}
+	if subA == "" {
+		return resource, true
+	}
+	sub, ok := intersectWildcard(subA, subB)
+	if !ok {
+		return "", false
+	}
+	return resource + "/" + sub, true
+}
+
+func selectorsDisjoint(a, b *metav1.LabelSelector) bool {
+	allowedA, forbiddenA := selectorConstraints(a)
+	allowedB, forbiddenB := selectorConstraints(b)
+
+	for key, valuesA := range allowedA {
+		if forbiddenB[key] {
+			return true
+		}
+		valuesB, ok := allowedB[key]
+		if !ok {
+			continue
+		}
+		shared := false
+		for value := range valuesA {
+			if valuesB[value] {
+				shared = true
+				break
+			}
+		}
+		if !shared {
+			return true
+		}
+	}
+	for key := range allowedB {
+		if forbiddenA[key] {
+			return true
+		}
+	}
+	return false
+}
+
+func selectorConstraints(selector *metav1.LabelSelector) (map[string]map[string]bool, map[string]bool) {
+	allowed := make(map[string]map[string]bool)
+	forbidden := make(map[string]bool)
+	if selector == nil {
+		return allowed, forbidden
+	}
+
+	restrict := func(key string, values []string) {
+		next := make(map[string]bool)
+		for _, value := range values {
+			if current, ok := allowed[key]; !ok || current[value] {
+				next[value] = true
+			}
+		}
+		allowed[key] = next
+	}
+	for key, value := range selector.MatchLabels {
+		restrict(key, []string{value})
+	}
+	for _, expr := range selector.MatchExpressions {
+		switch expr.Operator {
+		case metav1.LabelSelectorOpIn:
+			restrict(expr.Key, expr.Values)
+		case metav1.LabelSelectorOpDoesNotExist:
+			forbidden[expr.Key] = true
+		}
+	}
+	return allowed, forbidden
+}
+
+func formatWebhookRules(rules []admissionregistrationv1.RuleWithOperations) []string {
+	formatted := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		operations := make([]string, 0, len(rule.Operations))
+		for _, op := range rule.Operations {
+			operations = append(operations, string(op))
+		}
+
+		scope := string(admissionregistrationv1.AllScopes)
+		if rule.Scope != nil {
+			scope = string(*rule.Scope)
+		}
+
+		formatted = append(formatted, fmt.Sprintf("Rule: %s on %s in groups [%s] (scope %s)",
+			strings.Join(operations, ","),
+			strings.Join(rule.Resources, ","),
+			strings.Join(rule.APIGroups, ","),
+			scope))
+	}
+	return formatted
+}
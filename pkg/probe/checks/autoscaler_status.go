@@ -0,0 +1,93 @@
+package checks
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	autoscalerStatusNamespace = "kube-system"
+	autoscalerStatusConfigMap = "cluster-autoscaler-status"
+)
+
+type autoscalerStatus struct {
+	scaleUp        []string
+	scaleUpFailing bool
+	unhealthy      bool
+}
+
+func loadAutoscalerStatus(ctx context.Context, client kubernetes.Interface) *autoscalerStatus {
+	cm, err := client.CoreV1().ConfigMaps(autoscalerStatusNamespace).Get(ctx, autoscalerStatusConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	return parseAutoscalerStatus(cm.Data["status"])
+}
+
+func parseAutoscalerStatus(data string) *autoscalerStatus {
+	status := &autoscalerStatus{}
+	lines := strings.Split(data, "\n")
+
+	for i, line := range lines {
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "scaleup":
+			word := firstWord(value)
+			if word == "" {
+				word = nestedStatus(lines[i+1:])
+			}
+			if word == "" {
+				continue
+			}
+			status.scaleUp = append(status.scaleUp, word)
+			if strings.EqualFold(word, "Backoff") {
+				status.scaleUpFailing = true
+			}
+		case "health":
+			word := firstWord(value)
+			if word == "" {
+				word = nestedStatus(lines[i+1:])
+			}
+			if strings.EqualFold(word, "Unhealthy") {
+				status.unhealthy = true
+			}
+		}
+	}
+
+	return status
+}
+
+func nestedStatus(lines []string) string {
+	for _, line := range lines {
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			return ""
+		}
+		if strings.EqualFold(key, "status") {
+			return firstWord(value)
+		}
+	}
+	return ""
+}
+
+func firstWord(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+func (s *autoscalerStatus) summary() string {
+	if len(s.scaleUp) == 0 {
+		return "Cluster autoscaler: present, no scale-up status reported"
+	}
+	return "Cluster autoscaler scale-up: " + strings.Join(s.scaleUp, ", ")
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/punasusi/cluster-probe/pkg/probe"
 	certv1 "k8s.io/api/certificates/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -26,6 +28,14 @@ func (c *Certificates) Tier() int {
 }
 
 func (c *Certificates) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, client.Discovery())
+}
+
+func (c *Certificates) RunDynamic(ctx context.Context, client kubernetes.Interface, dynamicClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, discoveryClient)
+}
+
+func (c *Certificates) run(ctx context.Context, client kubernetes.Interface, discoveryClient discovery.DiscoveryInterface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
 		Tier:		c.Tier(),
@@ -90,7 +100,8 @@ func (c *Certificates) Run(ctx context.Context, client kubernetes.Interface) (*p
 						fmt.Sprintf("Requestor: %s", csr.Spec.Username),
 						fmt.Sprintf("Signer: %s", csr.Spec.SignerName),
 					},
-					Remediation:	"Review and approve/deny the CSR: kubectl certificate approve/deny " + csr.Name,
+					Remediation:		"Review the CSR and approve or deny it",
+					RemediationCommands:	[]string{"kubectl certificate approve " + csr.Name, "kubectl certificate deny " + csr.Name},
 				})
 			}
 		}
@@ -100,7 +111,7 @@ func (c *Certificates) Run(ctx context.Context, client kubernetes.Interface) (*p
 		}
 	}
 
-	serverVersion, err := client.Discovery().ServerVersion()
+	serverVersion, err := discoveryClient.ServerVersion()
 	if err != nil {
 		result.Results = append(result.Results, probe.Result{
 			CheckName:	c.Name(),
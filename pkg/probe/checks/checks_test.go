@@ -2,17 +2,38 @@ package checks
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func int32Ptr(i int32) *int32 { return &i }
@@ -62,6 +83,86 @@ func TestNodeStatusNotReady(t *testing.T) {
 	}
 }
 
+func TestNodeStatusCustomProblemCondition(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				{Type: "KernelDeadlock", Status: corev1.ConditionFalse, Reason: "KernelHasNoDeadlock"},
+				{Type: "NetworkingReady", Status: corev1.ConditionTrue},
+				{Type: "ContainerRuntimeUnhealthy", Status: corev1.ConditionTrue, Reason: "ContainerdUnhealthy", Message: "containerd is not responding"},
+			},
+		},
+	})
+
+	result, err := NewNodeStatus().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatalf("custom problem condition should warn, got %+v", result.Results)
+	}
+
+	issue := result.Results[0]
+	if issue.Message != "Node node1 reports condition ContainerRuntimeUnhealthy" {
+		t.Errorf("unexpected message: %s", issue.Message)
+	}
+	if !containsString(issue.Details, "Reason: ContainerdUnhealthy") {
+		t.Errorf("details should include the reason, got %v", issue.Details)
+	}
+	if len(result.Results) != 2 {
+		t.Errorf("only the True problem condition should be reported, got %+v", result.Results)
+	}
+}
+
+func TestNodeStatusStaleHeartbeat(t *testing.T) {
+	check := NewNodeStatus()
+	cfg := config.DefaultConfig()
+	cfg.Thresholds.NodeHeartbeatStale = 120
+	check.Configure(cfg)
+
+	client := fake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastHeartbeatTime: metav1.NewTime(time.Now().Add(-30 * time.Second))},
+				},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node2"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastHeartbeatTime: metav1.NewTime(time.Now().Add(-10 * time.Minute))},
+				},
+			},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatalf("stale heartbeat should warn, got %+v", result.Results)
+	}
+
+	stale := 0
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "last heartbeat") {
+			stale++
+			if !strings.HasPrefix(r.Message, "Node node2 reports Ready") {
+				t.Errorf("unexpected stale node: %s", r.Message)
+			}
+		}
+	}
+	if stale != 1 {
+		t.Errorf("expected exactly one stale heartbeat warning, got %d", stale)
+	}
+}
+
 func TestControlPlane(t *testing.T) {
 	check := NewControlPlane()
 	if check.Name() != "control-plane" {
@@ -81,175 +182,249 @@ func TestControlPlane(t *testing.T) {
 	}
 }
 
-func TestCriticalPods(t *testing.T) {
-	check := NewCriticalPods()
-	if check.Name() != "critical-pods" {
-		t.Errorf("unexpected name: %s", check.Name())
+func TestControlPlaneEtcdSaturation(t *testing.T) {
+	etcd := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-master", Namespace: "kube-system"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "etcd",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("1"),
+						corev1.ResourceMemory: resource.MustParse("2Gi"),
+					},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "etcd", Ready: true}},
+		},
 	}
+	client := fake.NewSimpleClientset(etcd)
 
-	client := fake.NewSimpleClientset(&corev1.Pod{
-		ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"},
-		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		podMetricsGVR: "PodMetricsList",
 	})
+	metrics := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata":   map[string]interface{}{"name": "etcd-master", "namespace": "kube-system"},
+		"containers": []interface{}{
+			map[string]interface{}{
+				"name":  "etcd",
+				"usage": map[string]interface{}{"cpu": "200m", "memory": "1945Mi"},
+			},
+		},
+	}}
+	if _, err := dynamicClient.Resource(podMetricsGVR).Namespace("kube-system").Create(context.Background(), metrics, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
 
-	result, err := check.Run(context.Background(), client)
+	result, err := NewControlPlane().RunDynamic(context.Background(), client, dynamicClient, nil)
 	if err != nil {
-		t.Fatalf("Run failed: %v", err)
-	}
-	if result == nil {
-		t.Fatal("result should not be nil")
+		t.Fatalf("RunDynamic failed: %v", err)
+	}
+
+	var memoryWarning, cpuWarning bool
+	for _, r := range result.Results {
+		if r.Severity == probe.SeverityWarning && strings.Contains(r.Message, "etcd pod etcd-master") {
+			if strings.Contains(r.Message, "memory limit") {
+				memoryWarning = true
+			}
+			if strings.Contains(r.Message, "cpu limit") {
+				cpuWarning = true
+			}
+		}
+		if r.Message == "Control plane components are healthy" {
+			t.Error("saturated control plane should not be reported healthy")
+		}
 	}
-}
-
-func TestCertificates(t *testing.T) {
-	check := NewCertificates()
-	if check.Name() != "certificates" {
-		t.Errorf("unexpected name: %s", check.Name())
+	if !memoryWarning {
+		t.Errorf("expected etcd memory saturation warning, got %+v", result.Results)
 	}
-	if check.Tier() != 1 {
-		t.Errorf("unexpected tier: %d", check.Tier())
+	if cpuWarning {
+		t.Error("etcd CPU usage at 20% should not be reported as saturated")
 	}
+}
 
+func TestControlPlaneSaturationWithoutMetrics(t *testing.T) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		podMetricsGVR: "PodMetricsList",
+	})
+	dynamicClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(podMetricsGVR.GroupResource(), "")
+	})
 	client := fake.NewSimpleClientset()
-	result, err := check.Run(context.Background(), client)
+
+	result, err := NewControlPlane().RunDynamic(context.Background(), client, dynamicClient, nil)
 	if err != nil {
-		t.Fatalf("Run failed: %v", err)
+		t.Fatalf("RunDynamic failed: %v", err)
 	}
-	if result == nil {
-		t.Fatal("result should not be nil")
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "limit") {
+			t.Errorf("unexpected saturation result without metrics: %s", r.Message)
+		}
 	}
 }
 
-func TestPodStatus(t *testing.T) {
-	check := NewPodStatus()
-	if check.Name() != "pod-status" {
+func TestNetworkingInfraIncompleteKubeProxyRollout(t *testing.T) {
+	check := NewNetworkingInfra()
+	if check.Name() != "networking-infra" {
 		t.Errorf("unexpected name: %s", check.Name())
 	}
-	if check.Tier() != 2 {
+	if check.Tier() != 1 {
 		t.Errorf("unexpected tier: %d", check.Tier())
 	}
 
 	client := fake.NewSimpleClientset(
-		&corev1.Pod{
-			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
-			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-proxy", Namespace: "kube-system"},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 2, NumberAvailable: 2, NumberUnavailable: 1, UpdatedNumberScheduled: 2},
 		},
-		&corev1.Pod{
-			ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "default"},
-			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "calico-node", Namespace: "calico-system"},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3},
 		},
 	)
 
+	cfg := config.DefaultConfig()
+	cfg.Networking.CNIDaemonSets = []string{"calico-system/calico-node"}
+	check.Configure(cfg)
+
 	result, err := check.Run(context.Background(), client)
 	if err != nil {
 		t.Fatalf("Run failed: %v", err)
 	}
-	if len(result.Results) == 0 {
-		t.Error("expected at least one result")
+
+	if result.MaxSeverity() != probe.SeverityCritical {
+		t.Errorf("expected critical severity, got %s", result.MaxSeverity())
+	}
+	var kubeProxy, cni bool
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "kube-system/kube-proxy has 2/3 pods ready") {
+			kubeProxy = true
+		}
+		if strings.Contains(r.Message, "calico-node") || strings.Contains(r.Message, "No CNI DaemonSet") {
+			cni = true
+		}
+	}
+	if !kubeProxy {
+		t.Errorf("expected incomplete kube-proxy rollout to be reported, got %+v", result.Results)
+	}
+	if cni {
+		t.Errorf("healthy configured CNI DaemonSet should not be reported, got %+v", result.Results)
 	}
 }
 
-func TestDeploymentStatus(t *testing.T) {
-	check := NewDeploymentStatus()
-	if check.Name() != "deployment-status" {
+func TestCriticalPods(t *testing.T) {
+	check := NewCriticalPods()
+	if check.Name() != "critical-pods" {
 		t.Errorf("unexpected name: %s", check.Name())
 	}
-	if check.Tier() != 2 {
-		t.Errorf("unexpected tier: %d", check.Tier())
-	}
 
-	client := fake.NewSimpleClientset(&appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
-		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
-		Status:     appsv1.DeploymentStatus{AvailableReplicas: 3, ReadyReplicas: 3, UpdatedReplicas: 3},
+	client := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
 	})
 
 	result, err := check.Run(context.Background(), client)
 	if err != nil {
 		t.Fatalf("Run failed: %v", err)
 	}
-	if result.MaxSeverity() != probe.SeverityOK {
-		t.Error("healthy deployment should be OK")
+	if result == nil {
+		t.Fatal("result should not be nil")
 	}
 }
 
-func TestDeploymentStatusUnhealthy(t *testing.T) {
-	check := NewDeploymentStatus()
-	client := fake.NewSimpleClientset(&appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
-		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
-		Status:     appsv1.DeploymentStatus{AvailableReplicas: 0, ReadyReplicas: 0},
-	})
+func TestCustomSystemNamespaces(t *testing.T) {
+	privileged := true
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "etcd-master-0", Namespace: "platform-system"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:         "etcd",
+					RestartCount: 9,
+					State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-agent", Namespace: "platform-system"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "agent", SecurityContext: &corev1.SecurityContext{Privileged: &privileged}}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "platform-dns"}},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "platform-dns"},
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.10"}}}},
+		},
+	)
 
-	result, _ := check.Run(context.Background(), client)
-	if result.MaxSeverity() == probe.SeverityOK {
-		t.Error("unhealthy deployment should not be OK")
-	}
-}
+	cfg := config.DefaultConfig()
+	cfg.SystemNamespaces = []string{"platform-system", "platform-dns"}
 
-func TestPVCStatus(t *testing.T) {
-	check := NewPVCStatus()
-	if check.Name() != "pvc-status" {
-		t.Errorf("unexpected name: %s", check.Name())
+	criticalPods := NewCriticalPods()
+	criticalPods.Configure(cfg)
+	result, err := criticalPods.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
 	}
-	if check.Tier() != 2 {
-		t.Errorf("unexpected tier: %d", check.Tier())
+	if result.MaxSeverity() != probe.SeverityCritical {
+		t.Errorf("crash-looping etcd in a custom system namespace should be critical, got %+v", result.Results)
+	}
+	if cmds := result.Results[0].RemediationCommands; len(cmds) != 1 || cmds[0] != "kubectl logs -n platform-system etcd-master-0 --previous" {
+		t.Errorf("remediation should target the pod's namespace, got %v", cmds)
 	}
 
-	client := fake.NewSimpleClientset(&corev1.PersistentVolumeClaim{
-		ObjectMeta: metav1.ObjectMeta{Name: "pvc1", Namespace: "default"},
-		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
-	})
-
-	result, err := check.Run(context.Background(), client)
+	podSecurity := NewPodSecurity()
+	podSecurity.Configure(cfg)
+	result, err = podSecurity.Run(context.Background(), client)
 	if err != nil {
 		t.Fatalf("Run failed: %v", err)
 	}
-	if result.MaxSeverity() != probe.SeverityOK {
-		t.Error("bound PVC should be OK")
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "is privileged") {
+			t.Errorf("privileged pods in system namespaces should not be reported individually, got %q", r.Message)
+		}
 	}
-}
 
-func TestJobFailures(t *testing.T) {
-	check := NewJobFailures()
-	if check.Name() != "job-failures" {
-		t.Errorf("unexpected name: %s", check.Name())
+	dns := NewDNSResolution()
+	dns.Configure(cfg)
+	result, err = dns.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
 	}
-	if check.Tier() != 2 {
-		t.Errorf("unexpected tier: %d", check.Tier())
+	if result.Results[0].Message != "DNS service coredns has 1 ready endpoints" {
+		t.Errorf("DNS service should be found in a custom system namespace, got %q", result.Results[0].Message)
 	}
 
-	client := fake.NewSimpleClientset(&batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "default"},
-		Status:     batchv1.JobStatus{Succeeded: 1},
-	})
-
-	result, err := check.Run(context.Background(), client)
+	result, err = NewPodSecurity().Run(context.Background(), client)
 	if err != nil {
 		t.Fatalf("Run failed: %v", err)
 	}
-	if result == nil {
-		t.Fatal("result should not be nil")
+	if result.MaxSeverity() != probe.SeverityWarning || !strings.Contains(result.Results[0].Message, "node-agent") {
+		t.Errorf("without configuration only kube-system should be exempt, got %+v", result.Results)
 	}
 }
 
-func TestResourceRequests(t *testing.T) {
-	check := NewResourceRequests()
-	if check.Name() != "resource-requests" {
+func TestCertificates(t *testing.T) {
+	check := NewCertificates()
+	if check.Name() != "certificates" {
 		t.Errorf("unexpected name: %s", check.Name())
 	}
-	if check.Tier() != 3 {
+	if check.Tier() != 1 {
 		t.Errorf("unexpected tier: %d", check.Tier())
 	}
 
-	client := fake.NewSimpleClientset(&corev1.Pod{
-		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
-		Spec: corev1.PodSpec{
-			Containers: []corev1.Container{{Name: "container1"}},
-		},
-		Status: corev1.PodStatus{Phase: corev1.PodRunning},
-	})
-
+	client := fake.NewSimpleClientset()
 	result, err := check.Run(context.Background(), client)
 	if err != nil {
 		t.Fatalf("Run failed: %v", err)
@@ -259,82 +434,2480 @@ func TestResourceRequests(t *testing.T) {
 	}
 }
 
-func TestNodeCapacity(t *testing.T) {
-	check := NewNodeCapacity()
-	if check.Name() != "node-capacity" {
+func TestAdmissionWebhooksLongTimeoutFailPolicy(t *testing.T) {
+	check := NewAdmissionWebhooks()
+	if check.Name() != "admission-webhooks" {
 		t.Errorf("unexpected name: %s", check.Name())
 	}
-	if check.Tier() != 3 {
+	if check.Tier() != 1 {
 		t.Errorf("unexpected tier: %d", check.Tier())
 	}
 
-	client := fake.NewSimpleClientset(&corev1.Node{
-		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+	fail := admissionregistrationv1.Fail
+	none := admissionregistrationv1.SideEffectClassNone
+	client := fake.NewSimpleClientset(&admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy-engine"},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+			Name:           "validate.policy.example.com",
+			TimeoutSeconds: int32Ptr(30),
+			FailurePolicy:  &fail,
+			SideEffects:    &none,
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"pods"},
+				},
+			}},
+		}},
 	})
 
 	result, err := check.Run(context.Background(), client)
 	if err != nil {
 		t.Fatalf("Run failed: %v", err)
 	}
-	if result == nil {
-		t.Fatal("result should not be nil")
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("long-timeout failing webhook should warn")
 	}
-}
 
-func TestStorageHealth(t *testing.T) {
-	check := NewStorageHealth()
-	if check.Name() != "storage-health" {
-		t.Errorf("unexpected name: %s", check.Name())
+	r := result.Results[0]
+	if !strings.Contains(r.Message, "30s timeout") {
+		t.Errorf("unexpected message: %s", r.Message)
 	}
-	if check.Tier() != 3 {
-		t.Errorf("unexpected tier: %d", check.Tier())
+	if !strings.Contains(strings.Join(r.Details, "\n"), "ValidatingWebhookConfiguration: policy-engine") {
+		t.Errorf("details should include the webhook configuration name: %v", r.Details)
 	}
 
-	client := fake.NewSimpleClientset(&storagev1.StorageClass{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        "standard",
-			Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
-		},
-	})
+	cfg := config.DefaultConfig()
+	cfg.Thresholds.WebhookTimeout = 30
+	check.Configure(cfg)
 
-	result, err := check.Run(context.Background(), client)
+	result, err = check.Run(context.Background(), client)
 	if err != nil {
 		t.Fatalf("Run failed: %v", err)
 	}
-	if result == nil {
-		t.Fatal("result should not be nil")
+	if result.MaxSeverity() != probe.SeverityOK {
+		t.Error("webhook within the configured timeout should be OK")
 	}
 }
 
-func TestQuotaUsage(t *testing.T) {
-	check := NewQuotaUsage()
-	if check.Name() != "quota-usage" {
-		t.Errorf("unexpected name: %s", check.Name())
-	}
-	if check.Tier() != 3 {
-		t.Errorf("unexpected tier: %d", check.Tier())
+func TestAdmissionWebhooksOverlappingMutators(t *testing.T) {
+	none := admissionregistrationv1.SideEffectClassNone
+	ifNeeded := admissionregistrationv1.IfNeededReinvocationPolicy
+	mutator := func(config, name string, reinvocation *admissionregistrationv1.ReinvocationPolicyType, resources ...string) *admissionregistrationv1.MutatingWebhookConfiguration {
+		return &admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: config},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{{
+				Name:               name,
+				SideEffects:        &none,
+				ReinvocationPolicy: reinvocation,
+				Rules: []admissionregistrationv1.RuleWithOperations{{
+					Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   []string{""},
+						APIVersions: []string{"v1"},
+						Resources:   resources,
+					},
+				}},
+			}},
+		}
 	}
 
-	client := fake.NewSimpleClientset()
-	result, err := check.Run(context.Background(), client)
+	client := fake.NewSimpleClientset(
+		mutator("istio-sidecar-injector", "sidecar-injector.istio.io", nil, "pods"),
+		mutator("vault-agent-injector", "vault.hashicorp.com", &ifNeeded, "pods"),
+		mutator("cert-defaults", "defaults.example.com", nil, "configmaps"),
+	)
+
+	result, err := NewAdmissionWebhooks().Run(context.Background(), client)
 	if err != nil {
 		t.Fatalf("Run failed: %v", err)
 	}
-	if result == nil {
-		t.Fatal("result should not be nil")
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("overlapping mutating webhooks should warn")
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected one overlap and a summary, got %+v", result.Results)
 	}
-}
 
-func TestServiceEndpoints(t *testing.T) {
-	check := NewServiceEndpoints()
-	if check.Name() != "service-endpoints" {
-		t.Errorf("unexpected name: %s", check.Name())
+	r := result.Results[0]
+	if r.Message != "2 mutating webhooks modify pods" {
+		t.Errorf("unexpected message: %s", r.Message)
 	}
-	if check.Tier() != 4 {
-		t.Errorf("unexpected tier: %d", check.Tier())
+	details := strings.Join(r.Details, "\n")
+	if !strings.Contains(details, "sidecar-injector.istio.io (MutatingWebhookConfiguration istio-sidecar-injector, reinvocationPolicy Never)") ||
+		!strings.Contains(details, "vault.hashicorp.com (MutatingWebhookConfiguration vault-agent-injector, reinvocationPolicy IfNeeded)") {
+		t.Errorf("details should list both webhooks: %v", r.Details)
 	}
+}
 
-	client := fake.NewSimpleClientset(
+func TestAdmissionWebhooksOverlapHonoursWildcardsOperationsAndSelectors(t *testing.T) {
+	none := admissionregistrationv1.SideEffectClassNone
+	mutator := func(name string, ops []admissionregistrationv1.OperationType, groups, resources []string, objectSelector *metav1.LabelSelector) *admissionregistrationv1.MutatingWebhookConfiguration {
+		return &admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{{
+				Name:           name + ".example.com",
+				SideEffects:    &none,
+				ObjectSelector: objectSelector,
+				Rules: []admissionregistrationv1.RuleWithOperations{{
+					Operations: ops,
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   groups,
+						APIVersions: []string{"*"},
+						Resources:   resources,
+					},
+				}},
+			}},
+		}
+	}
+	create := []admissionregistrationv1.OperationType{admissionregistrationv1.Create}
+	update := []admissionregistrationv1.OperationType{admissionregistrationv1.Update}
+	all := []admissionregistrationv1.OperationType{admissionregistrationv1.OperationAll}
+	team := func(name string) *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchLabels: map[string]string{"team": name}}
+	}
+
+	tests := []struct {
+		name     string
+		webhooks []runtime.Object
+		want     []string
+	}{
+		{
+			name: "wildcard group and resource",
+			webhooks: []runtime.Object{
+				mutator("policy", all, []string{"*"}, []string{"*"}, nil),
+				mutator("defaults", create, []string{"apps"}, []string{"deployments"}, nil),
+			},
+			want: []string{"2 mutating webhooks modify deployments.apps"},
+		},
+		{
+			name: "disjoint operations",
+			webhooks: []runtime.Object{
+				mutator("on-create", create, []string{""}, []string{"pods"}, nil),
+				mutator("on-update", update, []string{""}, []string{"pods"}, nil),
+			},
+		},
+		{
+			name: "wildcard resource skips subresources",
+			webhooks: []runtime.Object{
+				mutator("resources", create, []string{""}, []string{"*"}, nil),
+				mutator("status", create, []string{""}, []string{"pods/status"}, nil),
+			},
+		},
+		{
+			name: "disjoint object selectors",
+			webhooks: []runtime.Object{
+				mutator("team-a", create, []string{""}, []string{"pods"}, team("a")),
+				mutator("team-b", create, []string{""}, []string{"pods"}, team("b")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewAdmissionWebhooks().Run(context.Background(), fake.NewSimpleClientset(tt.webhooks...))
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var got []string
+			for _, r := range result.Results {
+				if strings.Contains(r.Message, "mutating webhooks modify") {
+					got = append(got, r.Message)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("expected overlaps %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAdmissionWebhooksEmptyCABundle(t *testing.T) {
+	none := admissionregistrationv1.SideEffectClassNone
+	validating := func(name string, annotations map[string]string, caBundle []byte) *admissionregistrationv1.ValidatingWebhookConfiguration {
+		return &admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+				Name:        name + ".example.com",
+				SideEffects: &none,
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service:  &admissionregistrationv1.ServiceReference{Namespace: "webhooks", Name: name},
+					CABundle: caBundle,
+				},
+			}},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		validating("broken", nil, nil),
+		validating("injected", map[string]string{"cert-manager.io/inject-ca-from": "webhooks/serving-cert"}, nil),
+		validating("bundled", nil, []byte("-----BEGIN CERTIFICATE-----")),
+	)
+
+	result, err := NewAdmissionWebhooks().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected one empty caBundle warning and a summary, got %+v", result.Results)
+	}
+
+	r := result.Results[0]
+	if r.Message != "Webhook broken.example.com has an empty caBundle" {
+		t.Errorf("unexpected message: %s", r.Message)
+	}
+	if !strings.Contains(strings.Join(r.Details, "\n"), "Service: webhooks/broken") {
+		t.Errorf("details should include the webhook service: %v", r.Details)
+	}
+	if result.Results[1].Message != "Admission webhooks: 3 total, 1 misconfigured" {
+		t.Errorf("unexpected summary: %s", result.Results[1].Message)
+	}
+}
+
+func TestPodStatus(t *testing.T) {
+	check := NewPodStatus()
+	if check.Name() != "pod-status" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 2 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Results) == 0 {
+		t.Error("expected at least one result")
+	}
+}
+
+func oomKilledPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Namespace: "app"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "worker",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "worker",
+				RestartCount: 7,
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+				},
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"},
+				},
+			}},
+		},
+	}
+}
+
+func TestPodStatusCrashLoopOOMKilled(t *testing.T) {
+	result, err := NewPodStatus().Run(context.Background(), fake.NewSimpleClientset(oomKilledPod()))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	issue := result.Results[0]
+	if issue.Message != "Pod app/worker-1 is in CrashLoopBackOff (OOMKilled)" {
+		t.Errorf("unexpected message: %s", issue.Message)
+	}
+	if issue.Namespace != "app" {
+		t.Errorf("expected namespace app, got %q", issue.Namespace)
+	}
+	details := strings.Join(issue.Details, "\n")
+	if !strings.Contains(details, "Last exit code: 137") || !strings.Contains(details, "Last termination reason: OOMKilled") {
+		t.Errorf("details should include the last termination, got: %v", issue.Details)
+	}
+	if !strings.Contains(issue.Remediation, "memory limit (currently 256Mi)") {
+		t.Errorf("remediation should call out the memory limit, got %q", issue.Remediation)
+	}
+	if len(issue.RemediationCommands) != 2 || !strings.HasPrefix(issue.RemediationCommands[0], "kubectl top pod -n app worker-1") {
+		t.Errorf("unexpected commands: %v", issue.RemediationCommands)
+	}
+}
+
+func TestPodStatusCrashLoopArchitectureMismatch(t *testing.T) {
+	node := func(name, arch string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelArchStable: arch}}}
+	}
+	crashing := func(name string, nodeSelector map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec: corev1.PodSpec{
+				NodeName:     "arm-1",
+				NodeSelector: nodeSelector,
+				Containers:   []corev1.Container{{Name: "main"}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:                 "main",
+					RestartCount:         7,
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 255, Reason: "Error"}},
+				}},
+			},
+		}
+	}
+
+	execFormat := crashing("exec-format", nil)
+	execFormat.Status.ContainerStatuses[0].LastTerminationState.Terminated.Message = "exec /app/server: exec format error"
+
+	client := fake.NewSimpleClientset(
+		node("arm-1", "arm64"),
+		node("x86-1", "amd64"),
+		crashing("unpinned", nil),
+		crashing("pinned", map[string]string{corev1.LabelArchStable: "arm64"}),
+		crashing("wrong-arch", map[string]string{corev1.LabelArchStable: "amd64"}),
+		execFormat,
+	)
+
+	result, err := NewPodStatus().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	byMessage := make(map[string]probe.Result)
+	for _, r := range result.Results {
+		byMessage[r.Message] = r
+	}
+
+	unpinned := byMessage["Pod app/unpinned is in CrashLoopBackOff"]
+	details := strings.Join(unpinned.Details, "\n")
+	if !strings.Contains(details, "Node: arm-1 (arch: arm64)") {
+		t.Errorf("details should include the node architecture, got: %v", unpinned.Details)
+	}
+	if strings.Contains(details, "Possible architecture mismatch") || strings.Contains(unpinned.Remediation, "no arm64 build") {
+		t.Errorf("an ordinary crash loop should not be labelled an architecture mismatch, got: %v / %q", unpinned.Details, unpinned.Remediation)
+	}
+	if !strings.Contains(details, "The cluster mixes 2 architectures") {
+		t.Errorf("details should mention the mixed architectures, got: %v", unpinned.Details)
+	}
+
+	for _, name := range []string{"exec-format", "wrong-arch"} {
+		r := byMessage["Pod app/"+name+" is in CrashLoopBackOff"]
+		if !strings.Contains(strings.Join(r.Details, "\n"), "Possible architecture mismatch") {
+			t.Errorf("%s should suggest an architecture mismatch, got: %v", name, r.Details)
+		}
+		if !strings.Contains(r.Remediation, "no arm64 build") {
+			t.Errorf("%s: unexpected remediation: %q", name, r.Remediation)
+		}
+	}
+
+	pinned := byMessage["Pod app/pinned is in CrashLoopBackOff"]
+	if !strings.Contains(strings.Join(pinned.Details, "\n"), "Node: arm-1 (arch: arm64)") {
+		t.Errorf("details should include the node architecture, got: %v", pinned.Details)
+	}
+	if strings.Contains(strings.Join(pinned.Details, "\n"), "architecture mismatch") {
+		t.Errorf("pod pinned to the node's architecture should not suggest a mismatch, got: %v", pinned.Details)
+	}
+}
+
+func TestStalledResourcesCrashLoopOOMKilled(t *testing.T) {
+	result, err := NewStalledResources().Run(context.Background(), fake.NewSimpleClientset(oomKilledPod()))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	issue := result.Results[0]
+	if issue.Message != "Pod app/worker-1 container worker in CrashLoopBackOff" {
+		t.Fatalf("unexpected message: %s", issue.Message)
+	}
+	if !strings.Contains(strings.Join(issue.Details, "\n"), "Last termination reason: OOMKilled") {
+		t.Errorf("details should include the termination reason, got: %v", issue.Details)
+	}
+	if !strings.Contains(issue.Remediation, "resources.limits.memory") {
+		t.Errorf("remediation should suggest raising the memory limit, got %q", issue.Remediation)
+	}
+}
+
+func TestPodStatusAutoscalerScaleUpBackoff(t *testing.T) {
+	pending := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "app"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  "Unschedulable",
+				Message: "0/3 nodes are available: 3 Insufficient cpu.",
+			}},
+		},
+	}
+	status := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-autoscaler-status", Namespace: "kube-system"},
+		Data: map[string]string{
+			"status": "Cluster-autoscaler status at 2026-10-16 10:00:00 +0000 UTC:\n" +
+				"Cluster-wide:\n" +
+				"  Health:      Healthy (ready=3 unready=0 notStarted=0 registered=3)\n" +
+				"  ScaleUp:     Backoff (ready=3 registered=3)\n" +
+				"               LastProbeTime:      2026-10-16 10:00:00 +0000 UTC\n",
+		},
+	}
+
+	result, err := NewPodStatus().Run(context.Background(), fake.NewSimpleClientset(pending))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if strings.Contains(strings.Join(result.Results[0].Details, "\n"), "autoscaler") {
+		t.Error("autoscaler context should be skipped when the status ConfigMap is absent")
+	}
+
+	result, err = NewPodStatus().Run(context.Background(), fake.NewSimpleClientset(pending, status))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	issue := result.Results[0]
+	if issue.Message != "Pod app/web-1 is unschedulable" {
+		t.Fatalf("unexpected message: %s", issue.Message)
+	}
+	if !strings.Contains(strings.Join(issue.Details, "\n"), "Cluster autoscaler scale-up: Backoff") {
+		t.Errorf("details should report the autoscaler scale-up state, got: %v", issue.Details)
+	}
+	if !strings.Contains(issue.Remediation, "failing to add nodes") {
+		t.Errorf("remediation should point at the failing autoscaler, got %q", issue.Remediation)
+	}
+}
+
+func TestParseAutoscalerStatusYAML(t *testing.T) {
+	status := parseAutoscalerStatus("clusterWide:\n  health:\n    status: Healthy\n  scaleUp:\n    status: NoActivity\n")
+	if status.scaleUpFailing || status.unhealthy {
+		t.Error("idle autoscaler should not be reported as failing")
+	}
+	if len(status.scaleUp) != 1 || status.scaleUp[0] != "NoActivity" {
+		t.Errorf("unexpected scale-up states: %v", status.scaleUp)
+	}
+}
+
+func TestDeploymentStatus(t *testing.T) {
+	check := NewDeploymentStatus()
+	if check.Name() != "deployment-status" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 2 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 3, ReadyReplicas: 3, UpdatedReplicas: 3},
+	})
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityOK {
+		t.Error("healthy deployment should be OK")
+	}
+}
+
+func TestDeploymentStatusUnhealthy(t *testing.T) {
+	check := NewDeploymentStatus()
+	client := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 0, ReadyReplicas: 0},
+	})
+
+	result, _ := check.Run(context.Background(), client)
+	if result.MaxSeverity() == probe.SeverityOK {
+		t.Error("unhealthy deployment should not be OK")
+	}
+}
+
+func TestDeploymentStatusRemediationCommands(t *testing.T) {
+	client := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 0, ReadyReplicas: 0},
+	})
+
+	result, err := NewDeploymentStatus().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	issue := result.Results[0]
+	if strings.Contains(issue.Remediation, "kubectl") {
+		t.Errorf("remediation prose should not embed commands, got %q", issue.Remediation)
+	}
+	expected := "kubectl get pods -n default -l app=app"
+	if len(issue.RemediationCommands) != 1 || issue.RemediationCommands[0] != expected {
+		t.Errorf("expected command %q, got %v", expected, issue.RemediationCommands)
+	}
+}
+
+func TestPVCStatus(t *testing.T) {
+	check := NewPVCStatus()
+	if check.Name() != "pvc-status" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 2 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc1", Namespace: "default"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	})
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityOK {
+		t.Error("bound PVC should be OK")
+	}
+}
+
+func TestJobFailures(t *testing.T) {
+	check := NewJobFailures()
+	if check.Name() != "job-failures" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 2 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(&batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "default"},
+		Status:     batchv1.JobStatus{Succeeded: 1},
+	})
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result == nil {
+		t.Fatal("result should not be nil")
+	}
+}
+
+func TestJobFailuresUnboundedRunningJob(t *testing.T) {
+	startTime := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	client := fake.NewSimpleClientset(&batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "runaway", Namespace: "default"},
+		Spec:       batchv1.JobSpec{BackoffLimit: int32Ptr(6)},
+		Status:     batchv1.JobStatus{Active: 1, StartTime: &startTime},
+	})
+
+	check := NewJobFailures()
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityOK {
+		t.Error("job running below the default threshold should be OK")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Thresholds.JobRunningAge = 1
+	check.Configure(cfg)
+
+	result, err = check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Error("job running past the configured threshold should warn")
+	}
+	if !strings.Contains(result.Results[0].Message, "has no deadline") {
+		t.Errorf("a defaulted backoffLimit should not hide a missing deadline, got %q", result.Results[0].Message)
+	}
+
+	deadline := int64(3600)
+	client = fake.NewSimpleClientset(&batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "bounded", Namespace: "default"},
+		Spec:       batchv1.JobSpec{BackoffLimit: int32Ptr(6), ActiveDeadlineSeconds: &deadline},
+		Status:     batchv1.JobStatus{Active: 1, StartTime: &startTime},
+	})
+	result, err = check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if strings.Contains(result.Results[0].Message, "has no deadline") {
+		t.Errorf("a job with activeDeadlineSeconds should not be reported as unbounded, got %q", result.Results[0].Message)
+	}
+}
+
+func TestSecretReferencesMissingTLSSecret(t *testing.T) {
+	check := NewSecretReferences()
+	if check.Name() != "secret-references" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 2 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{"shop.example.com"}, SecretName: "shop-tls"}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+			Spec: corev1.PodSpec{
+				Containers:       []corev1.Container{{Name: "web"}},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-creds"}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+	metadataClient := secretMetadataClient(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "registry-creds", Namespace: "shop"}})
+
+	result, err := check.RunMetadata(context.Background(), client, metadataClient)
+	if err != nil {
+		t.Fatalf("RunMetadata failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("missing TLS secret should warn")
+	}
+	if result.Results[0].Message != "Secret shop/shop-tls is referenced but does not exist" {
+		t.Errorf("unexpected message: %s", result.Results[0].Message)
+	}
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "registry-creds") {
+			t.Error("existing secret should not be reported")
+		}
+	}
+}
+
+func TestSecretReferencesUnusedDockerConfig(t *testing.T) {
+	metadataClient := secretMetadataClient(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "old-registry", Namespace: "shop"}, Type: corev1.SecretTypeDockerConfigJson},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "registry-creds", Namespace: "shop"}, Type: corev1.SecretTypeDockerConfigJson},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "sa-registry", Namespace: "shop"}, Type: corev1.SecretTypeDockerConfigJson},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "shop"}, Type: corev1.SecretTypeOpaque},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "system-registry", Namespace: "kube-system"}, Type: corev1.SecretTypeDockerConfigJson},
+	)
+	client := fake.NewSimpleClientset(
+		&corev1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: "builder", Namespace: "shop"},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "sa-registry"}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+			Spec: corev1.PodSpec{
+				Containers:       []corev1.Container{{Name: "web"}},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-creds"}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	result, err := NewSecretReferences().RunMetadata(context.Background(), client, metadataClient)
+	if err != nil {
+		t.Fatalf("RunMetadata failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("unused docker-config secret should warn")
+	}
+
+	var unused []string
+	for _, r := range result.Results {
+		if r.Severity == probe.SeverityWarning && strings.Contains(r.Message, "is not used") {
+			unused = append(unused, r.Message)
+		}
+	}
+	if len(unused) != 1 || unused[0] != "Secret shop/old-registry (kubernetes.io/dockerconfigjson) is not used by any pod or service account" {
+		t.Errorf("expected only shop/old-registry to be reported, got %v", unused)
+	}
+}
+
+func TestSecretReferencesSkipsConfiguredSystemNamespaces(t *testing.T) {
+	metadataClient := secretMetadataClient(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "mirror-creds", Namespace: "platform"}, Type: corev1.SecretTypeDockerConfigJson},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "old-registry", Namespace: "shop"}, Type: corev1.SecretTypeDockerConfigJson},
+	)
+
+	check := NewSecretReferences()
+	cfg := config.DefaultConfig()
+	cfg.SystemNamespaces = []string{"kube-system", "platform"}
+	check.Configure(cfg)
+
+	result, err := check.RunMetadata(context.Background(), fake.NewSimpleClientset(), metadataClient)
+	if err != nil {
+		t.Fatalf("RunMetadata failed: %v", err)
+	}
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "platform/mirror-creds") {
+			t.Errorf("credentials in configured system namespaces should be skipped, got %q", r.Message)
+		}
+	}
+	if summary := result.Results[len(result.Results)-1].Message; summary != "Secret references: 0 referenced, 0 missing, 1 unused credentials" {
+		t.Errorf("unexpected summary: %s", summary)
+	}
+}
+
+func secretMetadataClient(secrets ...*corev1.Secret) *metadatafake.FakeMetadataClient {
+	client := metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme())
+	client.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		restrictions := action.(k8stesting.ListAction).GetListRestrictions()
+		list := &metav1.List{}
+		for _, secret := range secrets {
+			if restrictions.Labels.Matches(labels.Set(secret.Labels)) && restrictions.Fields.Matches(fields.Set{"type": string(secret.Type)}) {
+				list.Items = append(list.Items, runtime.RawExtension{Object: &metav1.PartialObjectMetadata{ObjectMeta: secret.ObjectMeta}})
+			}
+		}
+		return true, list, nil
+	})
+	return client
+}
+
+func TestSecretReferencesListsSecretMetadataOnly(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "web"}},
+				Volumes: []corev1.Volume{
+					{Name: "config", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "app-config"}}},
+				},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+	metadataClient := secretMetadataClient(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "shop"}, Type: corev1.SecretTypeOpaque},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "old-registry", Namespace: "shop"}, Type: corev1.SecretTypeDockerConfigJson},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "git-creds", Namespace: "shop"}, Type: corev1.SecretTypeBasicAuth},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "shop"}, Type: corev1.SecretTypeTLS},
+	)
+
+	result, err := NewSecretReferences().RunMetadata(context.Background(), client, metadataClient)
+	if err != nil {
+		t.Fatalf("RunMetadata failed: %v", err)
+	}
+	for _, action := range client.Actions() {
+		if action.GetResource().Resource == "secrets" {
+			t.Errorf("secrets should only be listed through the metadata client, got %v", action)
+		}
+	}
+
+	var unused []string
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "is not used") {
+			unused = append(unused, r.Message)
+		}
+	}
+	want := []string{
+		"Secret shop/git-creds (kubernetes.io/basic-auth) is not used by any pod or service account",
+		"Secret shop/old-registry (kubernetes.io/dockerconfigjson) is not used by any pod or service account",
+	}
+	if strings.Join(unused, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected %v, got %v", want, unused)
+	}
+	if summary := result.Results[len(result.Results)-1].Message; summary != "Secret references: 1 referenced, 0 missing, 2 unused credentials" {
+		t.Errorf("unexpected summary: %s", summary)
+	}
+}
+
+func TestSecretReferencesForbiddenIsInfo(t *testing.T) {
+	metadataClient := metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme())
+	metadataClient.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", fmt.Errorf("denied"))
+	})
+
+	result, err := NewSecretReferences().RunMetadata(context.Background(), fake.NewSimpleClientset(), metadataClient)
+	if err != nil {
+		t.Fatalf("RunMetadata failed: %v", err)
+	}
+	if len(result.Results) != 1 || result.Results[0].Severity != probe.SeverityInfo {
+		t.Errorf("a forbidden secrets list should be reported as INFO, got %+v", result.Results)
+	}
+}
+
+func TestSecretsForbiddenIsInfo(t *testing.T) {
+	metadataClient := metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme())
+	metadataClient.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", fmt.Errorf("denied"))
+	})
+
+	helm, err := NewHelmReleases().RunMetadata(context.Background(), fake.NewSimpleClientset(), metadataClient)
+	if err != nil {
+		t.Fatalf("RunMetadata failed: %v", err)
+	}
+	if helm.MaxSeverity() != probe.SeverityInfo {
+		t.Errorf("helm-releases should report INFO when secrets are forbidden, got %+v", helm.Results)
+	}
+
+	counts, err := NewObjectCounts().RunMetadata(context.Background(), fake.NewSimpleClientset(), metadataClient)
+	if err != nil {
+		t.Fatalf("RunMetadata failed: %v", err)
+	}
+	var info *probe.Result
+	for i, r := range counts.Results {
+		if r.Severity == probe.SeverityInfo {
+			info = &counts.Results[i]
+		}
+	}
+	if info == nil || info.Message != "Secrets not counted: listing secrets is not permitted" {
+		t.Errorf("object-counts should report skipped secrets as INFO, got %+v", counts.Results)
+	}
+}
+
+func TestSecretsWithoutMetadataClientAreSkipped(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db-password", Namespace: "app"}})
+
+	for _, check := range []probe.Check{NewSecretReferences(), NewHelmReleases(), NewObjectCounts()} {
+		result, err := check.Run(context.Background(), client)
+		if err != nil {
+			t.Fatalf("%s: Run failed: %v", check.Name(), err)
+		}
+		found := false
+		for _, r := range result.Results {
+			if r.Severity == probe.SeverityInfo && strings.HasSuffix(r.Message, "secrets are only listed through a metadata client, and none is configured") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected an INFO result for the skipped secrets, got %+v", check.Name(), result.Results)
+		}
+	}
+	for _, action := range client.Actions() {
+		if action.GetResource().Resource == "secrets" {
+			t.Errorf("secrets should never be listed through the typed client, got %v", action)
+		}
+	}
+}
+
+func TestHPAStatusReplicaConflict(t *testing.T) {
+	check := NewHPAStatus()
+	if check.Name() != "hpa-status" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+
+	replicas := int32(1)
+	minReplicas := int32(3)
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		},
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "api", APIVersion: "apps/v1"},
+				MinReplicas:    &minReplicas,
+				MaxReplicas:    10,
+			},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("conflicting replicas should warn")
+	}
+	if result.Results[0].Message != "HPA app/api conflicts with Deployment api replicas" {
+		t.Errorf("unexpected message: %s", result.Results[0].Message)
+	}
+	if result.Results[0].Details[0] != "Spec replicas 1 is below HPA minReplicas 3" {
+		t.Errorf("unexpected detail: %s", result.Results[0].Details[0])
+	}
+}
+
+func TestStalledResourcesIgnoreKinds(t *testing.T) {
+	workflowGVR := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "workflows"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		workflowGVR: "WorkflowList",
+	})
+	workflow := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Workflow",
+		"metadata":   map[string]interface{}{"name": "nightly-etl", "namespace": "data"},
+		"status":     map[string]interface{}{"phase": "Pending"},
+	}}
+	if _, err := dynamicClient.Resource(workflowGVR).Namespace("data").Create(context.Background(), workflow, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(check *StalledResources) *probe.CheckResult {
+		result := &probe.CheckResult{Name: check.Name()}
+		check.checkResourcesForGVR(context.Background(), dynamicClient, workflowGVR, true, "Workflow", result, &stalledStats{})
+		return result
+	}
+
+	if result := run(NewStalledResources()); len(result.Results) != 1 {
+		t.Fatalf("expected the pending workflow to be reported, got %+v", result.Results)
+	}
+
+	check := NewStalledResources()
+	cfg := config.DefaultConfig()
+	cfg.StalledResources.IgnoreKinds = []string{"Workflow.argoproj.io"}
+	check.Configure(cfg)
+	if result := run(check); len(result.Results) != 0 {
+		t.Errorf("ignored kind should produce no findings, got %+v", result.Results)
+	}
+}
+
+func TestStalledCustomResourcesSkipsCoreChecks(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "app"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	})
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"list"}}},
+		},
+		{
+			GroupVersion: "argoproj.io/v1alpha1",
+			APIResources: []metav1.APIResource{{Name: "workflows", Kind: "Workflow", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}}},
+		},
+	}
+
+	workflowGVR := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "workflows"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		workflowGVR: "WorkflowList",
+	})
+	workflow := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Workflow",
+		"metadata":   map[string]interface{}{"name": "nightly-etl", "namespace": "data"},
+		"status":     map[string]interface{}{"phase": "Pending"},
+	}}
+	if _, err := dynamicClient.Resource(workflowGVR).Namespace("data").Create(context.Background(), workflow, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewStalledCustomResources().RunDynamic(context.Background(), client, dynamicClient, client.Discovery())
+	if err != nil {
+		t.Fatalf("RunDynamic failed: %v", err)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected the stalled workflow and a summary, got %+v", result.Results)
+	}
+	if !strings.Contains(result.Results[0].Message, "nightly-etl") {
+		t.Errorf("expected the pending workflow to be reported first, got %q", result.Results[0].Message)
+	}
+	summary := result.Results[1]
+	if summary.Message != "Stalled resources: 1 total" {
+		t.Errorf("core resources should not be scanned, got %q", summary.Message)
+	}
+	if !containsString(summary.Details, "Custom resource types scanned: 1") {
+		t.Errorf("expected scanned type count in details, got %v", summary.Details)
+	}
+
+	full, err := NewStalledResources().RunDynamic(context.Background(), client, dynamicClient, client.Discovery())
+	if err != nil {
+		t.Fatalf("RunDynamic failed: %v", err)
+	}
+	if msg := full.Results[len(full.Results)-1].Message; msg != "Stalled resources: 2 total" {
+		t.Errorf("full scan should include the pending pod, got %q", msg)
+	}
+}
+
+func TestStalledResourcesStuckTerminatingPod(t *testing.T) {
+	deletedAt := metav1.NewTime(time.Now().Add(-time.Hour))
+	grace := int64(30)
+	client := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:                       "worker-1",
+			Namespace:                  "app",
+			DeletionTimestamp:          &deletedAt,
+			DeletionGracePeriodSeconds: &grace,
+			Finalizers:                 []string{"example.com/cleanup"},
+		},
+		Spec:   corev1.PodSpec{NodeName: "node-3"},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	})
+
+	result, err := NewStalledResources().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("stuck terminating pod should warn")
+	}
+
+	issue := result.Results[0]
+	if !strings.HasPrefix(issue.Message, "Pod app/worker-1 stuck terminating for") {
+		t.Errorf("unexpected message: %s", issue.Message)
+	}
+	details := strings.Join(issue.Details, "\n")
+	if !strings.Contains(details, "Node: node-3") || !strings.Contains(details, "Finalizers: example.com/cleanup") {
+		t.Errorf("details should include node and finalizers, got: %v", issue.Details)
+	}
+	if strings.Contains(issue.Remediation, "kubectl") {
+		t.Errorf("remediation prose should not embed commands, got %q", issue.Remediation)
+	}
+	expected := "kubectl get pod -n app worker-1 -o jsonpath='{.metadata.finalizers}'"
+	if len(issue.RemediationCommands) != 1 || issue.RemediationCommands[0] != expected {
+		t.Errorf("expected command %q, got %v", expected, issue.RemediationCommands)
+	}
+}
+
+func TestStalledResourcesLeftoverReplicaSet(t *testing.T) {
+	controller := true
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "app",
+			UID:         "deploy-uid",
+			Generation:  3,
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": "3"},
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 3,
+			Replicas:           3,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:   appsv1.DeploymentProgressing,
+				Status: corev1.ConditionTrue,
+				Reason: "NewReplicaSetAvailable",
+			}},
+		},
+	}
+	owner := []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: "deploy-uid", Controller: &controller}}
+	replicaSet := func(name, revision string, replicas int32) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "app",
+				OwnerReferences: owner,
+				Annotations:     map[string]string{"deployment.kubernetes.io/revision": revision},
+			},
+			Spec:   appsv1.ReplicaSetSpec{Replicas: int32Ptr(replicas)},
+			Status: appsv1.ReplicaSetStatus{Replicas: replicas, ReadyReplicas: replicas},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		deploy,
+		replicaSet("web-v3", "3", 2),
+		replicaSet("web-v2", "2", 1),
+		replicaSet("web-v1", "1", 0),
+	)
+
+	result, err := NewStalledResources().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("leftover replica set holding pods should warn")
+	}
+
+	issue := result.Results[0]
+	if issue.Message != "Deployment app/web has 1 old ReplicaSets still holding pods after rollout completed" {
+		t.Errorf("unexpected message: %s", issue.Message)
+	}
+	if !strings.Contains(strings.Join(issue.Details, "\n"), "app/web-v2 (revision 2): 1 pods") {
+		t.Errorf("details should list the leftover replica set, got: %v", issue.Details)
+	}
+}
+
+func TestNakedPods(t *testing.T) {
+	check := NewNakedPods()
+	if check.Name() != "naked-pods" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 2 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "app"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-7d9f-abcde",
+				Namespace: "app",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-7d9f", Controller: boolPtr(true)},
+				},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "static-web-node1",
+				Namespace:   "app",
+				Annotations: map[string]string{mirrorPodAnnotation: "abc123"},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-proxy-node1", Namespace: "kube-system"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var warning *probe.Result
+	for i, r := range result.Results {
+		if r.Severity == probe.SeverityWarning && strings.Contains(r.Message, "not managed by a controller") {
+			warning = &result.Results[i]
+		}
+	}
+	if warning == nil {
+		t.Fatal("expected warning about naked pods")
+	}
+	if !strings.HasPrefix(warning.Message, "1 pods") {
+		t.Errorf("expected exactly one naked pod, got %q", warning.Message)
+	}
+	if len(warning.Details) != 1 || warning.Details[0] != "app/debug" {
+		t.Errorf("unexpected details: %v", warning.Details)
+	}
+	if warning.Namespace != "app" {
+		t.Errorf("expected namespace app, got %q", warning.Namespace)
+	}
+	if summary := result.Results[len(result.Results)-1]; summary.Severity != probe.SeverityOK {
+		t.Errorf("the summary should not count the issue again, got %v", summary.Severity)
+	}
+}
+
+func TestNakedPodsSkipsConfiguredSystemNamespaces(t *testing.T) {
+	check := NewNakedPods()
+	cfg := config.DefaultConfig()
+	cfg.SystemNamespaces = []string{"kube-system", "platform"}
+	check.Configure(cfg)
+
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "app"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "platform"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "not managed by a controller") && (len(r.Details) != 1 || r.Details[0] != "app/debug") {
+			t.Errorf("pods in configured system namespaces should be skipped, got %v", r.Details)
+		}
+	}
+}
+
+func TestPDBStatusSelectorMatchesNoPods(t *testing.T) {
+	check := NewPDBStatus()
+	if check.Name() != "pdb-status" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 2 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	minAvailable := intstr.FromInt32(1)
+	client := fake.NewSimpleClientset(
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "web"}},
+			},
+			Status: policyv1.PodDisruptionBudgetStatus{ExpectedPods: 1},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "app", Generation: 2},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "worker"}},
+			},
+			Status: policyv1.PodDisruptionBudgetStatus{ObservedGeneration: 1},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "app"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "app", Operator: "Matches"},
+				}},
+			},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatalf("PDB matching no pods should warn, got %+v", result.Results)
+	}
+
+	messages := make(map[string]probe.Result)
+	for _, r := range result.Results {
+		messages[r.Message] = r
+	}
+	issue, ok := messages["PodDisruptionBudget app/api matches no pods"]
+	if !ok {
+		t.Fatalf("expected app/api to be reported, got %+v", result.Results)
+	}
+	if issue.Details[0] != "Selector: app=api" {
+		t.Errorf("details should show the selector, got %v", issue.Details)
+	}
+	if _, ok := messages["PodDisruptionBudget app/worker matches no pods"]; ok {
+		t.Error("a PDB whose status has not been reconciled yet should not be reported")
+	}
+	if _, ok := messages["PodDisruptionBudget app/broken has an invalid selector"]; !ok {
+		t.Errorf("an unparseable selector should be reported, got %+v", result.Results)
+	}
+	if summary := result.Results[len(result.Results)-1].Message; summary != "PodDisruptionBudgets: 4 checked, 1 match no pods, 1 with invalid selectors" {
+		t.Errorf("unexpected summary: %s", summary)
+	}
+}
+
+func TestNodePinnedPods(t *testing.T) {
+	check := NewNodePinnedPods()
+	if check.Name() != "node-pinned-pods" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 2 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	pinnedFields := []metav1.ManagedFieldsEntry{
+		{
+			Manager:    "kubectl-client-side-apply",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:containers":{},"f:nodeName":{}}}`)},
+		},
+	}
+
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pinned", Namespace: "app", ManagedFields: pinnedFields},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-7d9f-abcde",
+				Namespace: "app",
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					{
+						Manager:    "kube-controller-manager",
+						Operation:  metav1.ManagedFieldsOperationUpdate,
+						FieldsType: "FieldsV1",
+						FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:containers":{}}}`)},
+					},
+				},
+			},
+			Spec:   corev1.PodSpec{NodeName: "node-2"},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:          "agent-xyz",
+				Namespace:     "monitoring",
+				ManagedFields: pinnedFields,
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "agent", Controller: boolPtr(true)},
+				},
+			},
+			Spec:   corev1.PodSpec{NodeName: "node-1"},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var warning *probe.Result
+	for i, r := range result.Results {
+		if r.Severity == probe.SeverityWarning && strings.Contains(r.Message, "pinned to a node") {
+			warning = &result.Results[i]
+		}
+	}
+	if warning == nil {
+		t.Fatal("expected warning about pinned pods")
+	}
+	if len(warning.Details) != 1 || warning.Details[0] != "app/pinned (node: node-1)" {
+		t.Errorf("unexpected details: %v", warning.Details)
+	}
+	if warning.Namespace != "app" {
+		t.Errorf("expected namespace app, got %q", warning.Namespace)
+	}
+	if summary := result.Results[len(result.Results)-1]; summary.Severity != probe.SeverityOK {
+		t.Errorf("the summary should not count the issue again, got %v", summary.Severity)
+	}
+}
+
+func TestDanglingOwners(t *testing.T) {
+	check := NewDanglingOwners()
+	if check.Name() != "dangling-owners" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if !check.OptIn() {
+		t.Error("dangling-owners should be opt-in")
+	}
+
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app", UID: "deploy-web"},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-7d9f",
+				Namespace: "app",
+				UID:       "rs-web",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "deploy-web", Controller: boolPtr(true)},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-7d9f-abcde",
+				Namespace: "app",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-7d9f", UID: "rs-web", Controller: boolPtr(true)},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "api-5c4b-fghij",
+				Namespace: "app",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "api-5c4b", UID: "rs-api-deleted", Controller: boolPtr(true)},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "runner-xyz",
+				Namespace: "ci",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "example.com/v1", Kind: "Runner", Name: "runner", UID: "runner-uid", Controller: boolPtr(true)},
+				},
+			},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var warning *probe.Result
+	for i, r := range result.Results {
+		if r.Severity == probe.SeverityWarning && strings.Contains(r.Message, "no longer exist") {
+			warning = &result.Results[i]
+		}
+	}
+	if warning == nil {
+		t.Fatal("expected warning about dangling owner references")
+	}
+	want := "Pod app/api-5c4b-fghij: owner ReplicaSet api-5c4b (uid rs-api-deleted) not found"
+	if len(warning.Details) != 1 || warning.Details[0] != want {
+		t.Errorf("unexpected details: %v", warning.Details)
+	}
+	if warning.Namespace != "app" {
+		t.Errorf("expected namespace app, got %q", warning.Namespace)
+	}
+	if summary := result.Results[len(result.Results)-1]; summary.Severity != probe.SeverityOK {
+		t.Errorf("the summary should not count the issue again, got %v", summary.Severity)
+	}
+}
+
+func TestDuplicateNames(t *testing.T) {
+	check := NewDuplicateNames()
+	if check.Name() != "duplicate-names" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if !check.OptIn() {
+		t.Error("duplicate-names should be opt-in")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.UniqueDeploymentNames = []string{"api-gateway", "billing"}
+	check.Configure(cfg)
+
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api-gateway", Namespace: "prod"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api-gateway", Namespace: "staging"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "billing", Namespace: "prod"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "staging"}},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var warnings []probe.Result
+	for _, r := range result.Results {
+		if r.Severity == probe.SeverityWarning && strings.Contains(r.Message, "expected to be unique") {
+			warnings = append(warnings, r)
+		}
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected one duplicate warning, got %d", len(warnings))
+	}
+	if !strings.Contains(warnings[0].Message, `"api-gateway"`) {
+		t.Errorf("unexpected message: %q", warnings[0].Message)
+	}
+	if len(warnings[0].Details) != 2 || warnings[0].Details[0] != "prod/api-gateway" || warnings[0].Details[1] != "staging/api-gateway" {
+		t.Errorf("unexpected details: %v", warnings[0].Details)
+	}
+}
+
+func TestSingletonReplicasOverScaled(t *testing.T) {
+	check := NewSingletonReplicas()
+	if !check.OptIn() {
+		t.Error("singleton-replicas should be opt-in")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.SingletonDeployments = []string{"operators/cert-rotator", "scheduler-extender", "leader-elected"}
+	check.Configure(cfg)
+
+	singleton := func(namespace, name string, ready int32, args ...string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(ready),
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "manager", Args: args}},
+				}},
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: ready},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		singleton("operators", "cert-rotator", 3),
+		singleton("staging", "cert-rotator", 2),
+		singleton("kube-system", "scheduler-extender", 1),
+		singleton("operators", "leader-elected", 2, "--leader-elect=true"),
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatalf("over-scaled singleton should warn, got %+v", result.Results)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected one warning and a summary, got %+v", result.Results)
+	}
+	if msg := result.Results[0].Message; msg != "Deployment operators/cert-rotator must run as a singleton but has 3 ready replicas" {
+		t.Errorf("unexpected message: %s", msg)
+	}
+	if msg := result.Results[1].Message; msg != "Singleton replicas: 1 of 3 singleton Deployments run more than one ready replica" {
+		t.Errorf("unexpected summary: %s", msg)
+	}
+}
+
+func TestInTreeStorage(t *testing.T) {
+	check := NewInTreeStorage()
+	if check.Name() != "intree-storage" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	legacyClass := "gp2"
+	client := fake.NewSimpleClientset(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "gp2"}, Provisioner: "kubernetes.io/aws-ebs"},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "gp3"}, Provisioner: "ebs.csi.aws.com"},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data-db-0", Namespace: "app"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &legacyClass},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var warnings []probe.Result
+	for _, r := range result.Results {
+		if r.Severity == probe.SeverityWarning && strings.HasPrefix(r.Message, "StorageClass ") {
+			warnings = append(warnings, r)
+		}
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected one in-tree StorageClass warning, got %d", len(warnings))
+	}
+	if !strings.Contains(warnings[0].Message, "gp2") {
+		t.Errorf("unexpected message: %q", warnings[0].Message)
+	}
+	details := strings.Join(warnings[0].Details, "\n")
+	for _, want := range []string{"CSI replacement: ebs.csi.aws.com", "app/data-db-0"} {
+		if !strings.Contains(details, want) {
+			t.Errorf("expected details to contain %q, got %v", want, warnings[0].Details)
+		}
+	}
+	if !strings.Contains(warnings[0].Remediation, "CSI migration") {
+		t.Errorf("expected remediation to mention CSI migration, got %q", warnings[0].Remediation)
+	}
+}
+
+func TestNodeLabels(t *testing.T) {
+	check := NewNodeLabels()
+	if check.Name() != "node-labels" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	labeled := map[string]string{
+		"topology.kubernetes.io/zone":      "eu-west-1a",
+		"topology.kubernetes.io/region":    "eu-west-1",
+		"node.kubernetes.io/instance-type": "m5.large",
+	}
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: labeled}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{
+			"topology.kubernetes.io/region":    "eu-west-1",
+			"node.kubernetes.io/instance-type": "m5.large",
+		}}},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Errorf("expected warning for a node without a zone label, got %v", result.MaxSeverity())
+	}
+
+	var found *probe.Result
+	for i := range result.Results {
+		if strings.Contains(result.Results[i].Message, "missing label") {
+			if found != nil {
+				t.Fatalf("expected one missing label, also got %q", result.Results[i].Message)
+			}
+			found = &result.Results[i]
+		}
+	}
+	if found == nil || found.Message != "1 of 2 nodes are missing label topology.kubernetes.io/zone" {
+		t.Fatalf("expected zone label finding, got %+v", result.Results)
+	}
+	if len(found.Details) != 1 || found.Details[0] != "node-b" {
+		t.Errorf("expected node-b in details, got %v", found.Details)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.RequiredNodeLabels = []string{"node.kubernetes.io/instance-type"}
+	check.Configure(cfg)
+	result, err = check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityOK {
+		t.Errorf("expected OK when only instance-type is required, got %+v", result.Results)
+	}
+}
+
+func TestPodChurn(t *testing.T) {
+	check := NewPodChurn()
+	if check.Name() != "pod-churn" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 2 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+	if !check.OptIn() {
+		t.Error("pod-churn should be opt-in")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Thresholds.PodChurnWindow = 30
+	cfg.Thresholds.PodChurnWarning = 5
+	check.Configure(cfg)
+
+	recent := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	old := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+
+	var objects []runtime.Object
+	for i := 0; i < 8; i++ {
+		objects = append(objects, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("web-%d", i), Namespace: "flappy", CreationTimestamp: recent,
+		}})
+	}
+	for i := 0; i < 8; i++ {
+		objects = append(objects, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("api-%d", i), Namespace: "stable", CreationTimestamp: old,
+		}})
+		objects = append(objects, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("report-%d", i), Namespace: "batch", CreationTimestamp: recent,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Job", Name: fmt.Sprintf("report-%d", i)}},
+		}})
+	}
+	objects = append(objects, &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-abc", Namespace: "flappy", CreationTimestamp: recent,
+	}})
+
+	result, err := check.Run(context.Background(), fake.NewSimpleClientset(objects...))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatalf("expected warning for high churn, got %+v", result.Results)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected one churn finding and a summary, got %+v", result.Results)
+	}
+	if result.Results[0].Message != "Namespace flappy created 8 pods in the last 30 minutes" {
+		t.Errorf("unexpected message: %s", result.Results[0].Message)
+	}
+	if result.Results[0].Details[1] != "ReplicaSets created in the same window: 1" {
+		t.Errorf("unexpected details: %v", result.Results[0].Details)
+	}
+}
+
+func TestTopologySpread(t *testing.T) {
+	check := NewTopologySpread()
+	if check.Name() != "topology-spread" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+	if !check.OptIn() {
+		t.Error("topology-spread should be opt-in")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Thresholds.TopologySpreadMinReplicas = 6
+	check.Configure(cfg)
+
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(10)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(10),
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.ScheduleAnyway,
+					}},
+				}},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "app"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "data"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: int32Ptr(6),
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Affinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+						PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+							Weight:          100,
+							PodAffinityTerm: corev1.PodAffinityTerm{TopologyKey: "kubernetes.io/hostname"},
+						}},
+					}},
+				}},
+			},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatalf("expected warning for a large deployment without spread, got %+v", result.Results)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected one finding and a summary, got %+v", result.Results)
+	}
+	if !strings.HasPrefix(result.Results[0].Message, "Deployment app/web runs 10 replicas") {
+		t.Errorf("unexpected message: %s", result.Results[0].Message)
+	}
+	if want := "Topology spread: 1 of 3 workloads with 6+ replicas have no spread constraints or anti-affinity"; result.Results[1].Message != want {
+		t.Errorf("unexpected summary: %s", result.Results[1].Message)
+	}
+}
+
+func TestResourceRequests(t *testing.T) {
+	check := NewResourceRequests()
+	if check.Name() != "resource-requests" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "container1"}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	})
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result == nil {
+		t.Fatal("result should not be nil")
+	}
+}
+
+func TestResourceRequestsMissingMemoryLimits(t *testing.T) {
+	pod := func(namespace, name string, limits corev1.ResourceList) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+					Limits:   limits,
+				},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	cpuOnly := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}
+	bounded := corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}
+
+	client := fake.NewSimpleClientset(
+		pod("batch", "worker-1", nil),
+		pod("batch", "worker-2", cpuOnly),
+		pod("batch", "worker-3", nil),
+		pod("batch", "worker-4", bounded),
+		pod("web", "frontend-1", bounded),
+		pod("web", "frontend-2", bounded),
+		pod("web", "frontend-3", nil),
+	)
+
+	check := NewResourceRequests()
+	cfg := config.DefaultConfig()
+	cfg.Thresholds.NoMemoryLimitPercent = 60
+	check.Configure(cfg)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("namespace with mostly limitless pods should warn")
+	}
+
+	var flagged []string
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "without a memory limit") {
+			flagged = append(flagged, r.Message)
+		}
+	}
+	if len(flagged) != 1 || flagged[0] != "Namespace batch has 3/4 pods without a memory limit" {
+		t.Errorf("expected only the batch namespace to be flagged, got %v", flagged)
+	}
+}
+
+func TestNodeCapacity(t *testing.T) {
+	check := NewNodeCapacity()
+	if check.Name() != "node-capacity" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+	})
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result == nil {
+		t.Fatal("result should not be nil")
+	}
+}
+
+func TestNodeCapacityHeavilyReserved(t *testing.T) {
+	check := NewNodeCapacity()
+	check.Configure(config.DefaultConfig())
+
+	node := func(name, allocatableCPU, allocatableMemory string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("32Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(allocatableCPU),
+					corev1.ResourceMemory: resource.MustParse(allocatableMemory),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+			},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		node("reserved", "4", "30Gi"),
+		node("normal", "7800m", "30Gi"),
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var warnings []probe.Result
+	for _, r := range result.Results {
+		if r.Severity == probe.SeverityWarning {
+			warnings = append(warnings, r)
+		}
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected one warning for the heavily reserved node, got %+v", result.Results)
+	}
+	if warnings[0].Message != "Node reserved reserves a large share of its capacity for the system" {
+		t.Errorf("unexpected message: %s", warnings[0].Message)
+	}
+	if !strings.Contains(strings.Join(warnings[0].Details, "\n"), "CPU: 4000m of 8000m allocatable (50.0% reserved)") {
+		t.Errorf("details should report the reserved CPU, got: %v", warnings[0].Details)
+	}
+
+	found := false
+	for _, r := range result.Results {
+		if r.Severity == probe.SeverityOK && strings.HasPrefix(r.Message, "Node normal:") {
+			found = true
+			if !strings.HasSuffix(r.Message, "Reserved CPU 2%, Memory 6%") {
+				t.Errorf("summary should include the reserved fractions, got: %s", r.Message)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected a summary for node normal, got %+v", result.Results)
+	}
+
+	result, err = check.Run(context.Background(), fake.NewSimpleClientset(node("exhausted", "0", "30Gi")))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatalf("a node with zero allocatable CPU should warn, got %+v", result.Results)
+	}
+	if !strings.Contains(strings.Join(result.Results[0].Details, "\n"), "(100.0% reserved)") {
+		t.Errorf("zero allocatable should count as fully reserved, got: %v", result.Results[0].Details)
+	}
+}
+
+func TestStorageHealth(t *testing.T) {
+	check := NewStorageHealth()
+	if check.Name() != "storage-health" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(&storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "standard",
+			Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+		},
+	})
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result == nil {
+		t.Fatal("result should not be nil")
+	}
+}
+
+func TestQuotaUsage(t *testing.T) {
+	check := NewQuotaUsage()
+	if check.Name() != "quota-usage" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset()
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result == nil {
+		t.Fatal("result should not be nil")
+	}
+}
+
+func TestQuotaUsageLimitRangeViolation(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "container-limits", Namespace: "team-a"},
+			Spec: corev1.LimitRangeSpec{
+				Limits: []corev1.LimitRangeItem{{
+					Type: corev1.LimitTypeContainer,
+					Max:  corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "big", Namespace: "team-a"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+				},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "small", Namespace: "team-a"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+				},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	result, err := NewQuotaUsage().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("pod above the LimitRange max should warn")
+	}
+
+	var found *probe.Result
+	for i := range result.Results {
+		if result.Results[i].Severity == probe.SeverityWarning {
+			found = &result.Results[i]
+		}
+	}
+	if found == nil || found.Message != "LimitRange team-a/container-limits has 1 existing containers outside its limits" {
+		t.Fatalf("unexpected results: %+v", result.Results)
+	}
+	if len(found.Details) != 1 || found.Details[0] != "big/app: memory limit 2Gi above max 1Gi" {
+		t.Errorf("unexpected details: %v", found.Details)
+	}
+	if found.Namespace != "team-a" {
+		t.Errorf("expected namespace team-a, got %q", found.Namespace)
+	}
+}
+
+func TestPVReclaimPolicyDelete(t *testing.T) {
+	check := NewPVReclaimPolicy()
+	if check.Name() != "pv-reclaim-policy" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+	if !check.OptIn() {
+		t.Error("pv-reclaim-policy should be opt-in")
+	}
+
+	pv := func(name, namespace string, policy corev1.PersistentVolumeReclaimPolicy) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:                      corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+				PersistentVolumeReclaimPolicy: policy,
+				StorageClassName:              "fast",
+				ClaimRef:                      &corev1.ObjectReference{Namespace: namespace, Name: "data-" + name},
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "db", Labels: map[string]string{"data-tier": "stateful"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "cache"}},
+		pv("pv-db", "db", corev1.PersistentVolumeReclaimDelete),
+		pv("pv-retained", "db", corev1.PersistentVolumeReclaimRetain),
+		pv("pv-cache", "cache", corev1.PersistentVolumeReclaimDelete),
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatalf("expected a warning for Delete-policy volumes, got %+v", result.Results)
+	}
+	if result.Results[len(result.Results)-1].Message != "PV reclaim policy: 2 of 3 bound volumes in user namespaces use Delete" {
+		t.Errorf("unexpected summary: %s", result.Results[len(result.Results)-1].Message)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.StatefulNamespaces = "data-tier=stateful"
+	check.Configure(cfg)
+
+	result, err = check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected one warning and a summary, got %+v", result.Results)
+	}
+	if result.Results[0].Message != "PersistentVolume pv-db bound to db/data-pv-db is deleted along with its claim" {
+		t.Errorf("unexpected message: %s", result.Results[0].Message)
+	}
+	if !strings.Contains(strings.Join(result.Results[0].RemediationCommands, "\n"), `"persistentVolumeReclaimPolicy":"Retain"`) {
+		t.Errorf("remediation should patch the PV to Retain, got: %v", result.Results[0].RemediationCommands)
+	}
+}
+
+func TestStatefulSetVolumesOrphanedPVC(t *testing.T) {
+	check := NewStatefulSetVolumes()
+	if check.Name() != "statefulset-volumes" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	claim := func(name, size string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "db"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+				},
+			},
+		}
+	}
+	template := claim("data", "20Gi")
+	template.Namespace = ""
+
+	client := fake.NewSimpleClientset(
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "db"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:             int32Ptr(2),
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{*template},
+			},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "postgres-0", Namespace: "db"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "postgres-1", Namespace: "db"}},
+		claim("data-postgres-0", "20Gi"),
+		claim("data-postgres-1", "10Gi"),
+		claim("data-postgres-2", "20Gi"),
+		claim("data-postgres-replica-0", "20Gi"),
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("orphaned PVC should warn")
+	}
+	if len(result.Results) != 3 {
+		t.Fatalf("expected an orphan, a mismatch and a summary, got %+v", result.Results)
+	}
+
+	var orphan, mismatch bool
+	for _, r := range result.Results {
+		switch r.Message {
+		case "PVC db/data-postgres-2 is left over from StatefulSet postgres scale-down":
+			orphan = true
+		case "PVC db/data-postgres-1 does not match StatefulSet postgres volumeClaimTemplate data":
+			mismatch = len(r.Details) == 1 && r.Details[0] == "Requested size 10Gi is smaller than the template's 20Gi"
+		}
+	}
+	if !orphan {
+		t.Errorf("expected data-postgres-2 to be reported as orphaned, got %+v", result.Results)
+	}
+	if !mismatch {
+		t.Errorf("expected data-postgres-1 to be reported as undersized, got %+v", result.Results)
+	}
+}
+
+func TestStatefulSetVolumesSkipsOrphansWhenPodsSampled(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "db"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(1)},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "postgres-0", Namespace: "db"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "web"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-postgres-1", Namespace: "db"}},
+	)
+
+	engine := probe.NewEngine(false)
+	engine.SetResourceBudget(1)
+	engine.Register(NewStatefulSetVolumes())
+	results, err := engine.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for _, r := range results[0].Results {
+		if strings.Contains(r.Message, "left over") {
+			t.Errorf("orphaned PVCs should not be reported from a sampled pod list, got %q", r.Message)
+		}
+	}
+	if results[0].MaxSeverity() != probe.SeverityOK {
+		t.Errorf("expected OK from a sampled scan, got %+v", results[0].Results)
+	}
+}
+
+func TestObjectCountsNamespaceOverThreshold(t *testing.T) {
+	check := NewObjectCounts()
+	if check.Name() != "object-counts" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset()
+	for i := 0; i < 4; i++ {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("release-v%d", i), Namespace: "helm-apps"}}
+		if _, err := client.CoreV1().ConfigMaps("helm-apps").Create(context.Background(), cm, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.CoreV1().ConfigMaps("default").Create(context.Background(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"},
+	}, metav1.CreateOptions{})
+
+	cfg := config.DefaultConfig()
+	cfg.Thresholds.ConfigMapsPerNamespace = 3
+	check.Configure(cfg)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("namespace over the threshold should warn")
+	}
+	if result.Results[0].Message != "Namespace helm-apps has 4 ConfigMaps" {
+		t.Errorf("unexpected message: %s", result.Results[0].Message)
+	}
+
+	summary := result.Results[len(result.Results)-1]
+	if len(summary.Details) < 2 || summary.Details[0] != "helm-apps: 4 ConfigMaps" {
+		t.Errorf("top namespaces should be ranked in details, got: %v", summary.Details)
+	}
+}
+
+func TestObjectCountsListsMetadataOnly(t *testing.T) {
+	object := func(kind, namespace, name string) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: kind},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		}
+	}
+	scheme := metadatafake.NewTestScheme()
+	scheme.AddKnownTypeWithName(corev1.SchemeGroupVersion.WithKind("ConfigMap"), &metav1.PartialObjectMetadata{})
+	scheme.AddKnownTypeWithName(corev1.SchemeGroupVersion.WithKind("Secret"), &metav1.PartialObjectMetadata{})
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme,
+		object("ConfigMap", "app", "settings"),
+		object("ConfigMap", "app", "flags"),
+		object("Secret", "app", "db-password"),
+	)
+	client := fake.NewSimpleClientset()
+
+	result, err := NewObjectCounts().RunMetadata(context.Background(), client, metadataClient)
+	if err != nil {
+		t.Fatalf("RunMetadata failed: %v", err)
+	}
+	if len(client.Actions()) != 0 {
+		t.Errorf("configmaps and secrets should only be listed through the metadata client, got %v", client.Actions())
+	}
+	summary := result.Results[len(result.Results)-1]
+	if summary.Message != "Namespaced objects: 2 ConfigMaps, 1 Secrets across 1 namespaces" {
+		t.Errorf("unexpected summary: %s", summary.Message)
+	}
+}
+
+func TestHelmReleasesManyRevisions(t *testing.T) {
+	check := NewHelmReleases()
+	if check.Name() != "helm-releases" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	secrets := []*corev1.Secret{{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-password", Namespace: "app"},
+		Type:       corev1.SecretTypeOpaque,
+	}}
+	release := func(name string, revisions int) {
+		for i := 1; i <= revisions; i++ {
+			secrets = append(secrets, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("sh.helm.release.v1.%s.v%d", name, i),
+					Namespace: "app",
+					Labels:    map[string]string{"owner": "helm", "name": name, "version": fmt.Sprint(i)},
+				},
+				Type: helmReleaseSecretType,
+			})
+		}
+	}
+	release("web", 25)
+	release("worker", 3)
+
+	result, err := check.RunMetadata(context.Background(), fake.NewSimpleClientset(), secretMetadataClient(secrets...))
+	if err != nil {
+		t.Fatalf("RunMetadata failed: %v", err)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected one warning and a summary, got %+v", result.Results)
+	}
+	if result.Results[0].Message != "Helm release app/web keeps 25 revisions" {
+		t.Errorf("unexpected message: %s", result.Results[0].Message)
+	}
+	if !strings.Contains(strings.Join(result.Results[0].Details, "\n"), "Helm release secrets in app: 28") {
+		t.Errorf("details should count the namespace's release secrets, got: %v", result.Results[0].Details)
+	}
+	if result.Results[1].Message != "Helm releases: 2 releases with 28 revision secrets across 1 namespaces, 1 above 10 revisions" {
+		t.Errorf("unexpected summary: %s", result.Results[1].Message)
+	}
+}
+
+func TestHelmReleasesListsSecretMetadataOnly(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	metadataClient := secretMetadataClient(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.v1.web.v1", Namespace: "app", Labels: map[string]string{"owner": "helm", "name": "web"}},
+			Type:       helmReleaseSecretType,
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.v1.web.v2", Namespace: "app", Labels: map[string]string{"owner": "helm", "name": "web"}},
+			Type:       helmReleaseSecretType,
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-values", Namespace: "app", Labels: map[string]string{"owner": "helm", "name": "web"}},
+			Type:       corev1.SecretTypeOpaque,
+		},
+	)
+
+	result, err := NewHelmReleases().RunMetadata(context.Background(), client, metadataClient)
+	if err != nil {
+		t.Fatalf("RunMetadata failed: %v", err)
+	}
+	if len(client.Actions()) != 0 {
+		t.Errorf("helm release secrets should only be listed through the metadata client, got %v", client.Actions())
+	}
+	if summary := result.Results[len(result.Results)-1].Message; summary != "Helm releases: 1 releases with 2 revision secrets across 1 namespaces, 0 above 10 revisions" {
+		t.Errorf("unexpected summary: %s", summary)
+	}
+}
+
+func TestRequestBalanceUnevenPod(t *testing.T) {
+	check := NewRequestBalance()
+	if check.Name() != "request-balance" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+	if !check.OptIn() {
+		t.Error("request-balance should be opt-in")
+	}
+	check.Configure(config.DefaultConfig())
+
+	requests := func(cpu, memory string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}}
+	}
+
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "uneven", Namespace: "app"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "app", Resources: requests("4", "2Gi")},
+				{Name: "proxy"},
+				{Name: "logger", Resources: requests("100m", "1Gi")},
+			}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "balanced", Namespace: "app"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "app", Resources: requests("1", "1Gi")},
+				{Name: "proxy", Resources: requests("250m", "256Mi")},
+			}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected one warning and a summary, got %+v", result.Results)
+	}
+	if result.Results[0].Message != "Pod app/uneven has uneven resource requests across its containers" {
+		t.Errorf("unexpected message: %s", result.Results[0].Message)
+	}
+	details := strings.Join(result.Results[0].Details, "\n")
+	for _, want := range []string{
+		"Container proxy requests no cpu while app requests 4",
+		"Container app requests 4 cpu, 40x the 100m requested by logger (threshold: 10x)",
+		"Container proxy requests no memory while app requests 2Gi",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("details should contain %q, got: %v", want, result.Results[0].Details)
+		}
+	}
+	if result.Results[1].Message != "Request balance: 1 of 2 multi-container pods have uneven requests" {
+		t.Errorf("unexpected summary: %s", result.Results[1].Message)
+	}
+}
+
+func TestEventStorm(t *testing.T) {
+	check := NewEventStorm()
+	if check.Name() != "event-storm" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 3 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(
+		&corev1.Event{
+			ObjectMeta:          metav1.ObjectMeta{Name: "web.17a1", Namespace: "shop"},
+			InvolvedObject:      corev1.ObjectReference{Kind: "Service", Namespace: "shop", Name: "web"},
+			Reason:              "SyncLoadBalancerFailed",
+			ReportingController: "service-controller",
+			Count:               4800,
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "api-1.17a2", Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "api-1"},
+			Reason:         "Pulled",
+			Source:         corev1.EventSource{Component: "kubelet"},
+			Count:          3,
+		},
+	)
+
+	cfg := config.DefaultConfig()
+	cfg.Thresholds.EventStormWarning = 1000
+	check.Configure(cfg)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("high-count event should warn")
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected one finding and a summary, got %+v", result.Results)
+	}
+	if result.Results[0].Message != "Service shop/web has 4800 SyncLoadBalancerFailed events" {
+		t.Errorf("unexpected message: %s", result.Results[0].Message)
+	}
+	if result.Results[0].Namespace != "shop" {
+		t.Errorf("expected namespace shop, got %q", result.Results[0].Namespace)
+	}
+	if len(result.Results[0].Details) != 2 || result.Results[0].Details[1] != "Reported by service-controller: 4800 events" {
+		t.Errorf("expected the reporting controller in details, got %v", result.Results[0].Details)
+	}
+}
+
+func TestEventStormKeysByInvolvedObject(t *testing.T) {
+	var objects []runtime.Object
+	for i := 0; i < 20; i++ {
+		objects = append(objects, &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d.17a1", i), Namespace: "batch"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "batch", Name: fmt.Sprintf("pod-%d", i)},
+			Reason:         "Scheduled",
+			Source:         corev1.EventSource{Component: "default-scheduler"},
+			Count:          100,
+		})
+	}
+
+	check := NewEventStorm()
+	cfg := config.DefaultConfig()
+	cfg.Thresholds.EventStormWarning = 1000
+	check.Configure(cfg)
+
+	result, err := check.Run(context.Background(), fake.NewSimpleClientset(objects...))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityOK {
+		t.Errorf("routine events spread across many objects should not warn, got %+v", result.Results)
+	}
+	if result.Results[0].Message != "Events: 2000 occurrences across 20 object and reason pairs, 0 above 1000" {
+		t.Errorf("unexpected summary: %s", result.Results[0].Message)
+	}
+}
+
+func TestServiceEndpoints(t *testing.T) {
+	check := NewServiceEndpoints()
+	if check.Name() != "service-endpoints" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 4 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(
 		&corev1.Service{
 			ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "default"},
 			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
@@ -354,6 +2927,210 @@ func TestServiceEndpoints(t *testing.T) {
 	}
 }
 
+func TestServiceEndpointsDeprecatedTopologyAnnotation(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "api",
+				Namespace:   "app",
+				Annotations: map[string]string{deprecatedTopologyHintsAnnotation: "auto"},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"},
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.5"}}}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Namespace:   "app",
+				Annotations: map[string]string{"service.kubernetes.io/topology-mode": "Auto"},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.6"}}}},
+		},
+	)
+
+	result, err := NewServiceEndpoints().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var warnings []probe.Result
+	for _, r := range result.Results {
+		if r.Severity == probe.SeverityWarning && strings.Contains(r.Message, "deprecated topology annotation") {
+			warnings = append(warnings, r)
+		}
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected one deprecated topology warning, got %d", len(warnings))
+	}
+	if !strings.HasPrefix(warnings[0].Message, "Service app/api ") {
+		t.Errorf("unexpected message: %q", warnings[0].Message)
+	}
+	if !strings.Contains(warnings[0].Remediation, "trafficDistribution") {
+		t.Errorf("expected remediation to mention trafficDistribution, got %q", warnings[0].Remediation)
+	}
+}
+
+func TestServiceEndpointsTargetPortMismatch(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"},
+			Spec: corev1.ServiceSpec{
+				Type:     corev1.ServiceTypeClusterIP,
+				Selector: map[string]string{"app": "api"},
+				Ports:    []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(8080)}},
+			},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{
+					IP:        "10.0.0.5",
+					TargetRef: &corev1.ObjectReference{Kind: "Pod", Namespace: "app", Name: "api-1"},
+				}},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "app", Labels: map[string]string{"app": "api"}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:  "api",
+					Ports: []corev1.ContainerPort{{ContainerPort: 9090}},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	result, err := NewServiceEndpoints().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("target port mismatch should warn")
+	}
+
+	issue := result.Results[0]
+	if issue.Message != "Service app/api targetPort 8080 does not match any container port" {
+		t.Errorf("unexpected message: %s", issue.Message)
+	}
+	if !strings.Contains(strings.Join(issue.Details, "\n"), "Container ports: 9090/TCP") {
+		t.Errorf("details should list the container ports, got: %v", issue.Details)
+	}
+}
+
+func TestServiceEndpointsDualStackMissingFamily(t *testing.T) {
+	dualStack := corev1.ServiceSpec{
+		Type:       corev1.ServiceTypeClusterIP,
+		IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+	}
+	ready := []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.5"}}}
+	client := fake.NewSimpleClientset(
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"}, Spec: dualStack},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"},
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.5"}}}},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta:  metav1.ObjectMeta{Name: "api-v4", Namespace: "app", Labels: map[string]string{discoveryv1.LabelServiceName: "api"}},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints:   ready,
+		},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"}, Spec: dualStack},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.6"}}}},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta:  metav1.ObjectMeta{Name: "web-v4", Namespace: "app", Labels: map[string]string{discoveryv1.LabelServiceName: "web"}},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.6"}}},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta:  metav1.ObjectMeta{Name: "web-v6", Namespace: "app", Labels: map[string]string{discoveryv1.LabelServiceName: "web"}},
+			AddressType: discoveryv1.AddressTypeIPv6,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"fd00::6"}}},
+		},
+	)
+
+	result, err := NewServiceEndpoints().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var warnings []string
+	for _, r := range result.Results {
+		if r.Severity == probe.SeverityWarning && strings.Contains(r.Message, "endpoints") && r.Namespace != "" {
+			warnings = append(warnings, r.Message)
+		}
+	}
+	if len(warnings) != 1 || warnings[0] != "Service app/api has no IPv6 endpoints" {
+		t.Errorf("expected only app/api to be missing IPv6 endpoints, got %v", warnings)
+	}
+}
+
+func TestServiceEndpointsLocalTrafficPolicyUnevenPlacement(t *testing.T) {
+	node := func(name string, labels map[string]string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			}},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		node("node-1", nil),
+		node("node-2", nil),
+		node("node-3", map[string]string{excludeFromLoadBalancersLabel: ""}),
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "edge"},
+			Spec: corev1.ServiceSpec{
+				Type:                  corev1.ServiceTypeLoadBalancer,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+				HealthCheckNodePort:   32100,
+				Selector:              map[string]string{"app": "ingress"},
+				Ports:                 []corev1.ServicePort{{Port: 443}},
+			},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "edge"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{
+					IP:        "10.0.0.5",
+					TargetRef: &corev1.ObjectReference{Kind: "Pod", Namespace: "edge", Name: "ingress-1"},
+				}},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress-1", Namespace: "edge", Labels: map[string]string{"app": "ingress"}},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	result, err := NewServiceEndpoints().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("local traffic policy with uneven placement should warn")
+	}
+
+	issue := result.Results[0]
+	if issue.Message != "Service edge/ingress uses externalTrafficPolicy Local but 1 of 2 nodes have no local endpoint" {
+		t.Errorf("unexpected message: %s", issue.Message)
+	}
+	if issue.Details[0] != "Nodes without a local endpoint: node-2" {
+		t.Errorf("expected node-2 to be listed, got %v", issue.Details)
+	}
+}
+
 func TestIngressStatus(t *testing.T) {
 	check := NewIngressStatus()
 	if check.Name() != "ingress-status" {
@@ -367,12 +3144,90 @@ func TestIngressStatus(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{Name: "ingress1", Namespace: "default"},
 	})
 
-	result, err := check.Run(context.Background(), client)
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result == nil {
+		t.Fatal("result should not be nil")
+	}
+}
+
+func TestIngressStatusAmbiguousClasses(t *testing.T) {
+	nginx := "nginx"
+	client := fake.NewSimpleClientset(
+		&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}},
+		&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "traefik"}},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "classless", Namespace: "app"},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "assigned", Namespace: "app"},
+			Spec:       networkingv1.IngressSpec{IngressClassName: &nginx},
+		},
+	)
+
+	result, err := NewIngressStatus().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var found *probe.Result
+	for i, r := range result.Results {
+		if strings.Contains(r.Message, "have no class") {
+			found = &result.Results[i]
+		}
+	}
+	if found == nil {
+		t.Fatal("expected warning about class-less ingresses with multiple classes")
+	}
+	if found.Severity != probe.SeverityWarning {
+		t.Errorf("expected warning, got %s", found.Severity)
+	}
+	details := strings.Join(found.Details, "\n")
+	if !strings.Contains(details, "nginx, traefik") {
+		t.Errorf("expected available classes in details, got %v", found.Details)
+	}
+	if !strings.Contains(details, "Unassigned: app/classless") || strings.Contains(details, "app/assigned") {
+		t.Errorf("expected only the class-less ingress listed, got %v", found.Details)
+	}
+}
+
+func TestIngressStatusImplementationSpecificPathType(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	implementationSpecific := networkingv1.PathTypeImplementationSpecific
+	backend := networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+		Name: "web",
+		Port: networkingv1.ServiceBackendPort{Number: 80},
+	}}
+	client := fake.NewSimpleClientset(&networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "app"},
+		Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{
+			Host: "app.example.com",
+			IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{
+					{Path: "/api", PathType: &implementationSpecific, Backend: backend},
+					{Path: "/", PathType: &prefix, Backend: backend},
+				},
+			}},
+		}}},
+		Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+			Ingress: []networkingv1.IngressLoadBalancerIngress{{IP: "203.0.113.10"}},
+		}},
+	})
+
+	result, err := NewIngressStatus().Run(context.Background(), client)
 	if err != nil {
 		t.Fatalf("Run failed: %v", err)
 	}
-	if result == nil {
-		t.Fatal("result should not be nil")
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatalf("expected a warning for an ImplementationSpecific path, got %+v", result.Results)
+	}
+	if result.Results[0].Message != "Ingress app/legacy has paths with pathType ImplementationSpecific" {
+		t.Errorf("unexpected message: %s", result.Results[0].Message)
+	}
+	if len(result.Results[0].Details) != 1 || result.Results[0].Details[0] != "Host app.example.com, path /api" {
+		t.Errorf("details should list only the ImplementationSpecific path, got: %v", result.Results[0].Details)
 	}
 }
 
@@ -398,6 +3253,59 @@ func TestNetworkPolicies(t *testing.T) {
 	}
 }
 
+func TestNetworkPoliciesDefaultDenyIsolatesPod(t *testing.T) {
+	check := NewNetworkPolicies()
+	denyAll := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: "shop"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Labels: map[string]string{"app": "web"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}
+
+	result, err := check.Run(context.Background(), fake.NewSimpleClientset(ns, pod, denyAll))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	found := false
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "cut off from all ingress traffic") {
+			found = true
+			if r.Severity != probe.SeverityWarning {
+				t.Errorf("expected warning, got %s", r.Severity)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("expected isolated pod to be reported")
+	}
+
+	allowWeb := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-web", Namespace: "shop"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
+		},
+	}
+
+	result, err = check.Run(context.Background(), fake.NewSimpleClientset(ns, pod, denyAll, allowWeb))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "cut off") {
+			t.Errorf("pod with an allow policy should not be reported: %s", r.Message)
+		}
+	}
+}
+
 func TestDNSResolution(t *testing.T) {
 	check := NewDNSResolution()
 	if check.Name() != "dns-resolution" {
@@ -417,6 +3325,58 @@ func TestDNSResolution(t *testing.T) {
 	}
 }
 
+func TestDNSConfigPolicyNoneWithoutNameservers(t *testing.T) {
+	check := NewDNSConfig()
+	if check.Name() != "dns-config" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 4 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "app"},
+			Spec:       corev1.PodSpec{DNSPolicy: corev1.DNSNone},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "resolver", Namespace: "app"},
+			Spec: corev1.PodSpec{
+				DNSPolicy: corev1.DNSNone,
+				DNSConfig: &corev1.PodDNSConfig{Nameservers: []string{"10.96.0.10"}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Spec:       corev1.PodSpec{DNSPolicy: corev1.DNSClusterFirst},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("dnsPolicy None without nameservers should warn")
+	}
+
+	noNameservers := result.Results[0]
+	if noNameservers.Message != "1 pods in namespace app use dnsPolicy None without nameservers" {
+		t.Errorf("unexpected message: %s", noNameservers.Message)
+	}
+	if len(noNameservers.Details) != 1 || noNameservers.Details[0] != "app/broken" {
+		t.Errorf("expected app/broken in details, got: %v", noNameservers.Details)
+	}
+
+	custom := result.Results[1]
+	if custom.Message != "1 pods in namespace app use custom nameservers" {
+		t.Errorf("unexpected message: %s", custom.Message)
+	}
+}
+
 func TestRBACAudit(t *testing.T) {
 	check := NewRBACAudit()
 	if check.Name() != "rbac-audit" {
@@ -457,6 +3417,125 @@ func TestRBACAuditWildcard(t *testing.T) {
 	}
 }
 
+func TestRBACAuditAggregateToAdmin(t *testing.T) {
+	check := NewRBACAudit()
+	client := fake.NewSimpleClientset(
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node-shell",
+				Labels: map[string]string{"rbac.authorization.k8s.io/aggregate-to-admin": "true"},
+			},
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"nodes/proxy"},
+				Verbs:     []string{"get", "create"},
+			}},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "system:aggregate-to-admin",
+				Labels: map[string]string{"rbac.authorization.k8s.io/aggregate-to-admin": "true"},
+			},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var aggregated []probe.Result
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "aggregated into the built-in admin role") {
+			aggregated = append(aggregated, r)
+		}
+	}
+	if len(aggregated) != 1 {
+		t.Fatalf("expected one aggregation warning for the custom role, got %+v", result.Results)
+	}
+	if aggregated[0].Severity != probe.SeverityWarning || aggregated[0].Message != "ClusterRole node-shell is aggregated into the built-in admin role" {
+		t.Errorf("unexpected result: %+v", aggregated[0])
+	}
+	if !strings.Contains(strings.Join(aggregated[0].Details, "\n"), "Rule: apiGroups: [''], resources: ['nodes/proxy'], verbs: ['get', 'create']") {
+		t.Errorf("details should list the aggregated rules, got: %v", aggregated[0].Details)
+	}
+}
+
+func TestRBACAuditSkipsBootstrapAggregatedRoles(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "edit",
+				Labels: map[string]string{
+					"kubernetes.io/bootstrapping":                  "rbac-defaults",
+					"rbac.authorization.k8s.io/aggregate-to-admin": "true",
+				},
+			},
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups: []string{"apps"},
+				Resources: []string{"deployments"},
+				Verbs:     []string{"create", "update"},
+			}},
+		},
+	)
+
+	result, err := NewRBACAudit().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "aggregated into the built-in admin role") {
+			t.Errorf("built-in roles labelled rbac-defaults should not be reported, got %q", r.Message)
+		}
+	}
+}
+
+func TestRBACAuditDanglingRoleRef(t *testing.T) {
+	check := NewRBACAudit()
+	client := fake.NewSimpleClientset(
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "viewer"}},
+		&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: "editor", Namespace: "app"}},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "ci-deployer"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "deployer"},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "viewers"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "viewer"},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "editors", Namespace: "app"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "editor"},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "editors", Namespace: "other"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "editor"},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Errorf("expected warning, got %v", result.MaxSeverity())
+	}
+
+	var dangling []string
+	for _, r := range result.Results {
+		if strings.Contains(r.Message, "references missing") {
+			dangling = append(dangling, r.Message)
+		}
+	}
+	want := []string{
+		"ClusterRoleBinding ci-deployer references missing ClusterRole deployer",
+		"RoleBinding other/editors references missing Role editor",
+	}
+	if strings.Join(dangling, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected dangling bindings:\n%s", strings.Join(dangling, "\n"))
+	}
+}
+
 func TestPodSecurity(t *testing.T) {
 	check := NewPodSecurity()
 	if check.Name() != "pod-security" {
@@ -504,6 +3583,116 @@ func TestPodSecurityPrivileged(t *testing.T) {
 	}
 }
 
+func TestImagePullPolicyMutableTag(t *testing.T) {
+	check := NewImagePullPolicy()
+	if check.Name() != "image-pull-policy" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 5 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	pod := func(namespace, name, image string, policy corev1.PullPolicy) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: image, ImagePullPolicy: policy}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		pod("app", "web", "registry.example.com/web:1.4", corev1.PullAlways),
+		pod("app", "pinned", "registry.example.com/web@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945", corev1.PullAlways),
+		pod("app", "cached", "registry.example.com/web:1.4", corev1.PullIfNotPresent),
+		pod("kube-system", "proxy", "registry.k8s.io/kube-proxy:v1.30.0", corev1.PullAlways),
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected one warning and a summary, got %+v", result.Results)
+	}
+
+	r := result.Results[0]
+	if r.Message != "Pod app/web pulls mutable image tags with imagePullPolicy Always" {
+		t.Errorf("unexpected message: %s", r.Message)
+	}
+	if len(r.Details) != 1 || r.Details[0] != "Container app: registry.example.com/web:1.4" {
+		t.Errorf("unexpected details: %v", r.Details)
+	}
+	if result.Results[1].Message != "Image pull policy: 1 of 3 pods pull mutable tags on every start" {
+		t.Errorf("unexpected summary: %s", result.Results[1].Message)
+	}
+}
+
+func TestNodePortsSensitivePort(t *testing.T) {
+	check := NewNodePorts()
+	if check.Name() != "node-ports" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 5 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "db"},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeNodePort,
+				Ports: []corev1.ServicePort{{Port: 5432, TargetPort: intstr.FromInt32(5432), NodePort: 30432}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeNodePort,
+				Ports: []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(8080), NodePort: 30080}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "internal", Namespace: "app"},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{{Port: 22}},
+			},
+		},
+	)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected one warning and a summary, got %+v", result.Results)
+	}
+	if result.Results[0].Message != "Service db/postgres exposes a sensitive port on every node" {
+		t.Errorf("unexpected message: %s", result.Results[0].Message)
+	}
+
+	summary := result.Results[1]
+	if summary.Message != "Node ports: 2 allocated, 1 services on sensitive ports" {
+		t.Errorf("unexpected summary: %s", summary.Message)
+	}
+	if len(summary.Details) != 2 || summary.Details[0] != "30080/TCP: app/web (NodePort)" {
+		t.Errorf("summary should list allocated node ports, got %v", summary.Details)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.SensitivePorts = []int{8080}
+	check.Configure(cfg)
+	result, err = check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Results[0].Message != "Service app/web exposes a sensitive port on every node" {
+		t.Errorf("configured sensitive ports should replace the defaults, got %q", result.Results[0].Message)
+	}
+}
+
 func TestSecretsUsage(t *testing.T) {
 	check := NewSecretsUsage()
 	if check.Name() != "secrets-usage" {
@@ -552,6 +3741,67 @@ func TestServiceAccounts(t *testing.T) {
 	}
 }
 
+func TestNodeOSEOL(t *testing.T) {
+	check := NewNodeOSEOL()
+	if check.Name() != "node-os-eol" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 5 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy-1"},
+			Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{
+				OSImage:       "CentOS Linux 7 (Core)",
+				KernelVersion: "3.10.0-1160.el7.x86_64",
+			}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "current-1"},
+			Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{
+				OSImage:       "Ubuntu 22.04.4 LTS",
+				KernelVersion: "5.15.0-105-generic",
+			}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "custom-1"},
+			Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{
+				OSImage:       "Acme Linux 1.0",
+				KernelVersion: "5.10.0",
+			}},
+		},
+	)
+
+	cfg := config.DefaultConfig()
+	cfg.EOLOSPatterns = []string{`Acme Linux 1\.`}
+	check.Configure(cfg)
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	flagged := map[string]bool{}
+	for _, r := range result.Results {
+		if r.Severity == probe.SeverityWarning && strings.Contains(r.Message, "end-of-life") {
+			for _, node := range r.Details {
+				flagged[node] = true
+			}
+		}
+	}
+	if !flagged["legacy-1"] {
+		t.Error("expected CentOS 7 node to be flagged by the built-in patterns")
+	}
+	if !flagged["custom-1"] {
+		t.Error("expected node matching a configured pattern to be flagged")
+	}
+	if flagged["current-1"] {
+		t.Error("supported node should not be flagged")
+	}
+}
+
 func TestContainsString(t *testing.T) {
 	slice := []string{"a", "b", "c"}
 	if !containsString(slice, "b") {
@@ -574,3 +3824,115 @@ func TestIsDangerousCapability(t *testing.T) {
 		t.Error("NET_BIND_SERVICE should not be dangerous")
 	}
 }
+
+func TestProbePermissions(t *testing.T) {
+	check := NewProbePermissions()
+	if check.Name() != "probe-permissions" {
+		t.Errorf("unexpected name: %s", check.Name())
+	}
+	if check.Tier() != 5 {
+		t.Errorf("unexpected tier: %d", check.Tier())
+	}
+
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}}},
+		{GroupVersion: "cert-manager.io/v1", APIResources: []metav1.APIResource{{Name: "certificates", Kind: "Certificate"}}},
+		{GroupVersion: "argoproj.io/v1alpha1", APIResources: []metav1.APIResource{{Name: "workflows", Kind: "Workflow"}}},
+		{GroupVersion: "snapshot.storage.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "volumesnapshots", Kind: "VolumeSnapshot"}}},
+	}
+	readOnly := []string{"get", "list", "watch"}
+	client.PrependReactor("create", "selfsubjectrulesreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectRulesReview)
+		if review.Spec.Namespace == "" {
+			t.Error("SelfSubjectRulesReview needs a namespace")
+		}
+		review.Status.ResourceRules = []authorizationv1.ResourceRule{
+			{APIGroups: []string{""}, Resources: []string{"configmaps", "endpoints", "events", "limitranges", "namespaces", "nodes", "persistentvolumeclaims", "persistentvolumes", "pods", "resourcequotas", "serviceaccounts", "services"}, Verbs: readOnly},
+			{APIGroups: []string{"apps", "batch", "autoscaling", "policy", "networking.k8s.io", "rbac.authorization.k8s.io", "admissionregistration.k8s.io", "certificates.k8s.io"}, Resources: []string{"*"}, Verbs: readOnly},
+			{APIGroups: []string{"storage.k8s.io"}, Resources: []string{"storageclasses", "csidrivers"}, Verbs: readOnly},
+			{APIGroups: []string{"cert-manager.io"}, Resources: []string{"*"}, Verbs: readOnly},
+			{APIGroups: []string{"authorization.k8s.io"}, Resources: []string{"selfsubjectrulesreviews"}, Verbs: []string{"create"}},
+		}
+		return true, review, nil
+	})
+
+	result, err := check.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.MaxSeverity() != probe.SeverityWarning {
+		t.Fatal("missing permissions should warn")
+	}
+	if len(result.Results) != 3 {
+		t.Fatalf("expected resource, CRD group and summary results, got %+v", result.Results)
+	}
+	if got := result.Results[0].Details; len(got) != 1 || got[0] != "volumeattachments.storage.k8s.io" {
+		t.Errorf("expected volumeattachments to be reported missing, got %v", got)
+	}
+	if got := result.Results[1].Details; len(got) != 2 || got[0] != "argoproj.io" || got[1] != "snapshot.storage.k8s.io" {
+		t.Errorf("expected argoproj.io and snapshot.storage.k8s.io to be reported, got %v", got)
+	}
+	if result.Results[2].Message != "Probe permissions: 31/32 required resource types readable" {
+		t.Errorf("unexpected summary: %s", result.Results[2].Message)
+	}
+
+	cached := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "cert-manager.io/v1", APIResources: []metav1.APIResource{{Name: "certificates", Kind: "Certificate"}}},
+	}}}
+	result, err = check.RunDynamic(context.Background(), client, nil, cached)
+	if err != nil {
+		t.Fatalf("RunDynamic failed: %v", err)
+	}
+	if len(result.Results) != 2 {
+		t.Errorf("RunDynamic should read API groups from the shared discovery client, got %+v", result.Results)
+	}
+}
+
+func TestProbePermissionsReviewFailureIsInfo(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectrulesreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "authorization.k8s.io", Resource: "selfsubjectrulesreviews"}, "", fmt.Errorf("denied"))
+	})
+
+	result, err := NewProbePermissions().Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Results) != 1 || result.Results[0].Severity != probe.SeverityInfo {
+		t.Errorf("expected a single INFO result when permissions cannot be verified, got %+v", result.Results)
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Reason: "KubeletNotReady"},
+		}},
+	})
+
+	results, err := RunAll(context.Background(), client, nil, nil, nil, config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("RunAll failed: %v", err)
+	}
+
+	byName := make(map[string]probe.CheckResult, len(results))
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+	for _, check := range Defaults() {
+		_, ran := byName[check.Name()]
+		optIn, ok := check.(probe.OptInCheck)
+		if ok && optIn.OptIn() && ran {
+			t.Errorf("opt-in check %s should not run by default", check.Name())
+		}
+		if !(ok && optIn.OptIn()) && !ran {
+			t.Errorf("default check %s did not run", check.Name())
+		}
+	}
+	nodeStatus := byName["node-status"]
+	if nodeStatus.MaxSeverity() != probe.SeverityCritical {
+		t.Errorf("expected node-status to report the NotReady node, got %+v", nodeStatus.Results)
+	}
+}
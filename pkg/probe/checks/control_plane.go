@@ -3,17 +3,36 @@ package checks
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
-type ControlPlane struct{}
+const controlPlaneSaturationPercent = 90
+
+var podMetricsGVR = schema.GroupVersionResource{
+	Group:		"metrics.k8s.io",
+	Version:	"v1beta1",
+	Resource:	"pods",
+}
+
+type ControlPlane struct {
+	systemNamespaces []string
+}
 
 func NewControlPlane() *ControlPlane {
-	return &ControlPlane{}
+	return &ControlPlane{
+		systemNamespaces: []string{"kube-system"},
+	}
 }
 
 func (c *ControlPlane) Name() string {
@@ -24,22 +43,35 @@ func (c *ControlPlane) Tier() int {
 	return 1
 }
 
+func (c *ControlPlane) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
 func (c *ControlPlane) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, nil)
+}
+
+func (c *ControlPlane) RunDynamic(ctx context.Context, client kubernetes.Interface, dynamicClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, dynamicClient)
+}
+
+func (c *ControlPlane) run(ctx context.Context, client kubernetes.Interface, dynamicClient dynamic.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
 		Tier:		c.Tier(),
 		Results:	[]probe.Result{},
 	}
 
-	pods, err := client.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{})
+	pods, err := listPodsIn(ctx, client, c.systemNamespaces)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list kube-system pods: %w", err)
+		return nil, fmt.Errorf("failed to list %s pods: %w", strings.Join(c.systemNamespaces, ", "), err)
 	}
 
 	components := map[string]struct {
 		found	bool
 		running	bool
 		podName	string
+		podNS	string
 		message	string
 	}{
 		"kube-apiserver":		{},
@@ -55,6 +87,7 @@ func (c *ControlPlane) Run(ctx context.Context, client kubernetes.Interface) (*p
 				info := components[component]
 				info.found = true
 				info.podName = pod.Name
+				info.podNS = pod.Namespace
 
 				if pod.Status.Phase == corev1.PodRunning {
 					allReady := true
@@ -75,32 +108,43 @@ func (c *ControlPlane) Run(ctx context.Context, client kubernetes.Interface) (*p
 		}
 	}
 
+	sampled := probe.IsSampled(pods.ListMeta)
 	allHealthy := true
 	for component, info := range components {
 		if !info.found {
+			if sampled {
+				continue
+			}
 
 			result.Results = append(result.Results, probe.Result{
 				CheckName:	c.Name(),
 				Severity:	probe.SeverityWarning,
-				Message:	fmt.Sprintf("%s not found in kube-system", component),
+				Message:	fmt.Sprintf("%s not found in %s", component, strings.Join(c.systemNamespaces, ", ")),
 				Details:	[]string{"This may be normal for managed Kubernetes clusters"},
 				Remediation:	"Verify control plane components are running if using self-managed Kubernetes",
 			})
 		} else if !info.running {
 			allHealthy = false
 			result.Results = append(result.Results, probe.Result{
-				CheckName:	c.Name(),
-				Severity:	probe.SeverityCritical,
-				Message:	fmt.Sprintf("%s is not healthy", component),
-				Details:	[]string{fmt.Sprintf("Pod: %s", info.podName), info.message},
-				Remediation:	fmt.Sprintf("Check %s logs with 'kubectl logs -n kube-system %s'", component, info.podName),
+				CheckName:		c.Name(),
+				Severity:		probe.SeverityCritical,
+				Message:		fmt.Sprintf("%s is not healthy", component),
+				Namespace:		info.podNS,
+				Details:		[]string{fmt.Sprintf("Pod: %s", info.podName), info.message},
+				Remediation:		fmt.Sprintf("Check %s logs", component),
+				RemediationCommands:	[]string{fmt.Sprintf("kubectl logs -n %s %s", info.podNS, info.podName)},
 			})
 		}
 	}
 
-	dnsHealthy := c.checkDNS(pods.Items, result)
+	dnsHealthy := c.checkDNS(pods.Items, sampled, result)
+
+	saturated := false
+	if dynamicClient != nil {
+		saturated = c.checkSaturation(ctx, dynamicClient, pods.Items, result)
+	}
 
-	if allHealthy && dnsHealthy {
+	if allHealthy && dnsHealthy && !saturated {
 		result.Results = append(result.Results, probe.Result{
 			CheckName:	c.Name(),
 			Severity:	probe.SeverityOK,
@@ -111,7 +155,7 @@ func (c *ControlPlane) Run(ctx context.Context, client kubernetes.Interface) (*p
 	return result, nil
 }
 
-func (c *ControlPlane) checkDNS(pods []corev1.Pod, result *probe.CheckResult) bool {
+func (c *ControlPlane) checkDNS(pods []corev1.Pod, sampled bool, result *probe.CheckResult) bool {
 	dnsFound := false
 	dnsHealthy := true
 
@@ -130,6 +174,7 @@ func (c *ControlPlane) checkDNS(pods []corev1.Pod, result *probe.CheckResult) bo
 					CheckName:	c.Name(),
 					Severity:	probe.SeverityCritical,
 					Message:	fmt.Sprintf("DNS pod %s is not running", pod.Name),
+					Namespace:	pod.Namespace,
 					Details:	[]string{fmt.Sprintf("Phase: %s", pod.Status.Phase)},
 					Remediation:	"Check DNS pod logs and events",
 				})
@@ -141,6 +186,7 @@ func (c *ControlPlane) checkDNS(pods []corev1.Pod, result *probe.CheckResult) bo
 							CheckName:	c.Name(),
 							Severity:	probe.SeverityWarning,
 							Message:	fmt.Sprintf("DNS container %s in pod %s not ready", cs.Name, pod.Name),
+							Namespace:	pod.Namespace,
 							Remediation:	"Check DNS pod logs for errors",
 						})
 					}
@@ -150,6 +196,9 @@ func (c *ControlPlane) checkDNS(pods []corev1.Pod, result *probe.CheckResult) bo
 	}
 
 	if !dnsFound {
+		if sampled {
+			return true
+		}
 		result.Results = append(result.Results, probe.Result{
 			CheckName:	c.Name(),
 			Severity:	probe.SeverityWarning,
@@ -162,6 +211,110 @@ func (c *ControlPlane) checkDNS(pods []corev1.Pod, result *probe.CheckResult) bo
 	return dnsHealthy
 }
 
+func (c *ControlPlane) checkSaturation(ctx context.Context, dynamicClient dynamic.Interface, pods []corev1.Pod, result *probe.CheckResult) bool {
+	usageByPod := make(map[string]corev1.ResourceList)
+	for _, namespace := range c.systemNamespaces {
+		metricsList, err := dynamicClient.Resource(podMetricsGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false
+		}
+		for _, item := range metricsList.Items {
+			usageByPod[item.GetNamespace()+"/"+item.GetName()] = podMetricsUsage(&item)
+		}
+	}
+
+	saturated := false
+	for _, pod := range pods {
+		component := ""
+		for _, name := range []string{"etcd", "kube-apiserver"} {
+			if containsComponent(pod.Name, name) {
+				component = name
+				break
+			}
+		}
+		if component == "" {
+			continue
+		}
+
+		usage, ok := usageByPod[pod.Namespace+"/"+pod.Name]
+		if !ok {
+			continue
+		}
+
+		for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			limit, ok := podLimit(&pod, resourceName)
+			if !ok || limit.IsZero() {
+				continue
+			}
+			used := usage[resourceName]
+
+			percent := used.MilliValue() * 100 / limit.MilliValue()
+			if percent < controlPlaneSaturationPercent {
+				continue
+			}
+
+			saturated = true
+			result.Results = append(result.Results, probe.Result{
+				CheckName:	c.Name(),
+				Severity:	probe.SeverityWarning,
+				Message:	fmt.Sprintf("%s pod %s is using %d%% of its %s limit", component, pod.Name, percent, resourceName),
+				Namespace:	pod.Namespace,
+				Details: []string{
+					fmt.Sprintf("Usage: %s of %s", formatResourceQuantity(resourceName, used), formatResourceQuantity(resourceName, limit)),
+					"A resource-starved control plane slows down API requests and leader elections across the cluster",
+				},
+				Remediation:	fmt.Sprintf("Raise the %s limit in the %s static pod manifest or reduce load on the API server", resourceName, component),
+				RemediationCommands: []string{
+					fmt.Sprintf("kubectl top pod -n %s %s --containers", pod.Namespace, pod.Name),
+				},
+			})
+		}
+	}
+
+	return saturated
+}
+
+func podMetricsUsage(item *unstructured.Unstructured) corev1.ResourceList {
+	usage := corev1.ResourceList{}
+	containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+	for _, container := range containers {
+		containerMap, ok := container.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		containerUsage, _, _ := unstructured.NestedStringMap(containerMap, "usage")
+		for name, value := range containerUsage {
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				continue
+			}
+			total := usage[corev1.ResourceName(name)]
+			total.Add(quantity)
+			usage[corev1.ResourceName(name)] = total
+		}
+	}
+	return usage
+}
+
+func podLimit(pod *corev1.Pod, resourceName corev1.ResourceName) (resource.Quantity, bool) {
+	var total resource.Quantity
+	for _, container := range pod.Spec.Containers {
+		limit, ok := container.Resources.Limits[resourceName]
+		if !ok {
+			return resource.Quantity{}, false
+		}
+		total.Add(limit)
+	}
+	return total, len(pod.Spec.Containers) > 0
+}
+
+func formatResourceQuantity(resourceName corev1.ResourceName, quantity resource.Quantity) string {
+	if resourceName == corev1.ResourceMemory {
+		return formatBytes(quantity.Value())
+	}
+	return fmt.Sprintf("%dm", quantity.MilliValue())
+}
+
 func containsComponent(podName, component string) bool {
 
 	if len(podName) >= len(component) && podName[:len(component)] == component {
@@ -3,17 +3,22 @@ package checks
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-type CriticalPods struct{}
+type CriticalPods struct {
+	systemNamespaces []string
+}
 
 func NewCriticalPods() *CriticalPods {
-	return &CriticalPods{}
+	return &CriticalPods{
+		systemNamespaces: []string{"kube-system"},
+	}
 }
 
 func (c *CriticalPods) Name() string {
@@ -24,6 +29,10 @@ func (c *CriticalPods) Tier() int {
 	return 1
 }
 
+func (c *CriticalPods) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
 func (c *CriticalPods) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
@@ -31,9 +40,9 @@ func (c *CriticalPods) Run(ctx context.Context, client kubernetes.Interface) (*p
 		Results:	[]probe.Result{},
 	}
 
-	pods, err := client.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{})
+	pods, err := listPodsIn(ctx, client, c.systemNamespaces)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list kube-system pods: %w", err)
+		return nil, fmt.Errorf("failed to list %s pods: %w", strings.Join(c.systemNamespaces, ", "), err)
 	}
 
 	criticalPrefixes := []string{
@@ -73,6 +82,7 @@ func (c *CriticalPods) Run(ctx context.Context, client kubernetes.Interface) (*p
 						warnings++
 					}
 
+					remediation, commands := c.getRemediation(reason, pod.Namespace, pod.Name)
 					result.Results = append(result.Results, probe.Result{
 						CheckName:	c.Name(),
 						Severity:	severity,
@@ -81,7 +91,8 @@ func (c *CriticalPods) Run(ctx context.Context, client kubernetes.Interface) (*p
 							fmt.Sprintf("Namespace: %s", pod.Namespace),
 							fmt.Sprintf("Restarts: %d", cs.RestartCount),
 						},
-						Remediation:	c.getRemediation(reason),
+						Remediation:		remediation,
+						RemediationCommands:	commands,
 					})
 				}
 			}
@@ -99,10 +110,12 @@ func (c *CriticalPods) Run(ctx context.Context, client kubernetes.Interface) (*p
 					CheckName:	c.Name(),
 					Severity:	severity,
 					Message:	fmt.Sprintf("Pod %s container %s has high restart count", pod.Name, cs.Name),
+					Namespace:	pod.Namespace,
 					Details: []string{
 						fmt.Sprintf("Restart count: %d", cs.RestartCount),
 					},
-					Remediation:	"Check container logs for crash reasons: kubectl logs -n kube-system " + pod.Name,
+					Remediation:		"Check container logs for crash reasons",
+					RemediationCommands:	[]string{fmt.Sprintf("kubectl logs -n %s %s -c %s --previous", pod.Namespace, pod.Name, cs.Name)},
 				})
 			}
 		}
@@ -120,6 +133,7 @@ func (c *CriticalPods) Run(ctx context.Context, client kubernetes.Interface) (*p
 				CheckName:	c.Name(),
 				Severity:	severity,
 				Message:	fmt.Sprintf("Pod %s is in Failed state", pod.Name),
+				Namespace:	pod.Namespace,
 				Details: []string{
 					fmt.Sprintf("Reason: %s", pod.Status.Reason),
 					fmt.Sprintf("Message: %s", pod.Status.Message),
@@ -144,6 +158,7 @@ func (c *CriticalPods) Run(ctx context.Context, client kubernetes.Interface) (*p
 						CheckName:	c.Name(),
 						Severity:	severity,
 						Message:	fmt.Sprintf("Pod %s is pending and unschedulable", pod.Name),
+						Namespace:	pod.Namespace,
 						Details: []string{
 							fmt.Sprintf("Reason: %s", cond.Reason),
 							fmt.Sprintf("Message: %s", cond.Message),
@@ -166,13 +181,13 @@ func (c *CriticalPods) Run(ctx context.Context, client kubernetes.Interface) (*p
 	return result, nil
 }
 
-func (c *CriticalPods) getRemediation(reason string) string {
+func (c *CriticalPods) getRemediation(reason, namespace, podName string) (string, []string) {
 	switch reason {
 	case "CrashLoopBackOff":
-		return "Container is crashing repeatedly. Check logs: kubectl logs -n kube-system <pod-name> --previous"
+		return "Container is crashing repeatedly. Check logs", []string{"kubectl logs -n " + namespace + " " + podName + " --previous"}
 	case "ImagePullBackOff", "ErrImagePull":
-		return "Cannot pull container image. Check image name, registry access, and network connectivity"
+		return "Cannot pull container image. Check image name, registry access, and network connectivity", nil
 	default:
-		return "Check pod events: kubectl describe pod -n kube-system <pod-name>"
+		return "Check pod events", []string{"kubectl describe pod -n " + namespace + " " + podName}
 	}
 }
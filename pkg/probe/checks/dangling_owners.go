@@ -0,0 +1,194 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const danglingOwnersMaxExamples = 10
+
+type DanglingOwners struct{}
+
+func NewDanglingOwners() *DanglingOwners {
+	return &DanglingOwners{}
+}
+
+func (c *DanglingOwners) Name() string {
+	return "dangling-owners"
+}
+
+func (c *DanglingOwners) Tier() int {
+	return 2
+}
+
+func (c *DanglingOwners) OptIn() bool {
+	return true
+}
+
+type ownerLookup struct {
+	ctx    context.Context
+	client kubernetes.Interface
+	uids   map[string]map[types.UID]bool
+}
+
+func (c *DanglingOwners) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	replicaSets, err := client.AppsV1().ReplicaSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+
+	pods, err := listPods(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	lookup := &ownerLookup{ctx: ctx, client: client, uids: make(map[string]map[types.UID]bool)}
+	lookup.uids["ReplicaSet"] = make(map[types.UID]bool, len(replicaSets.Items))
+	for _, rs := range replicaSets.Items {
+		lookup.uids["ReplicaSet"][rs.UID] = true
+	}
+
+	dangling := make(map[string][]string)
+	total := 0
+	checked := 0
+
+	for _, rs := range replicaSets.Items {
+		checked++
+		found, err := lookup.danglingRefs("ReplicaSet", rs.Namespace, rs.Name, rs.OwnerReferences)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) > 0 {
+			dangling[rs.Namespace] = append(dangling[rs.Namespace], found...)
+			total += len(found)
+		}
+	}
+
+	for _, pod := range pods.Items {
+		checked++
+		found, err := lookup.danglingRefs("Pod", pod.Namespace, pod.Name, pod.OwnerReferences)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) > 0 {
+			dangling[pod.Namespace] = append(dangling[pod.Namespace], found...)
+			total += len(found)
+		}
+	}
+
+	for _, namespace := range sortedKeys(dangling) {
+		refs := dangling[namespace]
+		sort.Strings(refs)
+		details := refs
+		if len(details) > danglingOwnersMaxExamples {
+			details = append(append([]string{}, refs[:danglingOwnersMaxExamples]...), fmt.Sprintf("... and %d more", len(refs)-danglingOwnersMaxExamples))
+		}
+
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("%d owner references in namespace %s point at objects that no longer exist", len(refs), namespace),
+			Namespace:   namespace,
+			Details:     details,
+			Remediation: "The garbage collector should remove dependents of deleted owners. Check kube-controller-manager garbage collector logs, then delete the orphaned objects or remove the stale ownerReferences",
+			RemediationCommands: []string{
+				"kubectl logs -n kube-system -l component=kube-controller-manager | grep -i garbage",
+			},
+		})
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  probe.SeverityOK,
+		Message:   fmt.Sprintf("Owner references: %d dangling across %d ReplicaSets and pods", total, checked),
+	})
+
+	return result, nil
+}
+
+func (l *ownerLookup) danglingRefs(kind, namespace, name string, refs []metav1.OwnerReference) ([]string, error) {
+	var dangling []string
+	for _, ref := range refs {
+		uids, err := l.ownerUIDs(ref.Kind)
+		if err != nil {
+			return nil, err
+		}
+		if uids == nil || uids[ref.UID] {
+			continue
+		}
+		dangling = append(dangling, fmt.Sprintf("%s %s/%s: owner %s %s (uid %s) not found", kind, namespace, name, ref.Kind, ref.Name, ref.UID))
+	}
+	return dangling, nil
+}
+
+func (l *ownerLookup) ownerUIDs(kind string) (map[types.UID]bool, error) {
+	if uids, ok := l.uids[kind]; ok {
+		return uids, nil
+	}
+
+	var objects []metav1.Object
+	switch kind {
+	case "Deployment":
+		list, err := l.client.AppsV1().Deployments("").List(l.ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments: %w", err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "StatefulSet":
+		list, err := l.client.AppsV1().StatefulSets("").List(l.ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "DaemonSet":
+		list, err := l.client.AppsV1().DaemonSets("").List(l.ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "Job":
+		list, err := l.client.BatchV1().Jobs("").List(l.ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs: %w", err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "Node":
+		list, err := l.client.CoreV1().Nodes().List(l.ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list nodes: %w", err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	default:
+		l.uids[kind] = nil
+		return nil, nil
+	}
+
+	uids := make(map[types.UID]bool, len(objects))
+	for _, obj := range objects {
+		uids[obj.GetUID()] = true
+	}
+	l.uids[kind] = uids
+	return uids, nil
+}
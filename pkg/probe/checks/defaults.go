@@ -0,0 +1,88 @@
+package checks
+
+import (
+	"context"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+)
+
+func Defaults() []probe.Check {
+	return []probe.Check{
+		NewNodeStatus(),
+		NewControlPlane(),
+		NewNetworkingInfra(),
+		NewCriticalPods(),
+		NewCertificates(),
+		NewAdmissionWebhooks(),
+
+		NewPodStatus(),
+		NewDeploymentStatus(),
+		NewPVCStatus(),
+		NewJobFailures(),
+		NewStalledResources(),
+		NewSecretReferences(),
+		NewHPAStatus(),
+		NewNakedPods(),
+		NewPDBStatus(),
+		NewNodePinnedPods(),
+		NewDanglingOwners(),
+		NewDuplicateNames(),
+		NewSingletonReplicas(),
+		NewPodChurn(),
+
+		NewResourceRequests(),
+		NewNodeCapacity(),
+		NewStorageHealth(),
+		NewInTreeStorage(),
+		NewNodeLabels(),
+		NewTopologySpread(),
+		NewQuotaUsage(),
+		NewPVReclaimPolicy(),
+		NewStatefulSetVolumes(),
+		NewObjectCounts(),
+		NewRequestBalance(),
+		NewHelmReleases(),
+		NewEventStorm(),
+
+		NewServiceEndpoints(),
+		NewIngressStatus(),
+		NewNetworkPolicies(),
+		NewDNSResolution(),
+		NewDNSConfig(),
+
+		NewRBACAudit(),
+		NewPodSecurity(),
+		NewSecretsUsage(),
+		NewImagePullPolicy(),
+		NewNodePorts(),
+		NewServiceAccounts(),
+		NewNodeOSEOL(),
+		NewProbePermissions(),
+	}
+}
+
+func RegisterDefaults(engine *probe.Engine) {
+	for _, check := range Defaults() {
+		engine.Register(check)
+	}
+}
+
+func RunAll(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface, metadataClient metadata.Interface, cfg *config.Config) ([]probe.CheckResult, error) {
+	engine := probe.NewEngine(false)
+	if cfg != nil {
+		engine.SetConfig(cfg)
+	}
+	if dynamicClient != nil && discoveryClient != nil {
+		engine.SetDynamicClients(dynamicClient, discoveryClient)
+	}
+	if metadataClient != nil {
+		engine.SetMetadataClient(metadataClient)
+	}
+	RegisterDefaults(engine)
+	return engine.Run(ctx, clientset)
+}
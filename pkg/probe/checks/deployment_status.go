@@ -87,11 +87,13 @@ func (c *DeploymentStatus) Run(ctx context.Context, client kubernetes.Interface)
 			}
 
 			result.Results = append(result.Results, probe.Result{
-				CheckName:	c.Name(),
-				Severity:	severity,
-				Message:	fmt.Sprintf("Deployment %s/%s has insufficient replicas", deploy.Namespace, deploy.Name),
-				Details:	details,
-				Remediation:	fmt.Sprintf("Check pods: kubectl get pods -n %s -l app=%s", deploy.Namespace, deploy.Name),
+				CheckName:		c.Name(),
+				Severity:		severity,
+				Message:		fmt.Sprintf("Deployment %s/%s has insufficient replicas", deploy.Namespace, deploy.Name),
+				Namespace:		deploy.Namespace,
+				Details:		details,
+				Remediation:		"Check the deployment's pods",
+				RemediationCommands:	[]string{fmt.Sprintf("kubectl get pods -n %s -l app=%s", deploy.Namespace, deploy.Name)},
 			})
 		} else if updated < desired {
 
@@ -100,10 +102,12 @@ func (c *DeploymentStatus) Run(ctx context.Context, client kubernetes.Interface)
 				CheckName:	c.Name(),
 				Severity:	probe.SeverityWarning,
 				Message:	fmt.Sprintf("Deployment %s/%s rollout in progress", deploy.Namespace, deploy.Name),
+				Namespace:	deploy.Namespace,
 				Details: []string{
 					fmt.Sprintf("Updated: %d/%d", updated, desired),
 				},
-				Remediation:	fmt.Sprintf("Monitor rollout: kubectl rollout status -n %s deployment/%s", deploy.Namespace, deploy.Name),
+				Remediation:		"Monitor the rollout",
+				RemediationCommands:	[]string{fmt.Sprintf("kubectl rollout status -n %s deployment/%s", deploy.Namespace, deploy.Name)},
 			})
 		} else {
 			healthy++
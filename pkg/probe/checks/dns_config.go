@@ -0,0 +1,132 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type DNSConfig struct {
+	systemNamespaces []string
+}
+
+func NewDNSConfig() *DNSConfig {
+	return &DNSConfig{
+		systemNamespaces: []string{"kube-system"},
+	}
+}
+
+func (c *DNSConfig) Name() string {
+	return "dns-config"
+}
+
+func (c *DNSConfig) Tier() int {
+	return 4
+}
+
+func (c *DNSConfig) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
+func (c *DNSConfig) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	pods, err := listPods(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	noNameservers := make(map[string][]string)
+	customNameservers := make(map[string][]string)
+	checked := 0
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		checked++
+
+		ref := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+		var nameservers []string
+		if pod.Spec.DNSConfig != nil {
+			nameservers = pod.Spec.DNSConfig.Nameservers
+		}
+
+		if pod.Spec.DNSPolicy == corev1.DNSNone && len(nameservers) == 0 {
+			noNameservers[pod.Namespace] = append(noNameservers[pod.Namespace], ref)
+			continue
+		}
+
+		isSystemNS := containsString(c.systemNamespaces, pod.Namespace)
+		if len(nameservers) > 0 && !isSystemNS {
+			customNameservers[pod.Namespace] = append(customNameservers[pod.Namespace], fmt.Sprintf("%s: %s", ref, strings.Join(nameservers, ", ")))
+		}
+	}
+
+	for _, ns := range sortedKeys(noNameservers) {
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("%d pods in namespace %s use dnsPolicy None without nameservers", len(noNameservers[ns]), ns),
+			Namespace:   ns,
+			Details:     noNameservers[ns],
+			Remediation: "Add spec.dnsConfig.nameservers or switch dnsPolicy to ClusterFirst so the pods can resolve names",
+		})
+	}
+
+	for _, ns := range sortedKeys(customNameservers) {
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("%d pods in namespace %s use custom nameservers", len(customNameservers[ns]), ns),
+			Namespace:   ns,
+			Details:     customNameservers[ns],
+			Remediation: "Custom nameservers bypass CoreDNS and cluster service discovery. Remove them unless the pods intentionally resolve outside the cluster",
+		})
+	}
+
+	misconfigured := len(noNameservers) + len(customNameservers)
+	severity := probe.SeverityOK
+	if misconfigured > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("DNS config: %d pods checked", checked),
+		Details: []string{
+			fmt.Sprintf("Pods with dnsPolicy None and no nameservers: %d", countValues(noNameservers)),
+			fmt.Sprintf("Pods with custom nameservers in user namespaces: %d", countValues(customNameservers)),
+		},
+	})
+
+	return result, nil
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func countValues(m map[string][]string) int {
+	total := 0
+	for _, v := range m {
+		total += len(v)
+	}
+	return total
+}
@@ -3,17 +3,25 @@ package checks
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-type DNSResolution struct{}
+var dnsServiceNames = []string{"kube-dns", "coredns", "rke2-coredns-rke2-coredns"}
+
+type DNSResolution struct {
+	systemNamespaces []string
+}
 
 func NewDNSResolution() *DNSResolution {
-	return &DNSResolution{}
+	return &DNSResolution{
+		systemNamespaces: []string{"kube-system"},
+	}
 }
 
 func (c *DNSResolution) Name() string {
@@ -24,6 +32,10 @@ func (c *DNSResolution) Tier() int {
 	return 4
 }
 
+func (c *DNSResolution) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
 func (c *DNSResolution) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
@@ -31,33 +43,25 @@ func (c *DNSResolution) Run(ctx context.Context, client kubernetes.Interface) (*
 		Results:	[]probe.Result{},
 	}
 
-	dnsService, err := client.CoreV1().Services("kube-system").Get(ctx, "kube-dns", metav1.GetOptions{})
-	if err != nil {
-
-		dnsService, err = client.CoreV1().Services("kube-system").Get(ctx, "coredns", metav1.GetOptions{})
-	}
-	if err != nil {
-
-		dnsService, err = client.CoreV1().Services("kube-system").Get(ctx, "rke2-coredns-rke2-coredns", metav1.GetOptions{})
-	}
-
-	if err != nil {
+	dnsService := c.findDNSService(ctx, client)
+	if dnsService == nil {
 		result.Results = append(result.Results, probe.Result{
 			CheckName:	c.Name(),
 			Severity:	probe.SeverityCritical,
 			Message:	"DNS service not found",
-			Details:	[]string{"Could not find kube-dns, coredns, or rke2-coredns service"},
-			Remediation:	"Check DNS addon installation",
+			Details:	[]string{fmt.Sprintf("Could not find kube-dns, coredns, or rke2-coredns service in %s", strings.Join(c.systemNamespaces, ", "))},
+			Remediation:	"Check DNS addon installation, or set system_namespaces in the config if DNS runs elsewhere",
 		})
 		return result, nil
 	}
 
-	endpoints, err := client.CoreV1().Endpoints("kube-system").Get(ctx, dnsService.Name, metav1.GetOptions{})
+	endpoints, err := client.CoreV1().Endpoints(dnsService.Namespace).Get(ctx, dnsService.Name, metav1.GetOptions{})
 	if err != nil {
 		result.Results = append(result.Results, probe.Result{
 			CheckName:	c.Name(),
 			Severity:	probe.SeverityCritical,
 			Message:	fmt.Sprintf("DNS endpoints not found for %s", dnsService.Name),
+			Namespace:	dnsService.Namespace,
 			Remediation:	"Check DNS pod status",
 		})
 		return result, nil
@@ -72,11 +76,12 @@ func (c *DNSResolution) Run(ctx context.Context, client kubernetes.Interface) (*
 
 	if readyAddresses == 0 {
 		result.Results = append(result.Results, probe.Result{
-			CheckName:	c.Name(),
-			Severity:	probe.SeverityCritical,
-			Message:	"No ready DNS endpoints",
-			Details:	[]string{fmt.Sprintf("Not ready: %d", notReadyAddresses)},
-			Remediation:	"Check DNS pod status: kubectl get pods -n kube-system -l k8s-app=kube-dns",
+			CheckName:		c.Name(),
+			Severity:		probe.SeverityCritical,
+			Message:		"No ready DNS endpoints",
+			Details:		[]string{fmt.Sprintf("Not ready: %d", notReadyAddresses)},
+			Remediation:		"Check DNS pod status",
+			RemediationCommands:	[]string{fmt.Sprintf("kubectl get pods -n %s -l k8s-app=kube-dns", dnsService.Namespace)},
 		})
 	} else {
 		result.Results = append(result.Results, probe.Result{
@@ -89,7 +94,7 @@ func (c *DNSResolution) Run(ctx context.Context, client kubernetes.Interface) (*
 		})
 	}
 
-	pods, err := client.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{})
+	pods, err := listPodsIn(ctx, client, c.systemNamespaces)
 	if err == nil {
 		dnsPodsReady := 0
 		dnsPodsTotal := 0
@@ -135,7 +140,7 @@ func (c *DNSResolution) Run(ctx context.Context, client kubernetes.Interface) (*
 		}
 	}
 
-	configMaps, err := client.CoreV1().ConfigMaps("kube-system").List(ctx, metav1.ListOptions{})
+	configMaps, err := client.CoreV1().ConfigMaps(dnsService.Namespace).List(ctx, metav1.ListOptions{})
 	if err == nil {
 		for _, cm := range configMaps.Items {
 			if cm.Name == "coredns" || cm.Name == "kube-dns" {
@@ -151,3 +156,14 @@ func (c *DNSResolution) Run(ctx context.Context, client kubernetes.Interface) (*
 
 	return result, nil
 }
+
+func (c *DNSResolution) findDNSService(ctx context.Context, client kubernetes.Interface) *corev1.Service {
+	for _, namespace := range c.systemNamespaces {
+		for _, name := range dnsServiceNames {
+			if service, err := client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+				return service
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type DuplicateNames struct {
+	deploymentNames []string
+}
+
+func NewDuplicateNames() *DuplicateNames {
+	return &DuplicateNames{}
+}
+
+func (c *DuplicateNames) Name() string {
+	return "duplicate-names"
+}
+
+func (c *DuplicateNames) Tier() int {
+	return 2
+}
+
+func (c *DuplicateNames) OptIn() bool {
+	return true
+}
+
+func (c *DuplicateNames) Configure(cfg *config.Config) {
+	c.deploymentNames = cfg.UniqueDeploymentNames
+}
+
+func (c *DuplicateNames) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	if len(c.deploymentNames) == 0 {
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityOK,
+			Message:   "Duplicate names: no names configured in unique_deployment_names",
+		})
+		return result, nil
+	}
+
+	deployments, err := client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	namespaces := make(map[string][]string)
+	for _, deploy := range deployments.Items {
+		namespaces[deploy.Name] = append(namespaces[deploy.Name], deploy.Namespace)
+	}
+
+	duplicates := 0
+	for _, name := range c.deploymentNames {
+		found := namespaces[name]
+		if len(found) < 2 {
+			continue
+		}
+		duplicates++
+		sort.Strings(found)
+
+		details := make([]string, 0, len(found))
+		for _, ns := range found {
+			details = append(details, fmt.Sprintf("%s/%s", ns, name))
+		}
+
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("Deployment %q exists in %d namespaces but is expected to be unique", name, len(found)),
+			Details:     details,
+			Remediation: "Remove or rename the unexpected copies so GitOps tooling resolves the name to a single Deployment",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl get deployments -A --field-selector metadata.name=%s", name),
+			},
+		})
+	}
+
+	severity := probe.SeverityOK
+	if duplicates > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Duplicate names: %d of %d unique Deployment names found in multiple namespaces", duplicates, len(c.deploymentNames)),
+	})
+
+	return result, nil
+}
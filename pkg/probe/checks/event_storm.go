@@ -0,0 +1,170 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type eventSource struct {
+	name        string
+	object      corev1.ObjectReference
+	reason      string
+	count       int
+	controllers map[string]int
+}
+
+type EventStorm struct {
+	warning int
+}
+
+func NewEventStorm() *EventStorm {
+	return &EventStorm{warning: 1000}
+}
+
+func (c *EventStorm) Name() string {
+	return "event-storm"
+}
+
+func (c *EventStorm) Tier() int {
+	return 3
+}
+
+func (c *EventStorm) Configure(cfg *config.Config) {
+	c.warning = cfg.GetThreshold("event_storm_warning")
+}
+
+func (c *EventStorm) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	events, err := listEvents(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	sources := make(map[string]*eventSource)
+	total := 0
+	for _, event := range events.Items {
+		count := eventCount(&event)
+		total += count
+
+		name := eventObject(&event)
+		key := name + "\x00" + event.Reason
+		source := sources[key]
+		if source == nil {
+			source = &eventSource{name: name, object: event.InvolvedObject, reason: event.Reason, controllers: make(map[string]int)}
+			sources[key] = source
+		}
+		source.count += count
+		source.controllers[eventController(&event)] += count
+	}
+
+	ranked := make([]*eventSource, 0, len(sources))
+	for _, source := range sources {
+		ranked = append(ranked, source)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].count != ranked[j].count {
+			return ranked[i].count > ranked[j].count
+		}
+		if ranked[i].name != ranked[j].name {
+			return ranked[i].name < ranked[j].name
+		}
+		return ranked[i].reason < ranked[j].reason
+	})
+
+	noisy := 0
+	for _, source := range ranked {
+		if source.count < c.warning {
+			continue
+		}
+		noisy++
+		details := []string{fmt.Sprintf("Threshold: %d events per object and reason", c.warning)}
+		for _, controller := range sortedEventControllers(source.controllers) {
+			details = append(details, fmt.Sprintf("Reported by %s: %d events", controller, source.controllers[controller]))
+		}
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityWarning,
+			Namespace: source.object.Namespace,
+			Message:   fmt.Sprintf("%s has %d %s events", source.name, source.count, source.reason),
+			Details:   details,
+			Remediation: "An object collecting the same event this often usually means its controller is stuck in a reconcile loop, and the event writes load the API server and etcd. " +
+				"Fix the condition being reported, or restart or scale down the controller",
+			RemediationCommands: []string{eventStormCommand(source)},
+		})
+	}
+
+	severity := probe.SeverityOK
+	if noisy > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Events: %d occurrences across %d object and reason pairs, %d above %d", total, len(sources), noisy, c.warning),
+	})
+
+	return result, nil
+}
+
+func eventCount(event *corev1.Event) int {
+	count := int(event.Count)
+	if event.Series != nil && int(event.Series.Count) > count {
+		count = int(event.Series.Count)
+	}
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+func eventController(event *corev1.Event) string {
+	if event.ReportingController != "" {
+		return event.ReportingController
+	}
+	if event.Source.Component != "" {
+		return event.Source.Component
+	}
+	return "unknown"
+}
+
+func eventObject(event *corev1.Event) string {
+	ref := event.InvolvedObject
+	if ref.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+	}
+	return fmt.Sprintf("%s %s", ref.Kind, ref.Name)
+}
+
+func eventStormCommand(source *eventSource) string {
+	selector := fmt.Sprintf("involvedObject.name=%s,reason=%s", source.object.Name, source.reason)
+	if source.object.Namespace != "" {
+		return fmt.Sprintf("kubectl get events -n %s --field-selector %s --sort-by=.lastTimestamp", source.object.Namespace, selector)
+	}
+	return fmt.Sprintf("kubectl get events -A --field-selector %s --sort-by=.lastTimestamp", selector)
+}
+
+func sortedEventControllers(controllers map[string]int) []string {
+	names := make([]string, 0, len(controllers))
+	for name := range controllers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if controllers[names[i]] != controllers[names[j]] {
+			return controllers[names[i]] > controllers[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
@@ -0,0 +1,141 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+)
+
+const helmReleaseSecretType corev1.SecretType = "helm.sh/release.v1"
+
+type helmRelease struct {
+	namespace string
+	name      string
+	revisions int
+}
+
+type HelmReleases struct {
+	maxRevisions int
+}
+
+func NewHelmReleases() *HelmReleases {
+	return &HelmReleases{maxRevisions: 10}
+}
+
+func (c *HelmReleases) Name() string {
+	return "helm-releases"
+}
+
+func (c *HelmReleases) Tier() int {
+	return 3
+}
+
+func (c *HelmReleases) Configure(cfg *config.Config) {
+	c.maxRevisions = cfg.GetThreshold("helm_release_revisions_warning")
+}
+
+func (c *HelmReleases) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, nil)
+}
+
+func (c *HelmReleases) RunMetadata(ctx context.Context, client kubernetes.Interface, metadataClient metadata.Interface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, metadataClient)
+}
+
+func (c *HelmReleases) run(ctx context.Context, client kubernetes.Interface, metadataClient metadata.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	secrets, err := listMetadata(ctx, client, metadataClient, secretsResource, metav1.ListOptions{
+		LabelSelector: "owner=helm",
+		FieldSelector: "type=" + string(helmReleaseSecretType),
+	})
+	if err != nil {
+		if reason, ok := secretsUnavailable(err); ok {
+			result.Results = append(result.Results, probe.Result{
+				CheckName: c.Name(),
+				Severity:  probe.SeverityInfo,
+				Message:   "Helm releases not counted: " + reason,
+			})
+			return result, nil
+		}
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	releases := make(map[string]*helmRelease)
+	namespaces := make(map[string]int)
+	total := 0
+	for _, secret := range secrets.Items {
+		name := secret.Labels["name"]
+		if name == "" {
+			continue
+		}
+		total++
+		namespaces[secret.Namespace]++
+		key := secret.Namespace + "/" + name
+		if releases[key] == nil {
+			releases[key] = &helmRelease{namespace: secret.Namespace, name: name}
+		}
+		releases[key].revisions++
+	}
+
+	ranked := make([]*helmRelease, 0, len(releases))
+	for _, release := range releases {
+		ranked = append(ranked, release)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].revisions != ranked[j].revisions {
+			return ranked[i].revisions > ranked[j].revisions
+		}
+		if ranked[i].namespace != ranked[j].namespace {
+			return ranked[i].namespace < ranked[j].namespace
+		}
+		return ranked[i].name < ranked[j].name
+	})
+
+	bloated := 0
+	for _, release := range ranked {
+		if release.revisions <= c.maxRevisions {
+			continue
+		}
+		bloated++
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityWarning,
+			Message:   fmt.Sprintf("Helm release %s/%s keeps %d revisions", release.namespace, release.name, release.revisions),
+			Namespace: release.namespace,
+			Details: []string{
+				fmt.Sprintf("Threshold: %d revisions", c.maxRevisions),
+				fmt.Sprintf("Helm release secrets in %s: %d", release.namespace, namespaces[release.namespace]),
+			},
+			Remediation: "Each revision is a Secret holding the full rendered manifest, which bloats etcd and slows Helm. Upgrade with --history-max to prune old revisions",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl get secrets -n %s -l owner=helm,name=%s", release.namespace, release.name),
+				fmt.Sprintf("helm history -n %s %s", release.namespace, release.name),
+			},
+		})
+	}
+
+	severity := probe.SeverityOK
+	if bloated > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Helm releases: %d releases with %d revision secrets across %d namespaces, %d above %d revisions", len(releases), total, len(namespaces), bloated, c.maxRevisions),
+	})
+
+	return result, nil
+}
@@ -0,0 +1,129 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type HPAStatus struct{}
+
+func NewHPAStatus() *HPAStatus {
+	return &HPAStatus{}
+}
+
+func (c *HPAStatus) Name() string {
+	return "hpa-status"
+}
+
+func (c *HPAStatus) Tier() int {
+	return 2
+}
+
+func (c *HPAStatus) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	hpas, err := client.AutoscalingV2().HorizontalPodAutoscalers("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list horizontal pod autoscalers: %w", err)
+	}
+
+	deployments, err := client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	statefulSets, err := client.AppsV1().StatefulSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+
+	targetReplicas := make(map[string]*int32)
+	for _, deploy := range deployments.Items {
+		targetReplicas["Deployment/"+deploy.Namespace+"/"+deploy.Name] = deploy.Spec.Replicas
+	}
+	for _, sts := range statefulSets.Items {
+		targetReplicas["StatefulSet/"+sts.Namespace+"/"+sts.Name] = sts.Spec.Replicas
+	}
+
+	conflicts := 0
+	for _, hpa := range hpas.Items {
+		ref := hpa.Spec.ScaleTargetRef
+		if ref.Kind != "Deployment" && ref.Kind != "StatefulSet" {
+			continue
+		}
+
+		replicas, found := targetReplicas[ref.Kind+"/"+hpa.Namespace+"/"+ref.Name]
+		if !found {
+			conflicts++
+			result.Results = append(result.Results, probe.Result{
+				CheckName:   c.Name(),
+				Severity:    probe.SeverityWarning,
+				Message:     fmt.Sprintf("HPA %s/%s targets missing %s %s", hpa.Namespace, hpa.Name, ref.Kind, ref.Name),
+				Namespace:   hpa.Namespace,
+				Remediation: "Fix the HPA scaleTargetRef or remove the orphaned HPA",
+			})
+			continue
+		}
+
+		if issue := c.replicaConflict(&hpa, replicas); issue != "" {
+			conflicts++
+			result.Results = append(result.Results, probe.Result{
+				CheckName: c.Name(),
+				Severity:  probe.SeverityWarning,
+				Message:   fmt.Sprintf("HPA %s/%s conflicts with %s %s replicas", hpa.Namespace, hpa.Name, ref.Kind, ref.Name),
+				Namespace: hpa.Namespace,
+				Details: []string{
+					issue,
+					fmt.Sprintf("Current replicas: %d, Desired replicas: %d", hpa.Status.CurrentReplicas, hpa.Status.DesiredReplicas),
+				},
+				Remediation: fmt.Sprintf("Remove spec.replicas from the %s manifest so the HPA owns the replica count", ref.Kind),
+			})
+		}
+	}
+
+	severity := probe.SeverityOK
+	if conflicts > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("HPAs: %d total, %d with conflicts", len(hpas.Items), conflicts),
+	})
+
+	return result, nil
+}
+
+func (c *HPAStatus) replicaConflict(hpa *autoscalingv2.HorizontalPodAutoscaler, replicas *int32) string {
+	specReplicas := int32(1)
+	if replicas != nil {
+		specReplicas = *replicas
+	}
+
+	if specReplicas == 0 {
+		return ""
+	}
+
+	minReplicas := int32(1)
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = *hpa.Spec.MinReplicas
+	}
+
+	if specReplicas < minReplicas {
+		return fmt.Sprintf("Spec replicas %d is below HPA minReplicas %d", specReplicas, minReplicas)
+	}
+	if specReplicas > hpa.Spec.MaxReplicas {
+		return fmt.Sprintf("Spec replicas %d is above HPA maxReplicas %d", specReplicas, hpa.Spec.MaxReplicas)
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type ImagePullPolicy struct {
+	systemNamespaces []string
+}
+
+func NewImagePullPolicy() *ImagePullPolicy {
+	return &ImagePullPolicy{
+		systemNamespaces: []string{"kube-system"},
+	}
+}
+
+func (c *ImagePullPolicy) Name() string {
+	return "image-pull-policy"
+}
+
+func (c *ImagePullPolicy) Tier() int {
+	return 5
+}
+
+func (c *ImagePullPolicy) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
+func (c *ImagePullPolicy) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	pods, err := listPods(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	checked := 0
+	flagged := 0
+	for _, pod := range pods.Items {
+		if containsString(c.systemNamespaces, pod.Namespace) {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		checked++
+
+		var details []string
+		for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+			for _, container := range containers {
+				if container.ImagePullPolicy == corev1.PullAlways && !pinnedByDigest(container.Image) {
+					details = append(details, fmt.Sprintf("Container %s: %s", container.Name, container.Image))
+				}
+			}
+		}
+		if len(details) == 0 {
+			continue
+		}
+
+		flagged++
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityWarning,
+			Message:   fmt.Sprintf("Pod %s/%s pulls mutable image tags with imagePullPolicy Always", pod.Namespace, pod.Name),
+			Namespace: pod.Namespace,
+			Details:   details,
+			Remediation: "Every container start re-resolves the tag, so a registry outage blocks restarts and a re-pushed tag silently changes what runs. " +
+				"Pin the image by digest (image@sha256:...), or use an immutable tag with imagePullPolicy IfNotPresent",
+		})
+	}
+
+	severity := probe.SeverityOK
+	if flagged > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Image pull policy: %d of %d pods pull mutable tags on every start", flagged, checked),
+	})
+
+	return result, nil
+}
+
+func pinnedByDigest(image string) bool {
+	return strings.Contains(image, "@")
+}
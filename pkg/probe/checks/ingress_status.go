@@ -3,6 +3,7 @@ package checks
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -48,9 +49,15 @@ func (c *IngressStatus) Run(ctx context.Context, client kubernetes.Interface) (*
 	withAddress := 0
 	withoutAddress := 0
 	withTLS := 0
+	implementationSpecific := 0
+	classless := []string{}
 
 	for _, ing := range ingresses.Items {
 
+		if !c.hasExplicitClass(&ing) {
+			classless = append(classless, fmt.Sprintf("%s/%s", ing.Namespace, ing.Name))
+		}
+
 		hasAddress := len(ing.Status.LoadBalancer.Ingress) > 0
 
 		if hasAddress {
@@ -61,6 +68,7 @@ func (c *IngressStatus) Run(ctx context.Context, client kubernetes.Interface) (*
 				CheckName:	c.Name(),
 				Severity:	probe.SeverityWarning,
 				Message:	fmt.Sprintf("Ingress %s/%s has no address assigned", ing.Namespace, ing.Name),
+				Namespace:	ing.Namespace,
 				Details: []string{
 					fmt.Sprintf("Class: %s", c.getIngressClass(&ing)),
 					fmt.Sprintf("Hosts: %v", c.getHosts(&ing)),
@@ -77,6 +85,7 @@ func (c *IngressStatus) Run(ctx context.Context, client kubernetes.Interface) (*
 						CheckName:	c.Name(),
 						Severity:	probe.SeverityWarning,
 						Message:	fmt.Sprintf("Ingress %s/%s has TLS without secret", ing.Namespace, ing.Name),
+						Namespace:	ing.Namespace,
 						Details: []string{
 							fmt.Sprintf("Hosts: %v", tls.Hosts),
 						},
@@ -86,32 +95,68 @@ func (c *IngressStatus) Run(ctx context.Context, client kubernetes.Interface) (*
 			}
 		}
 
+		if paths := c.implementationSpecificPaths(&ing); len(paths) > 0 {
+			implementationSpecific++
+			result.Results = append(result.Results, probe.Result{
+				CheckName:	c.Name(),
+				Severity:	probe.SeverityWarning,
+				Message:	fmt.Sprintf("Ingress %s/%s has paths with pathType ImplementationSpecific", ing.Namespace, ing.Name),
+				Namespace:	ing.Namespace,
+				Details:	paths,
+				Remediation:	"ImplementationSpecific paths are matched however the ingress controller chooses, so routing can change when the controller or its version changes. Set pathType to Prefix or Exact on every path",
+				RemediationCommands: []string{
+					fmt.Sprintf("kubectl edit ingress -n %s %s", ing.Namespace, ing.Name),
+				},
+			})
+		}
+
 		if ing.Spec.DefaultBackend == nil && len(ing.Spec.Rules) == 0 {
 			result.Results = append(result.Results, probe.Result{
 				CheckName:	c.Name(),
 				Severity:	probe.SeverityWarning,
 				Message:	fmt.Sprintf("Ingress %s/%s has no rules or default backend", ing.Namespace, ing.Name),
+				Namespace:	ing.Namespace,
 				Remediation:	"Configure ingress rules or default backend",
 			})
 		}
 	}
 
+	ambiguous := false
 	ingressClasses, err := client.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
 	if err == nil && len(ingressClasses.Items) > 0 {
 		classes := make([]string, 0, len(ingressClasses.Items))
 		for _, ic := range ingressClasses.Items {
-			classes = append(classes, ic.Name)
+			name := ic.Name
+			if ic.Annotations[networkingv1.AnnotationIsDefaultIngressClass] == "true" {
+				name += " (default)"
+			}
+			classes = append(classes, name)
 		}
 		result.Results = append(result.Results, probe.Result{
 			CheckName:	c.Name(),
-			Severity:	probe.SeverityOK,
+			Severity:	probe.SeverityInfo,
 			Message:	fmt.Sprintf("%d ingress classes available", len(classes)),
 			Details:	classes,
 		})
+
+		if len(classes) > 1 && len(classless) > 0 {
+			ambiguous = true
+			details := []string{fmt.Sprintf("Ingress classes: %s", strings.Join(classes, ", "))}
+			for _, ref := range classless {
+				details = append(details, fmt.Sprintf("Unassigned: %s", ref))
+			}
+			result.Results = append(result.Results, probe.Result{
+				CheckName:	c.Name(),
+				Severity:	probe.SeverityWarning,
+				Message:	fmt.Sprintf("%d ingresses have no class while %d ingress classes exist", len(classless), len(classes)),
+				Details:	details,
+				Remediation:	"Multiple controllers may claim class-less ingresses. Set spec.ingressClassName on each ingress",
+			})
+		}
 	}
 
 	severity := probe.SeverityOK
-	if withoutAddress > 0 {
+	if withoutAddress > 0 || implementationSpecific > 0 || ambiguous {
 		severity = probe.SeverityWarning
 	}
 
@@ -137,6 +182,36 @@ func (c *IngressStatus) getIngressClass(ing *networkingv1.Ingress) string {
 	return "default"
 }
 
+func (c *IngressStatus) hasExplicitClass(ing *networkingv1.Ingress) bool {
+	if ing.Spec.IngressClassName != nil && *ing.Spec.IngressClassName != "" {
+		return true
+	}
+	return ing.Annotations["kubernetes.io/ingress.class"] != ""
+}
+
+func (c *IngressStatus) implementationSpecificPaths(ing *networkingv1.Ingress) []string {
+	var paths []string
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		host := rule.Host
+		if host == "" {
+			host = "*"
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.PathType != nil && *path.PathType == networkingv1.PathTypeImplementationSpecific {
+				pathName := path.Path
+				if pathName == "" {
+					pathName = "/"
+				}
+				paths = append(paths, fmt.Sprintf("Host %s, path %s", host, pathName))
+			}
+		}
+	}
+	return paths
+}
+
 func (c *IngressStatus) getHosts(ing *networkingv1.Ingress) []string {
 	hosts := make([]string, 0)
 	for _, rule := range ing.Spec.Rules {
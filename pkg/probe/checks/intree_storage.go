@@ -0,0 +1,117 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var removedInTreeProvisioners = map[string]string{
+	"kubernetes.io/aws-ebs":         "ebs.csi.aws.com",
+	"kubernetes.io/gce-pd":          "pd.csi.storage.gke.io",
+	"kubernetes.io/azure-disk":      "disk.csi.azure.com",
+	"kubernetes.io/azure-file":      "file.csi.azure.com",
+	"kubernetes.io/cinder":          "cinder.csi.openstack.org",
+	"kubernetes.io/vsphere-volume":  "csi.vsphere.vmware.com",
+	"kubernetes.io/portworx-volume": "pxd.portworx.com",
+	"kubernetes.io/rbd":             "rbd.csi.ceph.com",
+	"kubernetes.io/cephfs":          "cephfs.csi.ceph.com",
+	"kubernetes.io/glusterfs":       "",
+	"kubernetes.io/scaleio":         "",
+	"kubernetes.io/storageos":       "",
+	"kubernetes.io/quobyte":         "",
+	"kubernetes.io/flocker":         "",
+}
+
+type InTreeStorage struct{}
+
+func NewInTreeStorage() *InTreeStorage {
+	return &InTreeStorage{}
+}
+
+func (c *InTreeStorage) Name() string {
+	return "intree-storage"
+}
+
+func (c *InTreeStorage) Tier() int {
+	return 3
+}
+
+func (c *InTreeStorage) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	storageClasses, err := client.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage classes: %w", err)
+	}
+
+	pvcs, err := client.CoreV1().PersistentVolumeClaims("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PVCs: %w", err)
+	}
+
+	claimsByClass := make(map[string][]string)
+	for _, pvc := range pvcs.Items {
+		if pvc.Spec.StorageClassName == nil {
+			continue
+		}
+		className := *pvc.Spec.StorageClassName
+		claimsByClass[className] = append(claimsByClass[className], fmt.Sprintf("%s/%s", pvc.Namespace, pvc.Name))
+	}
+
+	inTree := 0
+	for _, sc := range storageClasses.Items {
+		csiDriver, ok := removedInTreeProvisioners[sc.Provisioner]
+		if !ok {
+			continue
+		}
+		inTree++
+
+		claims := claimsByClass[sc.Name]
+		sort.Strings(claims)
+
+		details := []string{fmt.Sprintf("Provisioner: %s", sc.Provisioner)}
+		remediation := "This in-tree plugin has no CSI replacement. Migrate the data to a StorageClass backed by a supported CSI driver before upgrading"
+		if csiDriver != "" {
+			details = append(details, fmt.Sprintf("CSI replacement: %s", csiDriver))
+			remediation = fmt.Sprintf("Install the %s CSI driver and create a StorageClass that uses it. Existing volumes keep working through CSI migration once the driver is installed", csiDriver)
+		}
+		if len(claims) > 0 {
+			details = append(details, fmt.Sprintf("PVCs using this class: %d", len(claims)))
+			details = append(details, claims...)
+		}
+
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("StorageClass %s uses removed in-tree provisioner %s", sc.Name, sc.Provisioner),
+			Details:     details,
+			Remediation: remediation,
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl describe storageclass %s", sc.Name),
+				"kubectl get csidrivers",
+			},
+		})
+	}
+
+	severity := probe.SeverityOK
+	if inTree > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("In-tree storage: %d of %d StorageClasses use removed in-tree provisioners", inTree, len(storageClasses.Items)),
+	})
+
+	return result, nil
+}
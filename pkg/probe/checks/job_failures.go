@@ -6,15 +6,20 @@ import (
 	"time"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-type JobFailures struct{}
+type JobFailures struct {
+	runningAgeThreshold time.Duration
+}
 
 func NewJobFailures() *JobFailures {
-	return &JobFailures{}
+	return &JobFailures{
+		runningAgeThreshold: 24 * time.Hour,
+	}
 }
 
 func (c *JobFailures) Name() string {
@@ -25,6 +30,10 @@ func (c *JobFailures) Tier() int {
 	return 2
 }
 
+func (c *JobFailures) Configure(cfg *config.Config) {
+	c.runningAgeThreshold = time.Duration(cfg.GetThreshold("job_running_age_hours")) * time.Hour
+}
+
 func (c *JobFailures) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
@@ -53,16 +62,32 @@ func (c *JobFailures) Run(ctx context.Context, client kubernetes.Interface) (*pr
 
 			if job.Status.StartTime != nil {
 				runTime := time.Since(job.Status.StartTime.Time)
-				if runTime > 1*time.Hour {
-					result.Results = append(result.Results, probe.Result{
-						CheckName:	c.Name(),
-						Severity:	probe.SeverityWarning,
-						Message:	fmt.Sprintf("Job %s/%s has been running for %s", job.Namespace, job.Name, formatDuration(runTime)),
-						Details: []string{
-							fmt.Sprintf("Active pods: %d", job.Status.Active),
-						},
-						Remediation:	"Check job pod logs for issues",
-					})
+				if runTime > c.runningAgeThreshold {
+					if job.Spec.ActiveDeadlineSeconds == nil {
+						result.Results = append(result.Results, probe.Result{
+							CheckName:	c.Name(),
+							Severity:	probe.SeverityWarning,
+							Message:	fmt.Sprintf("Job %s/%s has no deadline and has been running for %s", job.Namespace, job.Name, formatDuration(runTime)),
+							Namespace:	job.Namespace,
+							Details: []string{
+								fmt.Sprintf("Active pods: %d", job.Status.Active),
+								"Jobs without activeDeadlineSeconds can run indefinitely; backoffLimit only caps retries of failed pods",
+							},
+							Remediation:		"Set activeDeadlineSeconds on the job spec, or delete it",
+							RemediationCommands:	[]string{fmt.Sprintf("kubectl delete job -n %s %s", job.Namespace, job.Name)},
+						})
+					} else {
+						result.Results = append(result.Results, probe.Result{
+							CheckName:	c.Name(),
+							Severity:	probe.SeverityWarning,
+							Message:	fmt.Sprintf("Job %s/%s has been running for %s", job.Namespace, job.Name, formatDuration(runTime)),
+							Namespace:	job.Namespace,
+							Details: []string{
+								fmt.Sprintf("Active pods: %d", job.Status.Active),
+							},
+							Remediation:	"Check job pod logs for issues",
+						})
+					}
 				}
 			}
 		}
@@ -97,11 +122,13 @@ func (c *JobFailures) Run(ctx context.Context, client kubernetes.Interface) (*pr
 				}
 
 				result.Results = append(result.Results, probe.Result{
-					CheckName:	c.Name(),
-					Severity:	probe.SeverityWarning,
-					Message:	fmt.Sprintf("Job %s/%s has failed", job.Namespace, job.Name),
-					Details:	details,
-					Remediation:	fmt.Sprintf("Check job pods: kubectl get pods -n %s -l job-name=%s", job.Namespace, job.Name),
+					CheckName:		c.Name(),
+					Severity:		probe.SeverityWarning,
+					Message:		fmt.Sprintf("Job %s/%s has failed", job.Namespace, job.Name),
+					Namespace:		job.Namespace,
+					Details:		details,
+					Remediation:		"Check job pods",
+					RemediationCommands:	[]string{fmt.Sprintf("kubectl get pods -n %s -l job-name=%s", job.Namespace, job.Name)},
 				})
 			}
 		}
@@ -124,6 +151,7 @@ func (c *JobFailures) Run(ctx context.Context, client kubernetes.Interface) (*pr
 						CheckName:	c.Name(),
 						Severity:	probe.SeverityWarning,
 						Message:	fmt.Sprintf("CronJob %s/%s last ran %s ago", cj.Namespace, cj.Name, formatDuration(age)),
+						Namespace:	cj.Namespace,
 						Details: []string{
 							fmt.Sprintf("Schedule: %s", cj.Spec.Schedule),
 							fmt.Sprintf("Active jobs: %d", len(cj.Status.Active)),
@@ -0,0 +1,108 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+)
+
+var (
+	secretsResource    = corev1.SchemeGroupVersion.WithResource("secrets")
+	configMapsResource = corev1.SchemeGroupVersion.WithResource("configmaps")
+)
+
+var errNoMetadataClient = errors.New("no metadata client is configured")
+
+func listPods(ctx context.Context, client kubernetes.Interface, namespace string) (*corev1.PodList, error) {
+	items, meta, err := probe.ListAll(ctx, "pods", func(opts metav1.ListOptions) ([]corev1.Pod, metav1.ListMeta, error) {
+		list, err := client.CoreV1().Pods(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.PodList{ListMeta: meta, Items: items}, nil
+}
+
+func listEvents(ctx context.Context, client kubernetes.Interface, namespace string) (*corev1.EventList, error) {
+	items, meta, err := probe.ListAll(ctx, "events", func(opts metav1.ListOptions) ([]corev1.Event, metav1.ListMeta, error) {
+		list, err := client.CoreV1().Events(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.EventList{ListMeta: meta, Items: items}, nil
+}
+
+func listPodsIn(ctx context.Context, client kubernetes.Interface, namespaces []string) (*corev1.PodList, error) {
+	pods := &corev1.PodList{}
+	for _, namespace := range namespaces {
+		list, err := listPods(ctx, client, namespace)
+		if err != nil {
+			return nil, err
+		}
+		pods.Items = append(pods.Items, list.Items...)
+		if probe.IsSampled(list.ListMeta) {
+			pods.ListMeta = list.ListMeta
+		}
+	}
+	return pods, nil
+}
+
+func listMetadata(ctx context.Context, client kubernetes.Interface, metadataClient metadata.Interface, resource schema.GroupVersionResource, opts metav1.ListOptions) (*metav1.PartialObjectMetadataList, error) {
+	if metadataClient != nil {
+		return metadataClient.Resource(resource).Namespace(metav1.NamespaceAll).List(ctx, opts)
+	}
+
+	selector := fields.Everything()
+	if opts.FieldSelector != "" {
+		parsed, err := fields.ParseSelector(opts.FieldSelector)
+		if err != nil {
+			return nil, err
+		}
+		selector = parsed
+	}
+
+	list := &metav1.PartialObjectMetadataList{}
+	switch resource {
+	case configMapsResource:
+		configMaps, err := client.CoreV1().ConfigMaps(metav1.NamespaceAll).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, cm := range configMaps.Items {
+			if selector.Matches(fields.Set{"metadata.name": cm.Name, "metadata.namespace": cm.Namespace}) {
+				list.Items = append(list.Items, metav1.PartialObjectMetadata{ObjectMeta: cm.ObjectMeta})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("listing %s metadata: %w", resource.Resource, errNoMetadataClient)
+	}
+	return list, nil
+}
+
+func secretsUnavailable(err error) (string, bool) {
+	switch {
+	case apierrors.IsForbidden(err):
+		return "listing secrets is not permitted", true
+	case errors.Is(err, errNoMetadataClient):
+		return "secrets are only listed through a metadata client, and none is configured", true
+	}
+	return "", false
+}
@@ -0,0 +1,100 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	mirrorPodAnnotation  = "kubernetes.io/config.mirror"
+	nakedPodsMaxExamples = 10
+)
+
+type NakedPods struct {
+	systemNamespaces []string
+}
+
+func NewNakedPods() *NakedPods {
+	return &NakedPods{
+		systemNamespaces: []string{"kube-system"},
+	}
+}
+
+func (c *NakedPods) Name() string {
+	return "naked-pods"
+}
+
+func (c *NakedPods) Tier() int {
+	return 2
+}
+
+func (c *NakedPods) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
+func (c *NakedPods) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	pods, err := listPods(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	naked := make(map[string][]string)
+	total := 0
+	checked := 0
+
+	for _, pod := range pods.Items {
+		if containsString(c.systemNamespaces, pod.Namespace) {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+			continue
+		}
+		checked++
+
+		if len(pod.OwnerReferences) == 0 {
+			naked[pod.Namespace] = append(naked[pod.Namespace], fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+			total++
+		}
+	}
+
+	for _, namespace := range sortedKeys(naked) {
+		pods := naked[namespace]
+		sort.Strings(pods)
+		details := pods
+		if len(details) > nakedPodsMaxExamples {
+			details = append(append([]string{}, pods[:nakedPodsMaxExamples]...), fmt.Sprintf("... and %d more", len(pods)-nakedPodsMaxExamples))
+		}
+
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("%d pods in namespace %s are not managed by a controller", len(pods), namespace),
+			Namespace:   namespace,
+			Details:     details,
+			Remediation: "Pods without an owner are not recreated if their node fails. Run them under a Deployment, StatefulSet, DaemonSet or Job",
+		})
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  probe.SeverityOK,
+		Message:   fmt.Sprintf("Naked pods: %d of %d pods in user namespaces have no owner, across %d namespaces", total, checked, len(naked)),
+	})
+
+	return result, nil
+}
@@ -3,16 +3,26 @@ package checks
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 )
 
-type NetworkPolicies struct{}
+type NetworkPolicies struct {
+	systemNamespaces []string
+}
 
 func NewNetworkPolicies() *NetworkPolicies {
-	return &NetworkPolicies{}
+	return &NetworkPolicies{
+		systemNamespaces: []string{"kube-system"},
+	}
 }
 
 func (c *NetworkPolicies) Name() string {
@@ -23,6 +33,10 @@ func (c *NetworkPolicies) Tier() int {
 	return 4
 }
 
+func (c *NetworkPolicies) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
 func (c *NetworkPolicies) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
@@ -40,9 +54,24 @@ func (c *NetworkPolicies) Run(ctx context.Context, client kubernetes.Interface)
 		return nil, fmt.Errorf("failed to list namespaces: %w", err)
 	}
 
+	pods, err := listPods(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
 	policyPerNS := make(map[string]int)
+	policiesByNS := make(map[string][]networkingv1.NetworkPolicy)
 	for _, policy := range policies.Items {
 		policyPerNS[policy.Namespace]++
+		policiesByNS[policy.Namespace] = append(policiesByNS[policy.Namespace], policy)
+	}
+
+	podsByNS := make(map[string][]corev1.Pod)
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podsByNS[pod.Namespace] = append(podsByNS[pod.Namespace], pod)
 	}
 
 	nsWithPolicies := 0
@@ -51,7 +80,7 @@ func (c *NetworkPolicies) Run(ctx context.Context, client kubernetes.Interface)
 
 	for _, ns := range namespaces.Items {
 
-		if ns.Name == "kube-system" || ns.Name == "kube-public" || ns.Name == "kube-node-lease" {
+		if containsString(c.systemNamespaces, ns.Name) {
 			systemNS++
 			continue
 		}
@@ -61,6 +90,27 @@ func (c *NetworkPolicies) Run(ctx context.Context, client kubernetes.Interface)
 		} else {
 			nsWithoutPolicies++
 		}
+
+		for _, policyType := range []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress} {
+			isolated, denyPolicies := c.findIsolatedPods(policiesByNS[ns.Name], podsByNS[ns.Name], policyType)
+			if len(isolated) == 0 {
+				continue
+			}
+
+			direction := strings.ToLower(string(policyType))
+			details := make([]string, 0, len(isolated)+1)
+			details = append(details, fmt.Sprintf("Isolating policies: %s", strings.Join(denyPolicies, ", ")))
+			details = append(details, isolated...)
+
+			result.Results = append(result.Results, probe.Result{
+				CheckName:	c.Name(),
+				Severity:	probe.SeverityWarning,
+				Message:	fmt.Sprintf("%d pods in namespace %s are cut off from all %s traffic", len(isolated), ns.Name, direction),
+				Namespace:	ns.Name,
+				Details:	details,
+				Remediation:	fmt.Sprintf("Add a NetworkPolicy allowing the required %s traffic for these pods, or narrow the podSelector of the isolating policy", direction),
+			})
+		}
 	}
 
 	if len(policies.Items) == 0 {
@@ -116,3 +166,71 @@ func (c *NetworkPolicies) Run(ctx context.Context, client kubernetes.Interface)
 
 	return result, nil
 }
+
+func (c *NetworkPolicies) findIsolatedPods(policies []networkingv1.NetworkPolicy, pods []corev1.Pod, policyType networkingv1.PolicyType) ([]string, []string) {
+	isolated := []string{}
+	denyPolicySet := make(map[string]struct{})
+
+	for _, pod := range pods {
+		selected := false
+		allowed := false
+		var selectingDeny []string
+
+		for _, policy := range policies {
+			if !policyHasType(&policy, policyType) || !policySelectsPod(&policy, &pod) {
+				continue
+			}
+			selected = true
+			if policyHasRules(&policy, policyType) {
+				allowed = true
+				break
+			}
+			selectingDeny = append(selectingDeny, policy.Name)
+		}
+
+		if selected && !allowed {
+			isolated = append(isolated, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+			for _, name := range selectingDeny {
+				denyPolicySet[name] = struct{}{}
+			}
+		}
+	}
+
+	denyPolicies := make([]string, 0, len(denyPolicySet))
+	for name := range denyPolicySet {
+		denyPolicies = append(denyPolicies, name)
+	}
+	sort.Strings(denyPolicies)
+
+	return isolated, denyPolicies
+}
+
+func policyHasType(policy *networkingv1.NetworkPolicy, policyType networkingv1.PolicyType) bool {
+	if len(policy.Spec.PolicyTypes) == 0 {
+		if policyType == networkingv1.PolicyTypeIngress {
+			return true
+		}
+		return len(policy.Spec.Egress) > 0
+	}
+	for _, t := range policy.Spec.PolicyTypes {
+		if t == policyType {
+			return true
+		}
+	}
+	return false
+}
+
+func policyHasRules(policy *networkingv1.NetworkPolicy, policyType networkingv1.PolicyType) bool {
+	if policyType == networkingv1.PolicyTypeIngress {
+		return len(policy.Spec.Ingress) > 0
+	}
+	return len(policy.Spec.Egress) > 0
+}
+
+func policySelectsPod(policy *networkingv1.NetworkPolicy, pod *corev1.Pod) bool {
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(pod.Labels))
+}
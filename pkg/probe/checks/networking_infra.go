@@ -0,0 +1,165 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type NetworkingInfra struct {
+	kubeProxyDaemonSets []string
+	cniDaemonSets       []string
+}
+
+func NewNetworkingInfra() *NetworkingInfra {
+	defaults := config.DefaultConfig()
+	return &NetworkingInfra{
+		kubeProxyDaemonSets: defaults.GetDaemonSets("kube_proxy_daemonsets"),
+		cniDaemonSets:       defaults.GetDaemonSets("cni_daemonsets"),
+	}
+}
+
+func (c *NetworkingInfra) Name() string {
+	return "networking-infra"
+}
+
+func (c *NetworkingInfra) Tier() int {
+	return 1
+}
+
+func (c *NetworkingInfra) Configure(cfg *config.Config) {
+	c.kubeProxyDaemonSets = cfg.GetDaemonSets("kube_proxy_daemonsets")
+	c.cniDaemonSets = cfg.GetDaemonSets("cni_daemonsets")
+}
+
+func (c *NetworkingInfra) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	nodeCount := int32(len(nodes.Items))
+
+	healthy := []string{}
+	problems := 0
+
+	components := []struct {
+		name       string
+		daemonSets []string
+		missing    string
+	}{
+		{"kube-proxy", c.kubeProxyDaemonSets, "This may be normal if the CNI replaces kube-proxy or it runs as static pods"},
+		{"CNI", c.cniDaemonSets, "This may be normal if the CNI runs outside a DaemonSet or under a different name"},
+	}
+
+	for _, component := range components {
+		found, err := c.findDaemonSets(ctx, client, component.daemonSets)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(found) == 0 {
+			problems++
+			result.Results = append(result.Results, probe.Result{
+				CheckName: c.Name(),
+				Severity:  probe.SeverityWarning,
+				Message:   fmt.Sprintf("No %s DaemonSet found", component.name),
+				Details: []string{
+					fmt.Sprintf("Looked for: %s", strings.Join(component.daemonSets, ", ")),
+					component.missing,
+				},
+				Remediation: "List the DaemonSet names used by your distribution under networking in .probe/config.yaml",
+			})
+			continue
+		}
+
+		for _, ds := range found {
+			ref := fmt.Sprintf("%s/%s", ds.Namespace, ds.Name)
+			desired := ds.Status.DesiredNumberScheduled
+			ready := ds.Status.NumberReady
+
+			if ready < desired {
+				problems++
+				result.Results = append(result.Results, probe.Result{
+					CheckName: c.Name(),
+					Severity:  probe.SeverityCritical,
+					Message:   fmt.Sprintf("%s DaemonSet %s has %d/%d pods ready", component.name, ref, ready, desired),
+					Namespace: ds.Namespace,
+					Details: []string{
+						fmt.Sprintf("Up to date: %d, available: %d, unavailable: %d", ds.Status.UpdatedNumberScheduled, ds.Status.NumberAvailable, ds.Status.NumberUnavailable),
+						"Pods on nodes without a ready networking agent cannot reach services or other pods",
+					},
+					Remediation: "Check the DaemonSet rollout and the failing pods on the affected nodes",
+					RemediationCommands: []string{
+						fmt.Sprintf("kubectl rollout status daemonset/%s -n %s", ds.Name, ds.Namespace),
+						fmt.Sprintf("kubectl describe daemonset %s -n %s", ds.Name, ds.Namespace),
+					},
+				})
+				continue
+			}
+
+			if desired < nodeCount {
+				problems++
+				result.Results = append(result.Results, probe.Result{
+					CheckName: c.Name(),
+					Severity:  probe.SeverityWarning,
+					Message:   fmt.Sprintf("%s DaemonSet %s is scheduled on %d of %d nodes", component.name, ref, desired, nodeCount),
+					Namespace: ds.Namespace,
+					Details: []string{
+						"Nodes without a pod from this DaemonSet may have no pod networking",
+					},
+					Remediation: "Check the DaemonSet nodeSelector, affinity and tolerations against node labels and taints",
+					RemediationCommands: []string{
+						fmt.Sprintf("kubectl get pods -n %s -o wide", ds.Namespace),
+					},
+				})
+				continue
+			}
+
+			healthy = append(healthy, fmt.Sprintf("%s: %s (%d/%d ready)", component.name, ref, ready, desired))
+		}
+	}
+
+	if problems == 0 {
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityOK,
+			Message:   "Networking DaemonSets are running on all nodes",
+			Details:   healthy,
+		})
+	}
+
+	return result, nil
+}
+
+func (c *NetworkingInfra) findDaemonSets(ctx context.Context, client kubernetes.Interface, refs []string) ([]*appsv1.DaemonSet, error) {
+	var found []*appsv1.DaemonSet
+	for _, ref := range refs {
+		namespace, name, ok := strings.Cut(ref, "/")
+		if !ok {
+			namespace, name = "kube-system", ref
+		}
+
+		ds, err := client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get daemonset %s/%s: %w", namespace, name, err)
+		}
+		found = append(found, ds)
+	}
+	return found, nil
+}
@@ -5,15 +5,21 @@ import (
 	"fmt"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-type NodeCapacity struct{}
+type NodeCapacity struct {
+	minAllocatablePercent int
+}
 
 func NewNodeCapacity() *NodeCapacity {
-	return &NodeCapacity{}
+	return &NodeCapacity{
+		minAllocatablePercent: 80,
+	}
 }
 
 func (c *NodeCapacity) Name() string {
@@ -24,6 +30,10 @@ func (c *NodeCapacity) Tier() int {
 	return 3
 }
 
+func (c *NodeCapacity) Configure(cfg *config.Config) {
+	c.minAllocatablePercent = cfg.GetThreshold("node_allocatable_min_percent")
+}
+
 func (c *NodeCapacity) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
@@ -36,7 +46,7 @@ func (c *NodeCapacity) Run(ctx context.Context, client kubernetes.Interface) (*p
 		return nil, fmt.Errorf("failed to list nodes: %w", err)
 	}
 
-	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	pods, err := listPods(ctx, client, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
@@ -105,16 +115,48 @@ func (c *NodeCapacity) Run(ctx context.Context, client kubernetes.Interface) (*p
 			})
 		}
 
+		cpuReserved := reservedPercent(node.Status.Capacity[corev1.ResourceCPU], node.Status.Allocatable[corev1.ResourceCPU])
+		memReserved := reservedPercent(node.Status.Capacity[corev1.ResourceMemory], node.Status.Allocatable[corev1.ResourceMemory])
+		minAllocatable := float64(c.minAllocatablePercent)
+		if 100-cpuReserved < minAllocatable || 100-memReserved < minAllocatable {
+			cpuCapacity := node.Status.Capacity[corev1.ResourceCPU]
+			memCapacity := node.Status.Capacity[corev1.ResourceMemory]
+			result.Results = append(result.Results, probe.Result{
+				CheckName:	c.Name(),
+				Severity:	probe.SeverityWarning,
+				Message:	fmt.Sprintf("Node %s reserves a large share of its capacity for the system", node.Name),
+				Details: []string{
+					fmt.Sprintf("CPU: %dm of %dm allocatable (%.1f%% reserved)", usage.cpuCapacity, cpuCapacity.MilliValue(), cpuReserved),
+					fmt.Sprintf("Memory: %s of %s allocatable (%.1f%% reserved)", formatBytes(usage.memoryCapacity), formatBytes(memCapacity.Value()), memReserved),
+					fmt.Sprintf("Threshold: allocatable below %d%% of capacity", c.minAllocatablePercent),
+				},
+				Remediation:	"Capacity minus allocatable is held back by the kubelet's system-reserved, kube-reserved and eviction thresholds. Check those kubelet settings; an oversized reservation leaves less room for workloads than the node size suggests",
+				RemediationCommands: []string{
+					fmt.Sprintf("kubectl get --raw /api/v1/nodes/%s/proxy/configz", node.Name),
+				},
+			})
+		}
+
 		result.Results = append(result.Results, probe.Result{
 			CheckName:	c.Name(),
 			Severity:	probe.SeverityOK,
-			Message:	fmt.Sprintf("Node %s: CPU %.0f%%, Memory %.0f%%, Pods %d/%d", node.Name, cpuPercent, memPercent, usage.podCount, usage.podCapacity),
+			Message:	fmt.Sprintf("Node %s: CPU %.0f%%, Memory %.0f%%, Pods %d/%d, Reserved CPU %.0f%%, Memory %.0f%%", node.Name, cpuPercent, memPercent, usage.podCount, usage.podCapacity, cpuReserved, memReserved),
 		})
 	}
 
 	return result, nil
 }
 
+func reservedPercent(capacity, allocatable resource.Quantity) float64 {
+	if capacity.IsZero() {
+		return 0
+	}
+	if allocatable.IsZero() {
+		return 100
+	}
+	return float64(capacity.MilliValue()-allocatable.MilliValue()) / float64(capacity.MilliValue()) * 100
+}
+
 type resourceUsage struct {
 	cpuCapacity	int64
 	cpuRequested	int64
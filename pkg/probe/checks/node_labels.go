@@ -0,0 +1,106 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const nodeLabelsMaxExamples = 10
+
+var defaultRequiredNodeLabels = []string{
+	"topology.kubernetes.io/zone",
+	"topology.kubernetes.io/region",
+	"node.kubernetes.io/instance-type",
+}
+
+type NodeLabels struct {
+	required []string
+}
+
+func NewNodeLabels() *NodeLabels {
+	return &NodeLabels{
+		required: defaultRequiredNodeLabels,
+	}
+}
+
+func (c *NodeLabels) Name() string {
+	return "node-labels"
+}
+
+func (c *NodeLabels) Tier() int {
+	return 3
+}
+
+func (c *NodeLabels) Configure(cfg *config.Config) {
+	if cfg.RequiredNodeLabels != nil {
+		c.required = cfg.RequiredNodeLabels
+	}
+}
+
+func (c *NodeLabels) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	missingByLabel := make(map[string][]string)
+	incomplete := make(map[string]bool)
+	for _, node := range nodes.Items {
+		for _, label := range c.required {
+			if _, ok := node.Labels[label]; ok {
+				continue
+			}
+			missingByLabel[label] = append(missingByLabel[label], node.Name)
+			incomplete[node.Name] = true
+		}
+	}
+
+	for _, label := range c.required {
+		missing := missingByLabel[label]
+		if len(missing) == 0 {
+			continue
+		}
+		sort.Strings(missing)
+
+		details := missing
+		if len(details) > nodeLabelsMaxExamples {
+			details = append(append([]string{}, missing[:nodeLabelsMaxExamples]...), fmt.Sprintf("... and %d more", len(missing)-nodeLabelsMaxExamples))
+		}
+
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("%d of %d nodes are missing label %s", len(missing), len(nodes.Items), label),
+			Details:     details,
+			Remediation: "Topology spread constraints and anti-affinity rules keyed on this label ignore or cannot place pods on unlabeled nodes. Fix the cloud provider or kubelet --node-labels configuration, or adjust required_node_labels if the label is not used",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl get nodes -L %s", label),
+			},
+		})
+	}
+
+	severity := probe.SeverityOK
+	if len(incomplete) > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Node labels: %d of %d nodes missing required labels", len(incomplete), len(nodes.Items)),
+	})
+
+	return result, nil
+}
@@ -0,0 +1,120 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var builtinEOLOSPatterns = []string{
+	`^[23]\.\d+\.`,
+	`CentOS Linux [678]\b`,
+	`CentOS Stream 8\b`,
+	`Ubuntu (14|16|18)\.04`,
+	`Debian GNU/Linux (8|9|10) `,
+	`Red Hat Enterprise Linux.* [67]\.`,
+	`Amazon Linux AMI`,
+	`Windows Server 2016`,
+}
+
+type NodeOSEOL struct {
+	patterns []string
+}
+
+func NewNodeOSEOL() *NodeOSEOL {
+	return &NodeOSEOL{
+		patterns: builtinEOLOSPatterns,
+	}
+}
+
+func (c *NodeOSEOL) Name() string {
+	return "node-os-eol"
+}
+
+func (c *NodeOSEOL) Tier() int {
+	return 5
+}
+
+func (c *NodeOSEOL) Configure(cfg *config.Config) {
+	c.patterns = append(append([]string{}, builtinEOLOSPatterns...), cfg.EOLOSPatterns...)
+}
+
+func (c *NodeOSEOL) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	var compiled []*regexp.Regexp
+	for _, pattern := range c.patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			result.Results = append(result.Results, probe.Result{
+				CheckName:   c.Name(),
+				Severity:    probe.SeverityWarning,
+				Message:     fmt.Sprintf("Invalid eol_os_patterns entry %q", pattern),
+				Details:     []string{err.Error()},
+				Remediation: "Fix the regular expression in .probe/config.yaml",
+			})
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	eolNodes := make(map[string][]string)
+	for _, node := range nodes.Items {
+		info := node.Status.NodeInfo
+		for _, value := range []string{info.OSImage, info.KernelVersion} {
+			if value == "" || !matchesAny(compiled, value) {
+				continue
+			}
+			eolNodes[value] = append(eolNodes[value], node.Name)
+		}
+	}
+
+	for _, version := range sortedKeys(eolNodes) {
+		names := eolNodes[version]
+		sort.Strings(names)
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("%d nodes run end-of-life OS or kernel %q", len(names), version),
+			Details:     names,
+			Remediation: "End-of-life releases no longer receive security fixes. Upgrade the node image or migrate the nodes to a supported OS",
+		})
+	}
+
+	severity := probe.SeverityOK
+	if len(eolNodes) > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Node OS support: %d nodes checked against %d end-of-life patterns", len(nodes.Items), len(compiled)),
+	})
+
+	return result, nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, value string) bool {
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const nodePinnedPodsMaxExamples = 10
+
+type NodePinnedPods struct {
+	systemNamespaces []string
+}
+
+func NewNodePinnedPods() *NodePinnedPods {
+	return &NodePinnedPods{
+		systemNamespaces: []string{"kube-system"},
+	}
+}
+
+func (c *NodePinnedPods) Name() string {
+	return "node-pinned-pods"
+}
+
+func (c *NodePinnedPods) Tier() int {
+	return 2
+}
+
+func (c *NodePinnedPods) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
+func (c *NodePinnedPods) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	pods, err := listPods(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	pinned := make(map[string][]string)
+	total := 0
+	checked := 0
+
+	for _, pod := range pods.Items {
+		if containsString(c.systemNamespaces, pod.Namespace) {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+			continue
+		}
+		checked++
+
+		if pod.Spec.NodeName == "" || ownedByDaemonSet(&pod) || !nodeNameSetByClient(&pod) {
+			continue
+		}
+		pinned[pod.Namespace] = append(pinned[pod.Namespace], fmt.Sprintf("%s/%s (node: %s)", pod.Namespace, pod.Name, pod.Spec.NodeName))
+		total++
+	}
+
+	for _, namespace := range sortedKeys(pinned) {
+		pods := pinned[namespace]
+		sort.Strings(pods)
+		details := pods
+		if len(details) > nodePinnedPodsMaxExamples {
+			details = append(append([]string{}, pods[:nodePinnedPodsMaxExamples]...), fmt.Sprintf("... and %d more", len(pods)-nodePinnedPodsMaxExamples))
+		}
+
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("%d pods in namespace %s are pinned to a node with spec.nodeName", len(pods), namespace),
+			Namespace:   namespace,
+			Details:     details,
+			Remediation: "Pods with an explicit nodeName bypass the scheduler, ignore taints and are not moved if the node fails. Use a nodeSelector or node affinity instead",
+		})
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  probe.SeverityOK,
+		Message:   fmt.Sprintf("Node pinning: %d of %d pods in user namespaces set nodeName directly", total, checked),
+	})
+
+	return result, nil
+}
+
+func ownedByDaemonSet(pod *corev1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeNameSetByClient(pod *corev1.Pod) bool {
+	for _, entry := range pod.ManagedFields {
+		if entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		spec, ok := fields["f:spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := spec["f:nodeName"]; ok {
+			return true
+		}
+	}
+	return false
+}
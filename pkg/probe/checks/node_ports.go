@@ -0,0 +1,123 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const nodePortsMaxExamples = 10
+
+var defaultSensitivePorts = []int{22, 2379, 2380, 3306, 5432, 6379, 6443, 9200, 10250, 10255, 27017}
+
+type NodePorts struct {
+	sensitive []int
+}
+
+func NewNodePorts() *NodePorts {
+	return &NodePorts{
+		sensitive: defaultSensitivePorts,
+	}
+}
+
+func (c *NodePorts) Name() string {
+	return "node-ports"
+}
+
+func (c *NodePorts) Tier() int {
+	return 5
+}
+
+func (c *NodePorts) Configure(cfg *config.Config) {
+	if cfg.SensitivePorts != nil {
+		c.sensitive = cfg.SensitivePorts
+	}
+}
+
+func (c *NodePorts) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	services, err := client.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	sensitive := make(map[int]bool, len(c.sensitive))
+	for _, port := range c.sensitive {
+		sensitive[port] = true
+	}
+
+	var allocated []string
+	flagged := 0
+	for _, svc := range services.Items {
+		if svc.Spec.Type != corev1.ServiceTypeNodePort && svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+
+		var exposed []string
+		for _, port := range svc.Spec.Ports {
+			if port.NodePort == 0 {
+				continue
+			}
+			allocated = append(allocated, fmt.Sprintf("%d/%s: %s/%s (%s)", port.NodePort, protocolOrTCP(port.Protocol), svc.Namespace, svc.Name, svc.Spec.Type))
+
+			targetPort := int(port.Port)
+			if port.TargetPort.IntValue() > 0 {
+				targetPort = port.TargetPort.IntValue()
+			}
+			for _, candidate := range []int{int(port.NodePort), int(port.Port), targetPort} {
+				if sensitive[candidate] {
+					exposed = append(exposed, fmt.Sprintf("Node port %d forwards to port %d (target %s)", port.NodePort, port.Port, port.TargetPort.String()))
+					break
+				}
+			}
+		}
+		if len(exposed) == 0 {
+			continue
+		}
+
+		flagged++
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityWarning,
+			Message:   fmt.Sprintf("Service %s/%s exposes a sensitive port on every node", svc.Namespace, svc.Name),
+			Namespace: svc.Namespace,
+			Details:   append([]string{fmt.Sprintf("Type: %s", svc.Spec.Type)}, exposed...),
+			Remediation: "NodePorts are reachable on every node's address, usually bypassing ingress controls. " +
+				"Use a ClusterIP service behind an ingress or a VPN, or restrict access with firewall rules and loadBalancerSourceRanges",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl get service -n %s %s -o yaml", svc.Namespace, svc.Name),
+			},
+		})
+	}
+
+	sort.Strings(allocated)
+	details := allocated
+	if len(details) > nodePortsMaxExamples {
+		details = append(append([]string{}, allocated[:nodePortsMaxExamples]...), fmt.Sprintf("... and %d more", len(allocated)-nodePortsMaxExamples))
+	}
+
+	severity := probe.SeverityOK
+	if flagged > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Node ports: %d allocated, %d services on sensitive ports", len(allocated), flagged),
+		Details:   details,
+	})
+
+	return result, nil
+}
@@ -3,17 +3,30 @@ package checks
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-type NodeStatus struct{}
+var healthyNodeConditions = map[corev1.NodeConditionType]bool{
+	corev1.NodeReady:	true,
+	"ConfigOK":		true,
+	"KubeletConfigOk":	true,
+}
+
+type NodeStatus struct {
+	heartbeatStale time.Duration
+}
 
 func NewNodeStatus() *NodeStatus {
-	return &NodeStatus{}
+	return &NodeStatus{
+		heartbeatStale: 600 * time.Second,
+	}
 }
 
 func (c *NodeStatus) Name() string {
@@ -24,6 +37,10 @@ func (c *NodeStatus) Tier() int {
 	return 1
 }
 
+func (c *NodeStatus) Configure(cfg *config.Config) {
+	c.heartbeatStale = time.Duration(cfg.GetThreshold("node_heartbeat_stale_seconds")) * time.Second
+}
+
 func (c *NodeStatus) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
@@ -82,6 +99,25 @@ func (c *NodeStatus) Run(ctx context.Context, client kubernetes.Interface) (*pro
 			})
 		}
 
+		if ready && !lastCondition.LastHeartbeatTime.IsZero() {
+			if age := time.Since(lastCondition.LastHeartbeatTime.Time); age > c.heartbeatStale {
+				result.Results = append(result.Results, probe.Result{
+					CheckName:	c.Name(),
+					Severity:	probe.SeverityWarning,
+					Message:	fmt.Sprintf("Node %s reports Ready but its last heartbeat was %s ago", node.Name, age.Truncate(time.Second)),
+					Details: []string{
+						fmt.Sprintf("Last heartbeat: %s", lastCondition.LastHeartbeatTime.UTC().Format(time.RFC3339)),
+						fmt.Sprintf("Threshold: %s", c.heartbeatStale),
+					},
+					Remediation:	"The kubelet may have stopped posting status; the node will turn NotReady once its lease expires. Check kubelet health and connectivity to the API server",
+					RemediationCommands: []string{
+						fmt.Sprintf("kubectl get lease -n kube-node-lease %s", node.Name),
+						fmt.Sprintf("kubectl describe node %s", node.Name),
+					},
+				})
+			}
+		}
+
 		for _, cond := range node.Status.Conditions {
 			switch cond.Type {
 			case corev1.NodeMemoryPressure:
@@ -124,6 +160,10 @@ func (c *NodeStatus) Run(ctx context.Context, client kubernetes.Interface) (*pro
 						Remediation:	"Check CNI plugin status and network configuration",
 					})
 				}
+			default:
+				if cond.Status == corev1.ConditionTrue && !c.isHealthyCondition(cond.Type) {
+					result.Results = append(result.Results, c.problemConditionResult(node.Name, cond))
+				}
 			}
 		}
 	}
@@ -138,3 +178,32 @@ func (c *NodeStatus) Run(ctx context.Context, client kubernetes.Interface) (*pro
 
 	return result, nil
 }
+
+func (c *NodeStatus) isHealthyCondition(condType corev1.NodeConditionType) bool {
+	return healthyNodeConditions[condType] || strings.HasSuffix(string(condType), "Ready")
+}
+
+func (c *NodeStatus) problemConditionResult(nodeName string, cond corev1.NodeCondition) probe.Result {
+	details := []string{}
+	if cond.Reason != "" {
+		details = append(details, fmt.Sprintf("Reason: %s", cond.Reason))
+	}
+	if cond.Message != "" {
+		details = append(details, fmt.Sprintf("Message: %s", cond.Message))
+	}
+	if !cond.LastTransitionTime.IsZero() {
+		details = append(details, fmt.Sprintf("True since: %s", cond.LastTransitionTime.UTC().Format(time.RFC3339)))
+	}
+
+	return probe.Result{
+		CheckName:	c.Name(),
+		Severity:	probe.SeverityWarning,
+		Message:	fmt.Sprintf("Node %s reports condition %s", nodeName, cond.Type),
+		Details:	details,
+		Remediation:	"Custom node conditions are set by node-problem-detector or a similar agent; check the container runtime and kubelet logs on the node",
+		RemediationCommands: []string{
+			fmt.Sprintf("kubectl describe node %s", nodeName),
+			fmt.Sprintf("kubectl get events --field-selector involvedObject.kind=Node,involvedObject.name=%s", nodeName),
+		},
+	}
+}
@@ -0,0 +1,179 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+)
+
+const objectCountsTopNamespaces = 5
+
+type namespaceObjectCount struct {
+	namespace  string
+	configMaps int
+	secrets    int
+}
+
+type ObjectCounts struct {
+	perNamespaceWarning int
+}
+
+func NewObjectCounts() *ObjectCounts {
+	return &ObjectCounts{
+		perNamespaceWarning: 500,
+	}
+}
+
+func (c *ObjectCounts) Name() string {
+	return "object-counts"
+}
+
+func (c *ObjectCounts) Tier() int {
+	return 3
+}
+
+func (c *ObjectCounts) Configure(cfg *config.Config) {
+	c.perNamespaceWarning = cfg.GetThreshold("configmaps_per_ns_warning")
+}
+
+func (c *ObjectCounts) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, nil)
+}
+
+func (c *ObjectCounts) RunMetadata(ctx context.Context, client kubernetes.Interface, metadataClient metadata.Interface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, metadataClient)
+}
+
+func (c *ObjectCounts) run(ctx context.Context, client kubernetes.Interface, metadataClient metadata.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	counts := make(map[string]*namespaceObjectCount)
+	countFor := func(namespace string) *namespaceObjectCount {
+		if counts[namespace] == nil {
+			counts[namespace] = &namespaceObjectCount{namespace: namespace}
+		}
+		return counts[namespace]
+	}
+
+	configMaps, err := listMetadata(ctx, client, metadataClient, configMapsResource, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configmaps: %w", err)
+	}
+	for _, cm := range configMaps.Items {
+		countFor(cm.Namespace).configMaps++
+	}
+
+	secretsCounted := true
+	secretsSkipped := ""
+	secrets, err := listMetadata(ctx, client, metadataClient, secretsResource, metav1.ListOptions{})
+	if err != nil {
+		reason, ok := secretsUnavailable(err)
+		if !ok {
+			return nil, fmt.Errorf("failed to list secrets: %w", err)
+		}
+		secretsCounted = false
+		secretsSkipped = reason
+	} else {
+		for _, secret := range secrets.Items {
+			countFor(secret.Namespace).secrets++
+		}
+	}
+
+	ranked := make([]*namespaceObjectCount, 0, len(counts))
+	for _, count := range counts {
+		ranked = append(ranked, count)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		ti := ranked[i].configMaps + ranked[i].secrets
+		tj := ranked[j].configMaps + ranked[j].secrets
+		if ti != tj {
+			return ti > tj
+		}
+		return ranked[i].namespace < ranked[j].namespace
+	})
+
+	crowded := 0
+	for _, count := range ranked {
+		if count.configMaps > c.perNamespaceWarning {
+			crowded++
+			result.Results = append(result.Results, c.crowdedResult(count.namespace, "ConfigMaps", "configmaps", count.configMaps))
+		}
+		if count.secrets > c.perNamespaceWarning {
+			crowded++
+			result.Results = append(result.Results, c.crowdedResult(count.namespace, "Secrets", "secrets", count.secrets))
+		}
+	}
+
+	details := []string{}
+	for i, count := range ranked {
+		if i >= objectCountsTopNamespaces {
+			break
+		}
+		if secretsCounted {
+			details = append(details, fmt.Sprintf("%s: %d ConfigMaps, %d Secrets", count.namespace, count.configMaps, count.secrets))
+		} else {
+			details = append(details, fmt.Sprintf("%s: %d ConfigMaps", count.namespace, count.configMaps))
+		}
+	}
+	if !secretsCounted {
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityInfo,
+			Message:   "Secrets not counted: " + secretsSkipped,
+		})
+	}
+
+	severity := probe.SeverityOK
+	if crowded > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	message := fmt.Sprintf("Namespaced objects: %d ConfigMaps, %d Secrets across %d namespaces", len(configMaps.Items), c.totalSecrets(ranked), len(counts))
+	if !secretsCounted {
+		message = fmt.Sprintf("Namespaced objects: %d ConfigMaps across %d namespaces", len(configMaps.Items), len(counts))
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   message,
+		Details:   details,
+	})
+
+	return result, nil
+}
+
+func (c *ObjectCounts) crowdedResult(namespace, kind, resource string, count int) probe.Result {
+	return probe.Result{
+		CheckName: c.Name(),
+		Severity:  probe.SeverityWarning,
+		Message:   fmt.Sprintf("Namespace %s has %d %s", namespace, count, kind),
+		Namespace: namespace,
+		Details: []string{
+			fmt.Sprintf("Threshold: %d per namespace", c.perNamespaceWarning),
+			"Large object counts slow down kubelet syncs and etcd",
+		},
+		Remediation: "Prune leaked objects such as old Helm release history (helm --history-max) or controller leftovers",
+		RemediationCommands: []string{
+			fmt.Sprintf("kubectl get %s -n %s --sort-by=.metadata.creationTimestamp", resource, namespace),
+		},
+	}
+}
+
+func (c *ObjectCounts) totalSecrets(counts []*namespaceObjectCount) int {
+	total := 0
+	for _, count := range counts {
+		total += count.secrets
+	}
+	return total
+}
@@ -0,0 +1,107 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type PDBStatus struct{}
+
+func NewPDBStatus() *PDBStatus {
+	return &PDBStatus{}
+}
+
+func (c *PDBStatus) Name() string {
+	return "pdb-status"
+}
+
+func (c *PDBStatus) Tier() int {
+	return 2
+}
+
+func (c *PDBStatus) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	pdbs, err := client.PolicyV1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pod disruption budgets: %w", err)
+	}
+
+	if len(pdbs.Items) == 0 {
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityOK,
+			Message:   "No PodDisruptionBudgets found",
+		})
+		return result, nil
+	}
+
+	unmatched := 0
+	invalid := 0
+	for _, pdb := range pdbs.Items {
+		selectorText := "<none>"
+		if pdb.Spec.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if err != nil {
+				invalid++
+				result.Results = append(result.Results, probe.Result{
+					CheckName:   c.Name(),
+					Severity:    probe.SeverityWarning,
+					Message:     fmt.Sprintf("PodDisruptionBudget %s/%s has an invalid selector", pdb.Namespace, pdb.Name),
+					Namespace:   pdb.Namespace,
+					Details:     []string{err.Error()},
+					Remediation: "Fix the selector so the PodDisruptionBudget can match the workload's pods",
+					RemediationCommands: []string{
+						fmt.Sprintf("kubectl get pdb -n %s %s -o yaml", pdb.Namespace, pdb.Name),
+					},
+				})
+				continue
+			}
+			selectorText = selector.String()
+			if selectorText == "" {
+				selectorText = "<empty, selects every pod>"
+			}
+		}
+
+		if pdb.Status.ObservedGeneration < pdb.Generation || pdb.Status.ExpectedPods > 0 {
+			continue
+		}
+
+		unmatched++
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityWarning,
+			Message:   fmt.Sprintf("PodDisruptionBudget %s/%s matches no pods", pdb.Namespace, pdb.Name),
+			Namespace: pdb.Namespace,
+			Details: []string{
+				fmt.Sprintf("Selector: %s", selectorText),
+				"A budget that selects nothing protects nothing, which usually means the workload's labels changed or it was removed",
+			},
+			Remediation: "Update the selector to match the workload's pod labels, or delete the PodDisruptionBudget if the workload is gone",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl get pdb -n %s %s -o yaml", pdb.Namespace, pdb.Name),
+				fmt.Sprintf("kubectl get pods -n %s --show-labels", pdb.Namespace),
+			},
+		})
+	}
+
+	severity := probe.SeverityOK
+	if unmatched > 0 || invalid > 0 {
+		severity = probe.SeverityWarning
+	}
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("PodDisruptionBudgets: %d checked, %d match no pods, %d with invalid selectors", len(pdbs.Items), unmatched, invalid),
+	})
+
+	return result, nil
+}
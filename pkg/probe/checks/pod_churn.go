@@ -0,0 +1,150 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type namespaceChurn struct {
+	namespace   string
+	pods        int
+	replicaSets int
+}
+
+type PodChurn struct {
+	window      time.Duration
+	podsWarning int
+}
+
+func NewPodChurn() *PodChurn {
+	return &PodChurn{
+		window:      60 * time.Minute,
+		podsWarning: 100,
+	}
+}
+
+func (c *PodChurn) Name() string {
+	return "pod-churn"
+}
+
+func (c *PodChurn) Tier() int {
+	return 2
+}
+
+func (c *PodChurn) OptIn() bool {
+	return true
+}
+
+func (c *PodChurn) Configure(cfg *config.Config) {
+	c.window = time.Duration(cfg.GetThreshold("pod_churn_window_minutes")) * time.Minute
+	c.podsWarning = cfg.GetThreshold("pod_churn_warning")
+}
+
+func (c *PodChurn) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	pods, err := listPods(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	replicaSets, err := client.AppsV1().ReplicaSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+
+	since := time.Now().Add(-c.window)
+	churn := make(map[string]*namespaceChurn)
+	churnFor := func(namespace string) *namespaceChurn {
+		if churn[namespace] == nil {
+			churn[namespace] = &namespaceChurn{namespace: namespace}
+		}
+		return churn[namespace]
+	}
+
+	recentPods := 0
+	for _, pod := range pods.Items {
+		if ownedByJob(&pod) || pod.CreationTimestamp.Time.Before(since) {
+			continue
+		}
+		recentPods++
+		churnFor(pod.Namespace).pods++
+	}
+
+	for _, rs := range replicaSets.Items {
+		if rs.CreationTimestamp.Time.Before(since) {
+			continue
+		}
+		churnFor(rs.Namespace).replicaSets++
+	}
+
+	ranked := make([]*namespaceChurn, 0, len(churn))
+	for _, entry := range churn {
+		ranked = append(ranked, entry)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].pods != ranked[j].pods {
+			return ranked[i].pods > ranked[j].pods
+		}
+		return ranked[i].namespace < ranked[j].namespace
+	})
+
+	busy := 0
+	for _, entry := range ranked {
+		if entry.pods <= c.podsWarning {
+			continue
+		}
+		busy++
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityWarning,
+			Message:   fmt.Sprintf("Namespace %s created %d pods in the last %d minutes", entry.namespace, entry.pods, int(c.window.Minutes())),
+			Namespace: entry.namespace,
+			Details: []string{
+				fmt.Sprintf("Threshold: %d pods per namespace per %d minutes", c.podsWarning, int(c.window.Minutes())),
+				fmt.Sprintf("ReplicaSets created in the same window: %d", entry.replicaSets),
+				"Only pods that still exist are counted, so actual churn may be higher",
+			},
+			Remediation: "Frequent pod replacement loads the scheduler, API server and etcd. Look for crash-driven evictions, rollouts triggered in a loop by CI or a controller, or an HPA that flaps between replica counts",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl get pods -n %s --sort-by=.metadata.creationTimestamp", entry.namespace),
+				fmt.Sprintf("kubectl get events -n %s --field-selector reason=SuccessfulCreate", entry.namespace),
+			},
+		})
+	}
+
+	severity := probe.SeverityOK
+	if busy > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Pod churn: %d pods created in the last %d minutes, %d namespaces above %d", recentPods, int(c.window.Minutes()), busy, c.podsWarning),
+		Details:   []string{"Pods owned by Jobs are not counted"},
+	})
+
+	return result, nil
+}
+
+func ownedByJob(pod *corev1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "Job" {
+			return true
+		}
+	}
+	return false
+}
@@ -5,15 +5,19 @@ import (
 	"fmt"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-type PodSecurity struct{}
+type PodSecurity struct {
+	systemNamespaces []string
+}
 
 func NewPodSecurity() *PodSecurity {
-	return &PodSecurity{}
+	return &PodSecurity{
+		systemNamespaces: []string{"kube-system"},
+	}
 }
 
 func (c *PodSecurity) Name() string {
@@ -24,6 +28,10 @@ func (c *PodSecurity) Tier() int {
 	return 5
 }
 
+func (c *PodSecurity) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
 func (c *PodSecurity) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
@@ -31,7 +39,7 @@ func (c *PodSecurity) Run(ctx context.Context, client kubernetes.Interface) (*pr
 		Results:	[]probe.Result{},
 	}
 
-	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	pods, err := listPods(ctx, client, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
@@ -55,7 +63,7 @@ func (c *PodSecurity) Run(ctx context.Context, client kubernetes.Interface) (*pr
 			continue
 		}
 
-		isSystem := pod.Namespace == "kube-system"
+		isSystem := containsString(c.systemNamespaces, pod.Namespace)
 
 		podSecurityContext := pod.Spec.SecurityContext
 
@@ -66,6 +74,7 @@ func (c *PodSecurity) Run(ctx context.Context, client kubernetes.Interface) (*pr
 					CheckName:	c.Name(),
 					Severity:	probe.SeverityWarning,
 					Message:	fmt.Sprintf("Pod %s/%s uses host network", pod.Namespace, pod.Name),
+					Namespace:	pod.Namespace,
 					Remediation:	"Review if host network access is necessary",
 				})
 			}
@@ -78,6 +87,7 @@ func (c *PodSecurity) Run(ctx context.Context, client kubernetes.Interface) (*pr
 					CheckName:	c.Name(),
 					Severity:	probe.SeverityWarning,
 					Message:	fmt.Sprintf("Pod %s/%s uses host PID namespace", pod.Namespace, pod.Name),
+					Namespace:	pod.Namespace,
 					Remediation:	"Review if host PID access is necessary",
 				})
 			}
@@ -90,6 +100,7 @@ func (c *PodSecurity) Run(ctx context.Context, client kubernetes.Interface) (*pr
 					CheckName:	c.Name(),
 					Severity:	probe.SeverityWarning,
 					Message:	fmt.Sprintf("Pod %s/%s uses host IPC namespace", pod.Namespace, pod.Name),
+					Namespace:	pod.Namespace,
 					Remediation:	"Review if host IPC access is necessary",
 				})
 			}
@@ -106,6 +117,7 @@ func (c *PodSecurity) Run(ctx context.Context, client kubernetes.Interface) (*pr
 						CheckName:	c.Name(),
 						Severity:	probe.SeverityWarning,
 						Message:	fmt.Sprintf("Container %s in pod %s/%s is privileged", container.Name, pod.Namespace, pod.Name),
+						Namespace:	pod.Namespace,
 						Remediation:	"Review if privileged mode is necessary; consider specific capabilities instead",
 					})
 				}
@@ -131,6 +143,7 @@ func (c *PodSecurity) Run(ctx context.Context, client kubernetes.Interface) (*pr
 						CheckName:	c.Name(),
 						Severity:	probe.SeverityWarning,
 						Message:	fmt.Sprintf("Container %s in pod %s/%s runs as root", container.Name, pod.Namespace, pod.Name),
+						Namespace:	pod.Namespace,
 						Remediation:	"Consider running as non-root user with runAsNonRoot: true",
 					})
 				}
@@ -150,6 +163,7 @@ func (c *PodSecurity) Run(ctx context.Context, client kubernetes.Interface) (*pr
 								CheckName:	c.Name(),
 								Severity:	probe.SeverityWarning,
 								Message:	fmt.Sprintf("Container %s in pod %s/%s has dangerous capability %s", container.Name, pod.Namespace, pod.Name, cap),
+								Namespace:	pod.Namespace,
 								Remediation:	"Review if this capability is necessary",
 							})
 						}
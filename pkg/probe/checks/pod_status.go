@@ -3,17 +3,23 @@ package checks
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-type PodStatus struct{}
+type PodStatus struct {
+	systemNamespaces []string
+}
 
 func NewPodStatus() *PodStatus {
-	return &PodStatus{}
+	return &PodStatus{
+		systemNamespaces: []string{"kube-system"},
+	}
 }
 
 func (c *PodStatus) Name() string {
@@ -24,6 +30,10 @@ func (c *PodStatus) Tier() int {
 	return 2
 }
 
+func (c *PodStatus) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
 func (c *PodStatus) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
@@ -31,11 +41,13 @@ func (c *PodStatus) Run(ctx context.Context, client kubernetes.Interface) (*prob
 		Results:	[]probe.Result{},
 	}
 
-	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	pods, err := listPods(ctx, client, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
 
+	nodeArchs, archCount := loadNodeArchitectures(ctx, client)
+
 	stats := struct {
 		total		int
 		running		int
@@ -48,6 +60,7 @@ func (c *PodStatus) Run(ctx context.Context, client kubernetes.Interface) (*prob
 	}{}
 
 	stats.total = len(pods.Items)
+	unschedulable := []int{}
 
 	for _, pod := range pods.Items {
 		switch pod.Status.Phase {
@@ -55,7 +68,9 @@ func (c *PodStatus) Run(ctx context.Context, client kubernetes.Interface) (*prob
 			stats.running++
 		case corev1.PodPending:
 			stats.pending++
-			c.checkPendingPod(&pod, result)
+			if c.checkPendingPod(&pod, result) {
+				unschedulable = append(unschedulable, len(result.Results)-1)
+			}
 		case corev1.PodFailed:
 			stats.failed++
 			c.checkFailedPod(&pod, result)
@@ -70,16 +85,30 @@ func (c *PodStatus) Run(ctx context.Context, client kubernetes.Interface) (*prob
 				switch cs.State.Waiting.Reason {
 				case "CrashLoopBackOff":
 					stats.crashLoop++
-					if pod.Namespace != "kube-system" {
+					if !containsString(c.systemNamespaces, pod.Namespace) {
+						remediation, commands := crashLoopRemediation(&pod, cs)
+						details := append([]string{
+							fmt.Sprintf("Container: %s", cs.Name),
+							fmt.Sprintf("Restarts: %d", cs.RestartCount),
+						}, terminationDetails(cs)...)
+						if arch := nodeArchs[pod.Spec.NodeName]; arch != "" {
+							details = append(details, fmt.Sprintf("Node: %s (arch: %s)", pod.Spec.NodeName, arch))
+							if reason := architectureMismatch(&pod, cs, arch); reason != "" && !oomKilled(cs) {
+								details = append(details, "Possible architecture mismatch: "+reason)
+								remediation = fmt.Sprintf("Check logs from the previous container run. An \"exec format error\" means the image has no %s build: publish a multi-arch image or pin the pod with a kubernetes.io/arch nodeSelector", arch)
+								commands = append(commands, "kubectl get nodes -L kubernetes.io/arch")
+							} else if archCount > 1 && !pinsArchitecture(&pod) {
+								details = append(details, fmt.Sprintf("The cluster mixes %d architectures and the pod has no kubernetes.io/arch nodeSelector or affinity", archCount))
+							}
+						}
 						result.Results = append(result.Results, probe.Result{
 							CheckName:	c.Name(),
 							Severity:	probe.SeverityWarning,
-							Message:	fmt.Sprintf("Pod %s/%s is in CrashLoopBackOff", pod.Namespace, pod.Name),
-							Details: []string{
-								fmt.Sprintf("Container: %s", cs.Name),
-								fmt.Sprintf("Restarts: %d", cs.RestartCount),
-							},
-							Remediation:	fmt.Sprintf("Check logs: kubectl logs -n %s %s -c %s --previous", pod.Namespace, pod.Name, cs.Name),
+							Message:	fmt.Sprintf("Pod %s/%s is in CrashLoopBackOff%s", pod.Namespace, pod.Name, oomSuffix(cs)),
+							Namespace:	pod.Namespace,
+							Details:	details,
+							Remediation:		remediation,
+							RemediationCommands:	commands,
 						})
 					}
 				case "ImagePullBackOff", "ErrImagePull":
@@ -88,6 +117,7 @@ func (c *PodStatus) Run(ctx context.Context, client kubernetes.Interface) (*prob
 						CheckName:	c.Name(),
 						Severity:	probe.SeverityWarning,
 						Message:	fmt.Sprintf("Pod %s/%s cannot pull image", pod.Namespace, pod.Name),
+						Namespace:	pod.Namespace,
 						Details: []string{
 							fmt.Sprintf("Container: %s", cs.Name),
 							fmt.Sprintf("Image: %s", cs.Image),
@@ -100,6 +130,14 @@ func (c *PodStatus) Run(ctx context.Context, client kubernetes.Interface) (*prob
 		}
 	}
 
+	if len(unschedulable) > 0 {
+		if autoscaler := loadAutoscalerStatus(ctx, client); autoscaler != nil {
+			for _, i := range unschedulable {
+				c.addAutoscalerContext(&result.Results[i], autoscaler)
+			}
+		}
+	}
+
 	severity := probe.SeverityOK
 	if stats.crashLoop > 0 || stats.imagePull > 0 || stats.failed > 0 {
 		severity = probe.SeverityWarning
@@ -119,21 +157,36 @@ func (c *PodStatus) Run(ctx context.Context, client kubernetes.Interface) (*prob
 	return result, nil
 }
 
-func (c *PodStatus) checkPendingPod(pod *corev1.Pod, result *probe.CheckResult) {
+func (c *PodStatus) checkPendingPod(pod *corev1.Pod, result *probe.CheckResult) bool {
 	for _, cond := range pod.Status.Conditions {
 		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
 			result.Results = append(result.Results, probe.Result{
 				CheckName:	c.Name(),
 				Severity:	probe.SeverityWarning,
 				Message:	fmt.Sprintf("Pod %s/%s is unschedulable", pod.Namespace, pod.Name),
+				Namespace:	pod.Namespace,
 				Details: []string{
 					fmt.Sprintf("Reason: %s", cond.Reason),
 					fmt.Sprintf("Message: %s", cond.Message),
 				},
 				Remediation:	"Check node resources, taints, and pod resource requests/tolerations",
 			})
+			return true
 		}
 	}
+	return false
+}
+
+func (c *PodStatus) addAutoscalerContext(r *probe.Result, autoscaler *autoscalerStatus) {
+	r.Details = append(r.Details, autoscaler.summary())
+
+	if autoscaler.scaleUpFailing || autoscaler.unhealthy {
+		r.Remediation = "Cluster autoscaler is failing to add nodes. Check node group max size, cloud provider quotas, and autoscaler logs"
+		r.RemediationCommands = []string{"kubectl describe configmap -n " + autoscalerStatusNamespace + " " + autoscalerStatusConfigMap}
+		return
+	}
+
+	r.Remediation = "Cluster autoscaler is not reporting scale-up failures. Check whether the pod's requests, selectors, and tolerations fit any node group"
 }
 
 func (c *PodStatus) checkFailedPod(pod *corev1.Pod, result *probe.CheckResult) {
@@ -143,6 +196,7 @@ func (c *PodStatus) checkFailedPod(pod *corev1.Pod, result *probe.CheckResult) {
 			CheckName:	c.Name(),
 			Severity:	probe.SeverityWarning,
 			Message:	fmt.Sprintf("Pod %s/%s was evicted", pod.Namespace, pod.Name),
+			Namespace:	pod.Namespace,
 			Details: []string{
 				fmt.Sprintf("Message: %s", pod.Status.Message),
 			},
@@ -150,3 +204,101 @@ func (c *PodStatus) checkFailedPod(pod *corev1.Pod, result *probe.CheckResult) {
 		})
 	}
 }
+
+func terminationDetails(cs corev1.ContainerStatus) []string {
+	terminated := cs.LastTerminationState.Terminated
+	if terminated == nil {
+		return nil
+	}
+	details := []string{fmt.Sprintf("Last exit code: %d", terminated.ExitCode)}
+	if terminated.Reason != "" {
+		details = append(details, fmt.Sprintf("Last termination reason: %s", terminated.Reason))
+	}
+	return details
+}
+
+func oomKilled(cs corev1.ContainerStatus) bool {
+	terminated := cs.LastTerminationState.Terminated
+	return terminated != nil && terminated.Reason == "OOMKilled"
+}
+
+func oomSuffix(cs corev1.ContainerStatus) string {
+	if oomKilled(cs) {
+		return " (OOMKilled)"
+	}
+	return ""
+}
+
+func crashLoopRemediation(pod *corev1.Pod, cs corev1.ContainerStatus) (string, []string) {
+	logs := fmt.Sprintf("kubectl logs -n %s %s -c %s --previous", pod.Namespace, pod.Name, cs.Name)
+	if !oomKilled(cs) {
+		return "Check logs from the previous container run", []string{logs}
+	}
+
+	limit := "no limit set"
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			if container.Name != cs.Name {
+				continue
+			}
+			if memory, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+				limit = "currently " + memory.String()
+			}
+		}
+	}
+
+	return fmt.Sprintf("The container was killed for exceeding its memory limit (%s). Raise resources.limits.memory or reduce the application's memory use", limit), []string{
+		fmt.Sprintf("kubectl top pod -n %s %s --containers", pod.Namespace, pod.Name),
+		logs,
+	}
+}
+
+func loadNodeArchitectures(ctx context.Context, client kubernetes.Interface) (map[string]string, int) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, 0
+	}
+
+	archs := make(map[string]string, len(nodes.Items))
+	distinct := make(map[string]bool)
+	for _, node := range nodes.Items {
+		arch := node.Labels[corev1.LabelArchStable]
+		if arch == "" {
+			arch = node.Status.NodeInfo.Architecture
+		}
+		if arch == "" {
+			continue
+		}
+		archs[node.Name] = arch
+		distinct[arch] = true
+	}
+	return archs, len(distinct)
+}
+
+func architectureMismatch(pod *corev1.Pod, cs corev1.ContainerStatus, nodeArch string) string {
+	if terminated := cs.LastTerminationState.Terminated; terminated != nil && strings.Contains(terminated.Message, "exec format error") {
+		return "the last run failed with exec format error"
+	}
+	if selected := pod.Spec.NodeSelector[corev1.LabelArchStable]; selected != "" && selected != nodeArch {
+		return fmt.Sprintf("the pod selects %s but runs on a %s node", selected, nodeArch)
+	}
+	return ""
+}
+
+func pinsArchitecture(pod *corev1.Pod) bool {
+	if pod.Spec.NodeSelector[corev1.LabelArchStable] != "" {
+		return true
+	}
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return false
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == corev1.LabelArchStable {
+				return true
+			}
+		}
+	}
+	return false
+}
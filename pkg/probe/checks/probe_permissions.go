@@ -0,0 +1,226 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const permissionsReviewNamespace = "default"
+
+type requiredResource struct {
+	group    string
+	resource string
+}
+
+var probeRequiredResources = []requiredResource{
+	{"", "configmaps"},
+	{"", "endpoints"},
+	{"", "events"},
+	{"", "limitranges"},
+	{"", "namespaces"},
+	{"", "nodes"},
+	{"", "persistentvolumeclaims"},
+	{"", "persistentvolumes"},
+	{"", "pods"},
+	{"", "resourcequotas"},
+	{"", "serviceaccounts"},
+	{"", "services"},
+	{"apps", "daemonsets"},
+	{"apps", "deployments"},
+	{"apps", "replicasets"},
+	{"apps", "statefulsets"},
+	{"batch", "cronjobs"},
+	{"batch", "jobs"},
+	{"autoscaling", "horizontalpodautoscalers"},
+	{"policy", "poddisruptionbudgets"},
+	{"networking.k8s.io", "ingresses"},
+	{"networking.k8s.io", "networkpolicies"},
+	{"rbac.authorization.k8s.io", "clusterrolebindings"},
+	{"rbac.authorization.k8s.io", "clusterroles"},
+	{"rbac.authorization.k8s.io", "rolebindings"},
+	{"rbac.authorization.k8s.io", "roles"},
+	{"storage.k8s.io", "csidrivers"},
+	{"storage.k8s.io", "storageclasses"},
+	{"storage.k8s.io", "volumeattachments"},
+	{"admissionregistration.k8s.io", "mutatingwebhookconfigurations"},
+	{"admissionregistration.k8s.io", "validatingwebhookconfigurations"},
+	{"certificates.k8s.io", "certificatesigningrequests"},
+}
+
+var builtinAPIGroups = map[string]bool{
+	"":                             true,
+	"admissionregistration.k8s.io": true,
+	"apiextensions.k8s.io":         true,
+	"apiregistration.k8s.io":       true,
+	"apps":                         true,
+	"authentication.k8s.io":        true,
+	"authorization.k8s.io":         true,
+	"autoscaling":                  true,
+	"batch":                        true,
+	"certificates.k8s.io":          true,
+	"coordination.k8s.io":          true,
+	"discovery.k8s.io":             true,
+	"events.k8s.io":                true,
+	"extensions":                   true,
+	"flowcontrol.apiserver.k8s.io": true,
+	"internal.apiserver.k8s.io":    true,
+	"networking.k8s.io":            true,
+	"node.k8s.io":                  true,
+	"policy":                       true,
+	"rbac.authorization.k8s.io":    true,
+	"resource.k8s.io":              true,
+	"scheduling.k8s.io":            true,
+	"storage.k8s.io":               true,
+	"storagemigration.k8s.io":      true,
+	"metrics.k8s.io":               true,
+	"custom.metrics.k8s.io":        true,
+	"external.metrics.k8s.io":      true,
+}
+
+type ProbePermissions struct{}
+
+func NewProbePermissions() *ProbePermissions {
+	return &ProbePermissions{}
+}
+
+func (c *ProbePermissions) Name() string {
+	return "probe-permissions"
+}
+
+func (c *ProbePermissions) Tier() int {
+	return 5
+}
+
+func (c *ProbePermissions) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, client.Discovery())
+}
+
+func (c *ProbePermissions) RunDynamic(ctx context.Context, client kubernetes.Interface, dynamicClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, discoveryClient)
+}
+
+func (c *ProbePermissions) run(ctx context.Context, client kubernetes.Interface, discoveryClient discovery.DiscoveryInterface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	review := &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: permissionsReviewNamespace},
+	}
+	review, err := client.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityInfo,
+			Message:   "Probe permissions not verified: SelfSubjectRulesReview failed",
+			Details:   []string{err.Error()},
+		})
+		return result, nil
+	}
+	rules := review.Status.ResourceRules
+
+	var missing []string
+	for _, required := range probeRequiredResources {
+		if !rulesAllowList(rules, required.group, required.resource) {
+			missing = append(missing, formatGroupResource(required.group, required.resource))
+		}
+	}
+
+	var missingGroups []string
+	if groups, err := discoveryClient.ServerGroups(); err == nil {
+		for _, group := range groups.Groups {
+			if builtinAPIGroups[group.Name] || rulesAllowGroup(rules, group.Name) {
+				continue
+			}
+			missingGroups = append(missingGroups, group.Name)
+		}
+		sort.Strings(missingGroups)
+	}
+
+	if len(missing) > 0 {
+		details := append([]string{}, missing...)
+		if review.Status.Incomplete {
+			details = append(details, "The API server reported an incomplete rule list, so some of these may still be allowed")
+		}
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("Probe credentials cannot list %d resource types the checks need", len(missing)),
+			Details:     details,
+			Remediation: "Checks that read these resources fail or report incomplete results. Re-run setup to recreate the read-only ClusterRole",
+			RemediationCommands: []string{
+				"cluster-probe --setup",
+			},
+		})
+	}
+
+	if len(missingGroups) > 0 {
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("Probe credentials cannot read %d custom resource API groups", len(missingGroups)),
+			Details:     missingGroups,
+			Remediation: "Setup grants read access to the CRD groups that existed when it ran. Re-run setup to pick up CRDs installed since then",
+			RemediationCommands: []string{
+				"cluster-probe --setup",
+			},
+		})
+	}
+
+	severity := probe.SeverityOK
+	if len(missing) > 0 || len(missingGroups) > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Probe permissions: %d/%d required resource types readable", len(probeRequiredResources)-len(missing), len(probeRequiredResources)),
+	})
+
+	return result, nil
+}
+
+func rulesAllowList(rules []authorizationv1.ResourceRule, group, resource string) bool {
+	for _, rule := range rules {
+		if containsOrWildcard(rule.APIGroups, group) && containsOrWildcard(rule.Resources, resource) && containsOrWildcard(rule.Verbs, "list") {
+			return true
+		}
+	}
+	return false
+}
+
+func rulesAllowGroup(rules []authorizationv1.ResourceRule, group string) bool {
+	for _, rule := range rules {
+		if containsOrWildcard(rule.APIGroups, group) && containsOrWildcard(rule.Verbs, "list") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsOrWildcard(values []string, want string) bool {
+	for _, value := range values {
+		if value == want || value == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func formatGroupResource(group, resource string) string {
+	if group == "" {
+		return resource
+	}
+	return resource + "." + group
+}
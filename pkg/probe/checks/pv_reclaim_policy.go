@@ -0,0 +1,129 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type PVReclaimPolicy struct {
+	namespaceSelector string
+	systemNamespaces  []string
+}
+
+func NewPVReclaimPolicy() *PVReclaimPolicy {
+	return &PVReclaimPolicy{
+		systemNamespaces: []string{"kube-system"},
+	}
+}
+
+func (c *PVReclaimPolicy) Name() string {
+	return "pv-reclaim-policy"
+}
+
+func (c *PVReclaimPolicy) Tier() int {
+	return 3
+}
+
+func (c *PVReclaimPolicy) OptIn() bool {
+	return true
+}
+
+func (c *PVReclaimPolicy) Configure(cfg *config.Config) {
+	c.namespaceSelector = cfg.StatefulNamespaces
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
+func (c *PVReclaimPolicy) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	var stateful map[string]bool
+	if c.namespaceSelector != "" {
+		namespaces, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: c.namespaceSelector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list namespaces matching %q: %w", c.namespaceSelector, err)
+		}
+		stateful = make(map[string]bool, len(namespaces.Items))
+		for _, ns := range namespaces.Items {
+			stateful[ns.Name] = true
+		}
+	}
+
+	pvs, err := client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistentvolumes: %w", err)
+	}
+
+	sort.Slice(pvs.Items, func(i, j int) bool {
+		return pvs.Items[i].Name < pvs.Items[j].Name
+	})
+
+	checked := 0
+	flagged := 0
+	for _, pv := range pvs.Items {
+		if pv.Status.Phase != corev1.VolumeBound || pv.Spec.ClaimRef == nil {
+			continue
+		}
+		claim := pv.Spec.ClaimRef
+		if containsString(c.systemNamespaces, claim.Namespace) {
+			continue
+		}
+		if stateful != nil && !stateful[claim.Namespace] {
+			continue
+		}
+		checked++
+
+		if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimDelete {
+			continue
+		}
+
+		flagged++
+		storageClass := pv.Spec.StorageClassName
+		if storageClass == "" {
+			storageClass = "(none)"
+		}
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityWarning,
+			Message:   fmt.Sprintf("PersistentVolume %s bound to %s/%s is deleted along with its claim", pv.Name, claim.Namespace, claim.Name),
+			Namespace: claim.Namespace,
+			Details: []string{
+				"Reclaim policy: Delete",
+				fmt.Sprintf("StorageClass: %s", storageClass),
+				fmt.Sprintf("Capacity: %s", storageRequest(pv.Spec.Capacity)),
+			},
+			Remediation: "Deleting the PVC, or the namespace holding it, permanently destroys the underlying volume. " +
+				"Set the reclaim policy to Retain on volumes holding data you cannot recreate, and use a StorageClass with reclaimPolicy: Retain for new ones",
+			RemediationCommands: []string{
+				fmt.Sprintf(`kubectl patch pv %s -p '{"spec":{"persistentVolumeReclaimPolicy":"Retain"}}'`, pv.Name),
+			},
+		})
+	}
+
+	severity := probe.SeverityOK
+	if flagged > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	scope := "user namespaces"
+	if c.namespaceSelector != "" {
+		scope = fmt.Sprintf("namespaces matching %s", c.namespaceSelector)
+	}
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("PV reclaim policy: %d of %d bound volumes in %s use Delete", flagged, checked, scope),
+	})
+
+	return result, nil
+}
@@ -68,6 +68,7 @@ func (c *PVCStatus) Run(ctx context.Context, client kubernetes.Interface) (*prob
 				CheckName:	c.Name(),
 				Severity:	severity,
 				Message:	fmt.Sprintf("PVC %s/%s is pending", pvc.Namespace, pvc.Name),
+				Namespace:	pvc.Namespace,
 				Details:	details,
 				Remediation:	"Check storage provisioner logs and available PVs",
 			})
@@ -77,6 +78,7 @@ func (c *PVCStatus) Run(ctx context.Context, client kubernetes.Interface) (*prob
 				CheckName:	c.Name(),
 				Severity:	probe.SeverityCritical,
 				Message:	fmt.Sprintf("PVC %s/%s has lost its bound volume", pvc.Namespace, pvc.Name),
+				Namespace:	pvc.Namespace,
 				Details: []string{
 					fmt.Sprintf("Volume was: %s", pvc.Spec.VolumeName),
 				},
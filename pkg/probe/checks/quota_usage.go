@@ -3,12 +3,16 @@ package checks
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+const limitRangeMaxExamples = 10
+
 type QuotaUsage struct{}
 
 func NewQuotaUsage() *QuotaUsage {
@@ -41,6 +45,7 @@ func (c *QuotaUsage) Run(ctx context.Context, client kubernetes.Interface) (*pro
 			Severity:	probe.SeverityOK,
 			Message:	"No resource quotas defined in the cluster",
 		})
+		c.checkLimitRanges(ctx, client, result)
 		return result, nil
 	}
 
@@ -75,6 +80,7 @@ func (c *QuotaUsage) Run(ctx context.Context, client kubernetes.Interface) (*pro
 					CheckName:	c.Name(),
 					Severity:	probe.SeverityCritical,
 					Message:	fmt.Sprintf("Quota %s/%s has reached limit for %s", quota.Namespace, quota.Name, resourceName),
+					Namespace:	quota.Namespace,
 					Details: []string{
 						fmt.Sprintf("Used: %s", used.String()),
 						fmt.Sprintf("Hard: %s", hardLimit.String()),
@@ -88,6 +94,7 @@ func (c *QuotaUsage) Run(ctx context.Context, client kubernetes.Interface) (*pro
 					CheckName:	c.Name(),
 					Severity:	probe.SeverityWarning,
 					Message:	fmt.Sprintf("Quota %s/%s is at %.0f%% for %s", quota.Namespace, quota.Name, usagePercent, resourceName),
+					Namespace:	quota.Namespace,
 					Details: []string{
 						fmt.Sprintf("Used: %s", used.String()),
 						fmt.Sprintf("Hard: %s", hardLimit.String()),
@@ -98,19 +105,10 @@ func (c *QuotaUsage) Run(ctx context.Context, client kubernetes.Interface) (*pro
 		}
 	}
 
-	limitRanges, err := client.CoreV1().LimitRanges("").List(ctx, metav1.ListOptions{})
-	if err == nil {
-		if len(limitRanges.Items) > 0 {
-			result.Results = append(result.Results, probe.Result{
-				CheckName:	c.Name(),
-				Severity:	probe.SeverityOK,
-				Message:	fmt.Sprintf("%d limit ranges configured", len(limitRanges.Items)),
-			})
-		}
-	}
+	limitRangeViolations := c.checkLimitRanges(ctx, client, result)
 
 	severity := probe.SeverityOK
-	if quotasNearLimit > 0 {
+	if quotasNearLimit > 0 || limitRangeViolations > 0 {
 		severity = probe.SeverityWarning
 	}
 	if quotasExceeded > 0 {
@@ -125,3 +123,91 @@ func (c *QuotaUsage) Run(ctx context.Context, client kubernetes.Interface) (*pro
 
 	return result, nil
 }
+
+func (c *QuotaUsage) checkLimitRanges(ctx context.Context, client kubernetes.Interface, result *probe.CheckResult) int {
+	limitRanges, err := client.CoreV1().LimitRanges("").List(ctx, metav1.ListOptions{})
+	if err != nil || len(limitRanges.Items) == 0 {
+		return 0
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName:	c.Name(),
+		Severity:	probe.SeverityOK,
+		Message:	fmt.Sprintf("%d limit ranges configured", len(limitRanges.Items)),
+	})
+
+	violating := 0
+	for _, lr := range limitRanges.Items {
+		pods, err := client.CoreV1().Pods(lr.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			continue
+		}
+
+		var violations []string
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			for _, container := range pod.Spec.Containers {
+				for _, problem := range limitRangeViolations(lr.Spec.Limits, container.Resources) {
+					violations = append(violations, fmt.Sprintf("%s/%s: %s", pod.Name, container.Name, problem))
+				}
+			}
+		}
+
+		if len(violations) == 0 {
+			continue
+		}
+		violating++
+		sort.Strings(violations)
+
+		details := violations
+		if len(details) > limitRangeMaxExamples {
+			details = append(append([]string{}, violations[:limitRangeMaxExamples]...), fmt.Sprintf("... and %d more", len(violations)-limitRangeMaxExamples))
+		}
+
+		result.Results = append(result.Results, probe.Result{
+			CheckName:	c.Name(),
+			Severity:	probe.SeverityWarning,
+			Message:	fmt.Sprintf("LimitRange %s/%s has %d existing containers outside its limits", lr.Namespace, lr.Name, len(violations)),
+			Namespace:	lr.Namespace,
+			Details:	details,
+			Remediation:	"These pods were admitted before the LimitRange changed; their replacements will be rejected on the next rollout or restart. Bring the workloads' requests and limits within the range, or widen the LimitRange",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl describe limitrange -n %s %s", lr.Namespace, lr.Name),
+			},
+		})
+	}
+
+	return violating
+}
+
+func limitRangeViolations(limits []corev1.LimitRangeItem, resources corev1.ResourceRequirements) []string {
+	var problems []string
+	for _, item := range limits {
+		if item.Type != corev1.LimitTypeContainer {
+			continue
+		}
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			for _, value := range []struct {
+				kind		string
+				quantity	corev1.ResourceList
+			}{
+				{"request", resources.Requests},
+				{"limit", resources.Limits},
+			} {
+				quantity, ok := value.quantity[name]
+				if !ok {
+					continue
+				}
+				if max, ok := item.Max[name]; ok && quantity.Cmp(max) > 0 {
+					problems = append(problems, fmt.Sprintf("%s %s %s above max %s", name, value.kind, quantity.String(), max.String()))
+				}
+				if min, ok := item.Min[name]; ok && quantity.Cmp(min) < 0 {
+					problems = append(problems, fmt.Sprintf("%s %s %s below min %s", name, value.kind, quantity.String(), min.String()))
+				}
+			}
+		}
+	}
+	return problems
+}
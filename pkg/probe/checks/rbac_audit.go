@@ -6,15 +6,25 @@ import (
 	"strings"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-type RBACAudit struct{}
+const (
+	aggregateToAdminLabel	= "rbac.authorization.k8s.io/aggregate-to-admin"
+	bootstrappingLabel	= "kubernetes.io/bootstrapping"
+)
+
+type RBACAudit struct {
+	systemNamespaces []string
+}
 
 func NewRBACAudit() *RBACAudit {
-	return &RBACAudit{}
+	return &RBACAudit{
+		systemNamespaces: []string{"kube-system"},
+	}
 }
 
 func (c *RBACAudit) Name() string {
@@ -25,6 +35,10 @@ func (c *RBACAudit) Tier() int {
 	return 5
 }
 
+func (c *RBACAudit) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
 func (c *RBACAudit) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
@@ -40,6 +54,7 @@ func (c *RBACAudit) Run(ctx context.Context, client kubernetes.Interface) (*prob
 	wildcardRoles := 0
 	secretAccessRoles := 0
 	adminRoles := 0
+	aggregatedRoles := 0
 
 	for _, cr := range clusterRoles.Items {
 
@@ -62,6 +77,24 @@ func (c *RBACAudit) Run(ctx context.Context, client kubernetes.Interface) (*prob
 			})
 		}
 
+		if cr.Labels[aggregateToAdminLabel] == "true" && cr.Labels[bootstrappingLabel] != "rbac-defaults" {
+			aggregatedRoles++
+			details := []string{fmt.Sprintf("Label: %s=true", aggregateToAdminLabel)}
+			for _, rule := range cr.Rules {
+				details = append(details, "Rule: "+formatPolicyRule(rule))
+			}
+			result.Results = append(result.Results, probe.Result{
+				CheckName:	c.Name(),
+				Severity:	probe.SeverityWarning,
+				Message:	fmt.Sprintf("ClusterRole %s is aggregated into the built-in admin role", cr.Name),
+				Details:	details,
+				Remediation:	"The controller manager copies these rules into the admin ClusterRole, so every subject granted admin gains them without any binding change. Remove the label unless all namespace admins should have these permissions",
+				RemediationCommands: []string{
+					fmt.Sprintf("kubectl label clusterrole %s %s-", cr.Name, aggregateToAdminLabel),
+				},
+			})
+		}
+
 		if issues.hasSecretAccess {
 			secretAccessRoles++
 		}
@@ -86,12 +119,13 @@ func (c *RBACAudit) Run(ctx context.Context, client kubernetes.Interface) (*prob
 		if crb.RoleRef.Name == "cluster-admin" {
 			for _, subject := range crb.Subjects {
 
-				if subject.Kind == "ServiceAccount" && subject.Namespace != "kube-system" {
+				if subject.Kind == "ServiceAccount" && !containsString(c.systemNamespaces, subject.Namespace) {
 					dangerousBindings++
 					result.Results = append(result.Results, probe.Result{
 						CheckName:	c.Name(),
 						Severity:	probe.SeverityWarning,
 						Message:	fmt.Sprintf("ServiceAccount %s/%s has cluster-admin access", subject.Namespace, subject.Name),
+						Namespace:	subject.Namespace,
 						Details: []string{
 							fmt.Sprintf("Binding: %s", crb.Name),
 						},
@@ -115,23 +149,57 @@ func (c *RBACAudit) Run(ctx context.Context, client kubernetes.Interface) (*prob
 		}
 	}
 
+	roleNames := map[string]bool{}
 	roles, err := client.RbacV1().Roles("").List(ctx, metav1.ListOptions{})
-	if err == nil {
+	rolesListed := err == nil
+	if rolesListed {
 		for _, role := range roles.Items {
+			roleNames[role.Namespace+"/"+role.Name] = true
 			issues := c.analyzeRules(role.Rules)
 			if issues.hasWildcardAll {
 				result.Results = append(result.Results, probe.Result{
 					CheckName:	c.Name(),
 					Severity:	probe.SeverityWarning,
 					Message:	fmt.Sprintf("Role %s/%s has wildcard access", role.Namespace, role.Name),
+					Namespace:	role.Namespace,
 					Remediation:	"Consider limiting to specific resources and verbs",
 				})
 			}
 		}
 	}
 
+	clusterRoleNames := make(map[string]bool, len(clusterRoles.Items))
+	for _, cr := range clusterRoles.Items {
+		clusterRoleNames[cr.Name] = true
+	}
+
+	danglingBindings := 0
+	for _, crb := range clusterRoleBindings.Items {
+		if crb.RoleRef.Kind == "ClusterRole" && !clusterRoleNames[crb.RoleRef.Name] {
+			danglingBindings++
+			result.Results = append(result.Results, c.danglingBindingResult("ClusterRoleBinding", "", crb.Name, crb.RoleRef))
+		}
+	}
+
+	roleBindings, err := client.RbacV1().RoleBindings("").List(ctx, metav1.ListOptions{})
+	if err == nil {
+		for _, rb := range roleBindings.Items {
+			missing := false
+			switch rb.RoleRef.Kind {
+			case "ClusterRole":
+				missing = !clusterRoleNames[rb.RoleRef.Name]
+			case "Role":
+				missing = rolesListed && !roleNames[rb.Namespace+"/"+rb.RoleRef.Name]
+			}
+			if missing {
+				danglingBindings++
+				result.Results = append(result.Results, c.danglingBindingResult("RoleBinding", rb.Namespace, rb.Name, rb.RoleRef))
+			}
+		}
+	}
+
 	severity := probe.SeverityOK
-	if wildcardRoles > 0 || dangerousBindings > 0 {
+	if wildcardRoles > 0 || aggregatedRoles > 0 || dangerousBindings > 0 || danglingBindings > 0 {
 		severity = probe.SeverityWarning
 	}
 
@@ -149,14 +217,41 @@ func (c *RBACAudit) Run(ctx context.Context, client kubernetes.Interface) (*prob
 		Details: []string{
 			fmt.Sprintf("Custom ClusterRoles: %d", totalClusterRoles),
 			fmt.Sprintf("Wildcard access roles: %d", wildcardRoles),
+			fmt.Sprintf("Roles aggregated into admin: %d", aggregatedRoles),
 			fmt.Sprintf("Roles with secret access: %d", secretAccessRoles),
 			fmt.Sprintf("Dangerous bindings: %d", dangerousBindings),
+			fmt.Sprintf("Bindings to missing roles: %d", danglingBindings),
 		},
 	})
 
 	return result, nil
 }
 
+func (c *RBACAudit) danglingBindingResult(kind, namespace, name string, roleRef rbacv1.RoleRef) probe.Result {
+	bindingName := name
+	command := fmt.Sprintf("kubectl get %s %s", strings.ToLower(roleRef.Kind), roleRef.Name)
+	if namespace != "" {
+		bindingName = namespace + "/" + name
+		if roleRef.Kind == "Role" {
+			command += " -n " + namespace
+		}
+	}
+
+	return probe.Result{
+		CheckName:	c.Name(),
+		Severity:	probe.SeverityWarning,
+		Message:	fmt.Sprintf("%s %s references missing %s %s", kind, bindingName, roleRef.Kind, roleRef.Name),
+		Namespace:	namespace,
+		Details: []string{
+			"The binding grants no permissions until the referenced role exists",
+		},
+		Remediation:	"Recreate the missing role if the deploy that provides it failed, otherwise delete the stale binding",
+		RemediationCommands: []string{
+			command,
+		},
+	}
+}
+
 type ruleIssues struct {
 	hasWildcardAll	bool
 	hasSecretAccess	bool
@@ -191,6 +286,20 @@ func (c *RBACAudit) analyzeRules(rules []rbacv1.PolicyRule) ruleIssues {
 	return issues
 }
 
+func formatPolicyRule(rule rbacv1.PolicyRule) string {
+	quote := func(values []string) string {
+		quoted := make([]string, 0, len(values))
+		for _, v := range values {
+			quoted = append(quoted, "'"+v+"'")
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+	if len(rule.NonResourceURLs) > 0 {
+		return fmt.Sprintf("nonResourceURLs: %s, verbs: %s", quote(rule.NonResourceURLs), quote(rule.Verbs))
+	}
+	return fmt.Sprintf("apiGroups: %s, resources: %s, verbs: %s", quote(rule.APIGroups), quote(rule.Resources), quote(rule.Verbs))
+}
+
 func containsString(slice []string, str string) bool {
 	for _, s := range slice {
 		if s == str {
@@ -0,0 +1,137 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type RequestBalance struct {
+	ratio            int
+	systemNamespaces []string
+}
+
+func NewRequestBalance() *RequestBalance {
+	return &RequestBalance{
+		ratio:            10,
+		systemNamespaces: []string{"kube-system"},
+	}
+}
+
+func (c *RequestBalance) Name() string {
+	return "request-balance"
+}
+
+func (c *RequestBalance) Tier() int {
+	return 3
+}
+
+func (c *RequestBalance) OptIn() bool {
+	return true
+}
+
+func (c *RequestBalance) Configure(cfg *config.Config) {
+	c.ratio = cfg.GetThreshold("container_request_ratio_warning")
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
+func (c *RequestBalance) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	pods, err := listPods(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	checked := 0
+	flagged := 0
+	for _, pod := range pods.Items {
+		if containsString(c.systemNamespaces, pod.Namespace) || len(pod.Spec.Containers) < 2 {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		checked++
+
+		var details []string
+		for _, resource := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			details = append(details, c.imbalances(pod.Spec.Containers, resource)...)
+		}
+		if len(details) == 0 {
+			continue
+		}
+
+		flagged++
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityWarning,
+			Message:   fmt.Sprintf("Pod %s/%s has uneven resource requests across its containers", pod.Namespace, pod.Name),
+			Namespace: pod.Namespace,
+			Details:   details,
+			Remediation: "The scheduler places pods by the sum of their requests, so a sidecar without requests consumes capacity nobody reserved and skews bin-packing. " +
+				"Give every container requests that reflect its real usage",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl top pod -n %s %s --containers", pod.Namespace, pod.Name),
+			},
+		})
+	}
+
+	severity := probe.SeverityOK
+	if flagged > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Request balance: %d of %d multi-container pods have uneven requests", flagged, checked),
+	})
+
+	return result, nil
+}
+
+func (c *RequestBalance) imbalances(containers []corev1.Container, resource corev1.ResourceName) []string {
+	var largest, smallest *corev1.Container
+	var missing []string
+	for i := range containers {
+		container := &containers[i]
+		request, ok := container.Resources.Requests[resource]
+		if !ok || request.IsZero() {
+			missing = append(missing, container.Name)
+			continue
+		}
+		if largest == nil || request.Cmp(largest.Resources.Requests[resource]) > 0 {
+			largest = container
+		}
+		if smallest == nil || request.Cmp(smallest.Resources.Requests[resource]) < 0 {
+			smallest = container
+		}
+	}
+
+	if largest == nil {
+		return nil
+	}
+
+	largestRequest := largest.Resources.Requests[resource]
+	var details []string
+	for _, name := range missing {
+		details = append(details, fmt.Sprintf("Container %s requests no %s while %s requests %s", name, resource, largest.Name, largestRequest.String()))
+	}
+
+	smallestRequest := smallest.Resources.Requests[resource]
+	ratio := float64(largestRequest.MilliValue()) / float64(smallestRequest.MilliValue())
+	if ratio >= float64(c.ratio) {
+		details = append(details, fmt.Sprintf("Container %s requests %s %s, %.0fx the %s requested by %s (threshold: %dx)", largest.Name, largestRequest.String(), resource, ratio, smallestRequest.String(), smallest.Name, c.ratio))
+	}
+
+	return details
+}
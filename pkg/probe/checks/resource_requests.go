@@ -3,17 +3,26 @@ package checks
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-type ResourceRequests struct{}
+const memoryLimitMinPods = 3
+
+type ResourceRequests struct {
+	noMemoryLimitPercent	int
+	systemNamespaces	[]string
+}
 
 func NewResourceRequests() *ResourceRequests {
-	return &ResourceRequests{}
+	return &ResourceRequests{
+		noMemoryLimitPercent:	50,
+		systemNamespaces:	[]string{"kube-system"},
+	}
 }
 
 func (c *ResourceRequests) Name() string {
@@ -24,6 +33,11 @@ func (c *ResourceRequests) Tier() int {
 	return 3
 }
 
+func (c *ResourceRequests) Configure(cfg *config.Config) {
+	c.noMemoryLimitPercent = cfg.GetThreshold("no_memory_limit_percent")
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
 func (c *ResourceRequests) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
@@ -31,7 +45,7 @@ func (c *ResourceRequests) Run(ctx context.Context, client kubernetes.Interface)
 		Results:	[]probe.Result{},
 	}
 
-	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	pods, err := listPods(ctx, client, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
@@ -41,6 +55,8 @@ func (c *ResourceRequests) Run(ctx context.Context, client kubernetes.Interface)
 	total := 0
 
 	nsWithIssues := make(map[string]int)
+	nsPods := make(map[string]int)
+	nsNoMemoryLimit := make(map[string]int)
 
 	for _, pod := range pods.Items {
 
@@ -48,10 +64,14 @@ func (c *ResourceRequests) Run(ctx context.Context, client kubernetes.Interface)
 			continue
 		}
 
-		isSystemNS := pod.Namespace == "kube-system" || pod.Namespace == "kube-public" || pod.Namespace == "kube-node-lease"
+		isSystemNS := containsString(c.systemNamespaces, pod.Namespace)
 
 		total++
+		if !isSystemNS {
+			nsPods[pod.Namespace]++
+		}
 
+		missingMemoryLimit := false
 		for _, container := range pod.Spec.Containers {
 			hasRequests := container.Resources.Requests != nil &&
 				(container.Resources.Requests.Cpu() != nil || container.Resources.Requests.Memory() != nil)
@@ -68,6 +88,14 @@ func (c *ResourceRequests) Run(ctx context.Context, client kubernetes.Interface)
 			if !hasLimits {
 				noLimits++
 			}
+
+			if _, ok := container.Resources.Limits[corev1.ResourceMemory]; !ok {
+				missingMemoryLimit = true
+			}
+		}
+
+		if missingMemoryLimit && !isSystemNS {
+			nsNoMemoryLimit[pod.Namespace]++
 		}
 	}
 
@@ -77,14 +105,17 @@ func (c *ResourceRequests) Run(ctx context.Context, client kubernetes.Interface)
 				CheckName:	c.Name(),
 				Severity:	probe.SeverityWarning,
 				Message:	fmt.Sprintf("Namespace %s has %d containers without resource requests", ns, count),
+				Namespace:	ns,
 				Remediation: "Set resource requests for better scheduling: " +
 					"resources: { requests: { cpu: '100m', memory: '128Mi' } }",
 			})
 		}
 	}
 
+	memoryLimitNamespaces := c.checkMemoryLimits(nsPods, nsNoMemoryLimit, result)
+
 	severity := probe.SeverityOK
-	if noRequests > total/2 {
+	if noRequests > total/2 || memoryLimitNamespaces > 0 {
 		severity = probe.SeverityWarning
 	}
 
@@ -100,3 +131,37 @@ func (c *ResourceRequests) Run(ctx context.Context, client kubernetes.Interface)
 
 	return result, nil
 }
+
+func (c *ResourceRequests) checkMemoryLimits(nsPods, nsNoMemoryLimit map[string]int, result *probe.CheckResult) int {
+	namespaces := make([]string, 0, len(nsNoMemoryLimit))
+	for ns := range nsNoMemoryLimit {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	flagged := 0
+	for _, ns := range namespaces {
+		pods := nsPods[ns]
+		limitless := nsNoMemoryLimit[ns]
+		if pods < memoryLimitMinPods || limitless*100 < pods*c.noMemoryLimitPercent {
+			continue
+		}
+		flagged++
+		result.Results = append(result.Results, probe.Result{
+			CheckName:	c.Name(),
+			Severity:	probe.SeverityWarning,
+			Message:	fmt.Sprintf("Namespace %s has %d/%d pods without a memory limit", ns, limitless, pods),
+			Namespace:	ns,
+			Details: []string{
+				fmt.Sprintf("Threshold: %d%% of pods", c.noMemoryLimitPercent),
+				"Memory is not compressible: a pod without a limit can exhaust its node, triggering OOM kills and evictions of its neighbors",
+			},
+			Remediation: "Set memory limits on every container, or add a LimitRange with a default memory limit for the namespace: " +
+				"resources: { limits: { memory: '512Mi' } }",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl get pods -n %s -o custom-columns=NAME:.metadata.name,MEMORY_LIMIT:.spec.containers[*].resources.limits.memory", ns),
+			},
+		})
+	}
+	return flagged
+}
@@ -0,0 +1,275 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+)
+
+var credentialSecretTypes = []corev1.SecretType{
+	corev1.SecretTypeDockerConfigJson,
+	corev1.SecretTypeDockercfg,
+	corev1.SecretTypeBasicAuth,
+}
+
+type credentialSecret struct {
+	meta       metav1.ObjectMeta
+	secretType corev1.SecretType
+}
+
+type SecretReferences struct {
+	systemNamespaces []string
+}
+
+func NewSecretReferences() *SecretReferences {
+	return &SecretReferences{
+		systemNamespaces: []string{"kube-system"},
+	}
+}
+
+func (c *SecretReferences) Name() string {
+	return "secret-references"
+}
+
+func (c *SecretReferences) Tier() int {
+	return 2
+}
+
+func (c *SecretReferences) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
+func (c *SecretReferences) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, nil)
+}
+
+func (c *SecretReferences) RunMetadata(ctx context.Context, client kubernetes.Interface, metadataClient metadata.Interface) (*probe.CheckResult, error) {
+	return c.run(ctx, client, metadataClient)
+}
+
+func (c *SecretReferences) run(ctx context.Context, client kubernetes.Interface, metadataClient metadata.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	secrets, err := listMetadata(ctx, client, metadataClient, secretsResource, metav1.ListOptions{})
+	if err != nil {
+		if reason, ok := secretsUnavailable(err); ok {
+			skipped := probe.Result{
+				CheckName: c.Name(),
+				Severity:  probe.SeverityInfo,
+				Message:   "Secret references not verified: " + reason,
+			}
+			if errors.IsForbidden(err) {
+				skipped.Details = []string{"The read-only probe credentials intentionally exclude secrets access"}
+			}
+			result.Results = append(result.Results, skipped)
+			return result, nil
+		}
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	credentials, err := c.listCredentialSecrets(ctx, client, metadataClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credential secrets: %w", err)
+	}
+
+	pods, err := listPods(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	ingresses, err := client.NetworkingV1().Ingresses("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	serviceAccounts, err := client.CoreV1().ServiceAccounts("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+
+	existing := make(map[string]bool, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		existing[secret.Namespace+"/"+secret.Name] = true
+	}
+
+	references := make(map[string][]string)
+	addRef := func(namespace, name, referrer string) {
+		if name == "" {
+			return
+		}
+		key := namespace + "/" + name
+		references[key] = append(references[key], referrer)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		c.collectPodReferences(&pod, addRef)
+	}
+
+	for _, ing := range ingresses.Items {
+		for _, tls := range ing.Spec.TLS {
+			addRef(ing.Namespace, tls.SecretName, fmt.Sprintf("Ingress %s/%s TLS", ing.Namespace, ing.Name))
+		}
+	}
+
+	keys := make([]string, 0, len(references))
+	for key := range references {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	missing := 0
+	for _, key := range keys {
+		if existing[key] {
+			continue
+		}
+		missing++
+		namespace, _, _ := strings.Cut(key, "/")
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("Secret %s is referenced but does not exist", key),
+			Namespace:   namespace,
+			Details:     references[key],
+			Remediation: "Create the missing secret or remove the stale reference",
+		})
+	}
+
+	used := make(map[string]bool, len(references))
+	for key := range references {
+		used[key] = true
+	}
+	for _, sa := range serviceAccounts.Items {
+		for _, ips := range sa.ImagePullSecrets {
+			used[sa.Namespace+"/"+ips.Name] = true
+		}
+	}
+
+	unused := 0
+	details := []string{
+		fmt.Sprintf("Existing secrets: %d", len(secrets.Items)),
+	}
+	if probe.IsSampled(pods.ListMeta) {
+		details = append(details, "Unused credential detection skipped: the pod list was sampled by --resource-budget")
+	} else {
+		unused = c.checkUnusedCredentials(credentials, used, result)
+	}
+
+	severity := probe.SeverityOK
+	if missing > 0 || unused > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Secret references: %d referenced, %d missing, %d unused credentials", len(references), missing, unused),
+		Details:   details,
+	})
+
+	return result, nil
+}
+
+func (c *SecretReferences) listCredentialSecrets(ctx context.Context, client kubernetes.Interface, metadataClient metadata.Interface) ([]credentialSecret, error) {
+	var credentials []credentialSecret
+	for _, secretType := range credentialSecretTypes {
+		list, err := listMetadata(ctx, client, metadataClient, secretsResource, metav1.ListOptions{
+			FieldSelector: "type=" + string(secretType),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			credentials = append(credentials, credentialSecret{meta: item.ObjectMeta, secretType: secretType})
+		}
+	}
+	sort.Slice(credentials, func(i, j int) bool {
+		if credentials[i].meta.Namespace != credentials[j].meta.Namespace {
+			return credentials[i].meta.Namespace < credentials[j].meta.Namespace
+		}
+		return credentials[i].meta.Name < credentials[j].meta.Name
+	})
+	return credentials, nil
+}
+
+func (c *SecretReferences) checkUnusedCredentials(credentials []credentialSecret, used map[string]bool, result *probe.CheckResult) int {
+	unused := 0
+	for _, secret := range credentials {
+		if containsString(c.systemNamespaces, secret.meta.Namespace) {
+			continue
+		}
+		key := secret.meta.Namespace + "/" + secret.meta.Name
+		if used[key] {
+			continue
+		}
+		unused++
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityWarning,
+			Message:   fmt.Sprintf("Secret %s (%s) is not used by any pod or service account", key, secret.secretType),
+			Namespace: secret.meta.Namespace,
+			Details: []string{
+				fmt.Sprintf("Created: %s", secret.meta.CreationTimestamp.UTC().Format("2006-01-02")),
+			},
+			Remediation: "Delete leftover registry and basic-auth credentials that nothing references",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl delete secret -n %s %s", secret.meta.Namespace, secret.meta.Name),
+			},
+		})
+	}
+	return unused
+}
+
+func (c *SecretReferences) collectPodReferences(pod *corev1.Pod, addRef func(namespace, name, referrer string)) {
+	podRef := fmt.Sprintf("Pod %s/%s", pod.Namespace, pod.Name)
+
+	for _, vol := range pod.Spec.Volumes {
+		if vol.Secret != nil && !isOptional(vol.Secret.Optional) {
+			addRef(pod.Namespace, vol.Secret.SecretName, fmt.Sprintf("%s volume %s", podRef, vol.Name))
+		}
+		if vol.Projected != nil {
+			for _, source := range vol.Projected.Sources {
+				if source.Secret != nil && !isOptional(source.Secret.Optional) {
+					addRef(pod.Namespace, source.Secret.Name, fmt.Sprintf("%s projected volume %s", podRef, vol.Name))
+				}
+			}
+		}
+	}
+
+	for _, ips := range pod.Spec.ImagePullSecrets {
+		addRef(pod.Namespace, ips.Name, fmt.Sprintf("%s imagePullSecrets", podRef))
+	}
+
+	allContainers := append(pod.Spec.InitContainers, pod.Spec.Containers...)
+	for _, container := range allContainers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil && !isOptional(envFrom.SecretRef.Optional) {
+				addRef(pod.Namespace, envFrom.SecretRef.Name, fmt.Sprintf("%s container %s envFrom", podRef, container.Name))
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && !isOptional(env.ValueFrom.SecretKeyRef.Optional) {
+				addRef(pod.Namespace, env.ValueFrom.SecretKeyRef.Name, fmt.Sprintf("%s container %s env %s", podRef, container.Name, env.Name))
+			}
+		}
+	}
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
@@ -5,15 +5,19 @@ import (
 	"fmt"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-type SecretsUsage struct{}
+type SecretsUsage struct {
+	systemNamespaces []string
+}
 
 func NewSecretsUsage() *SecretsUsage {
-	return &SecretsUsage{}
+	return &SecretsUsage{
+		systemNamespaces: []string{"kube-system"},
+	}
 }
 
 func (c *SecretsUsage) Name() string {
@@ -24,6 +28,10 @@ func (c *SecretsUsage) Tier() int {
 	return 5
 }
 
+func (c *SecretsUsage) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
 func (c *SecretsUsage) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
@@ -31,7 +39,7 @@ func (c *SecretsUsage) Run(ctx context.Context, client kubernetes.Interface) (*p
 		Results:	[]probe.Result{},
 	}
 
-	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	pods, err := listPods(ctx, client, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
@@ -53,7 +61,7 @@ func (c *SecretsUsage) Run(ctx context.Context, client kubernetes.Interface) (*p
 			continue
 		}
 
-		if pod.Namespace == "kube-system" {
+		if containsString(c.systemNamespaces, pod.Namespace) {
 			continue
 		}
 
@@ -84,6 +92,7 @@ func (c *SecretsUsage) Run(ctx context.Context, client kubernetes.Interface) (*p
 						CheckName:	c.Name(),
 						Severity:	probe.SeverityWarning,
 						Message:	fmt.Sprintf("Container %s in pod %s/%s uses envFrom with secret", container.Name, pod.Namespace, pod.Name),
+						Namespace:	pod.Namespace,
 						Details: []string{
 							fmt.Sprintf("Secret: %s", envFrom.SecretRef.Name),
 							"Exposing entire secrets as environment variables may leak sensitive data",
@@ -110,6 +119,7 @@ func (c *SecretsUsage) Run(ctx context.Context, client kubernetes.Interface) (*p
 				CheckName:	c.Name(),
 				Severity:	probe.SeverityWarning,
 				Message:	fmt.Sprintf("Pod %s/%s exposes secrets via environment variables", pod.Namespace, pod.Name),
+				Namespace:	pod.Namespace,
 				Details: []string{
 					"Secrets in env vars may appear in logs, process listings, or crash dumps",
 				},
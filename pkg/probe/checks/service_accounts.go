@@ -5,14 +5,19 @@ import (
 	"fmt"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-type ServiceAccounts struct{}
+type ServiceAccounts struct {
+	systemNamespaces []string
+}
 
 func NewServiceAccounts() *ServiceAccounts {
-	return &ServiceAccounts{}
+	return &ServiceAccounts{
+		systemNamespaces: []string{"kube-system"},
+	}
 }
 
 func (c *ServiceAccounts) Name() string {
@@ -23,6 +28,10 @@ func (c *ServiceAccounts) Tier() int {
 	return 5
 }
 
+func (c *ServiceAccounts) Configure(cfg *config.Config) {
+	c.systemNamespaces = cfg.GetSystemNamespaces()
+}
+
 func (c *ServiceAccounts) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
 	result := &probe.CheckResult{
 		Name:		c.Name(),
@@ -35,7 +44,7 @@ func (c *ServiceAccounts) Run(ctx context.Context, client kubernetes.Interface)
 		return nil, fmt.Errorf("failed to list service accounts: %w", err)
 	}
 
-	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	pods, err := listPods(ctx, client, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
@@ -47,7 +56,7 @@ func (c *ServiceAccounts) Run(ctx context.Context, client kubernetes.Interface)
 		key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Spec.ServiceAccountName)
 		saUsage[key]++
 
-		if pod.Spec.ServiceAccountName == "default" && pod.Namespace != "kube-system" {
+		if pod.Spec.ServiceAccountName == "default" && !containsString(c.systemNamespaces, pod.Namespace) {
 			defaultSAUsage++
 		}
 	}
@@ -71,7 +80,7 @@ func (c *ServiceAccounts) Run(ctx context.Context, client kubernetes.Interface)
 			stats.autoMountDisabled++
 		}
 
-		if sa.Name != "default" && sa.Namespace != "kube-system" {
+		if sa.Name != "default" && !containsString(c.systemNamespaces, sa.Namespace) {
 			key := fmt.Sprintf("%s/%s", sa.Namespace, sa.Name)
 			if saUsage[key] == 0 {
 				stats.unusedSAs++
@@ -93,7 +102,7 @@ func (c *ServiceAccounts) Run(ctx context.Context, client kubernetes.Interface)
 	}
 
 	for _, sa := range serviceAccounts.Items {
-		if sa.Namespace == "kube-system" {
+		if containsString(c.systemNamespaces, sa.Namespace) {
 			continue
 		}
 
@@ -102,6 +111,7 @@ func (c *ServiceAccounts) Run(ctx context.Context, client kubernetes.Interface)
 				CheckName:	c.Name(),
 				Severity:	probe.SeverityWarning,
 				Message:	fmt.Sprintf("ServiceAccount %s/%s has %d secrets attached", sa.Namespace, sa.Name, len(sa.Secrets)),
+				Namespace:	sa.Namespace,
 				Details: []string{
 					"Multiple secrets may indicate unused tokens or complex configurations",
 				},
@@ -3,13 +3,22 @@ package checks
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	deprecatedTopologyHintsAnnotation	= "service.kubernetes.io/topology-aware-hints"
+	excludeFromLoadBalancersLabel		= "node.kubernetes.io/exclude-from-external-load-balancers"
+)
+
 type ServiceEndpoints struct{}
 
 func NewServiceEndpoints() *ServiceEndpoints {
@@ -41,6 +50,19 @@ func (c *ServiceEndpoints) Run(ctx context.Context, client kubernetes.Interface)
 		return nil, fmt.Errorf("failed to list endpoints: %w", err)
 	}
 
+	pods, err := listPods(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	sliceFamilies, slicesListed := c.endpointSliceFamilies(ctx, client)
+
+	podMap := make(map[string]*corev1.Pod)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		podMap[fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)] = pod
+	}
+
 	endpointMap := make(map[string]*corev1.Endpoints)
 	for i := range endpoints.Items {
 		ep := &endpoints.Items[i]
@@ -52,9 +74,32 @@ func (c *ServiceEndpoints) Run(ctx context.Context, client kubernetes.Interface)
 	withoutEndpoints := 0
 	externalName := 0
 	headless := 0
+	portMismatches := 0
+	deprecatedTopology := 0
+	missingFamilies := 0
+	localPolicyGaps := 0
+
+	var lbNodes []string
+	lbNodesListed := false
 
 	for _, svc := range services.Items {
 
+		if value, ok := svc.Annotations[deprecatedTopologyHintsAnnotation]; ok {
+			deprecatedTopology++
+			result.Results = append(result.Results, probe.Result{
+				CheckName:	c.Name(),
+				Severity:	probe.SeverityWarning,
+				Message:	fmt.Sprintf("Service %s/%s uses deprecated topology annotation %s", svc.Namespace, svc.Name, deprecatedTopologyHintsAnnotation),
+				Namespace:	svc.Namespace,
+				Details: []string{
+					fmt.Sprintf("%s: %s", deprecatedTopologyHintsAnnotation, value),
+					"Topology-aware routing is now configured with spec.trafficDistribution or the service.kubernetes.io/topology-mode annotation",
+				},
+				Remediation:		"Replace the annotation with spec.trafficDistribution: PreferClose (or service.kubernetes.io/topology-mode: Auto on older clusters)",
+				RemediationCommands:	[]string{fmt.Sprintf("kubectl annotate service -n %s %s %s-", svc.Namespace, svc.Name, deprecatedTopologyHintsAnnotation)},
+			})
+		}
+
 		if svc.Spec.Type == corev1.ServiceTypeExternalName {
 			externalName++
 			continue
@@ -84,6 +129,54 @@ func (c *ServiceEndpoints) Run(ctx context.Context, client kubernetes.Interface)
 
 		if hasEndpoints {
 			withEndpoints++
+
+			if slicesListed {
+				if missing := c.missingIPFamilies(&svc, sliceFamilies[key]); len(missing) > 0 {
+					missingFamilies++
+					families := make([]string, 0, len(svc.Spec.IPFamilies))
+					for _, family := range svc.Spec.IPFamilies {
+						families = append(families, string(family))
+					}
+					result.Results = append(result.Results, probe.Result{
+						CheckName:	c.Name(),
+						Severity:	probe.SeverityWarning,
+						Message:	fmt.Sprintf("Service %s/%s has no %s endpoints", svc.Namespace, svc.Name, strings.Join(missing, " or ")),
+						Namespace:	svc.Namespace,
+						Details: []string{
+							fmt.Sprintf("ipFamilies: %s", strings.Join(families, ", ")),
+							"Clients connecting over the missing family get no backends even though the Service has a cluster IP for it",
+						},
+						Remediation:		"Check that the backing pods have addresses in every family the Service declares, or set ipFamilyPolicy: SingleStack",
+						RemediationCommands:	[]string{fmt.Sprintf("kubectl get endpointslices -n %s -l kubernetes.io/service-name=%s", svc.Namespace, svc.Name)},
+					})
+				}
+			}
+
+			backing := c.backingPods(ep, podMap)
+
+			if svc.Spec.Type == corev1.ServiceTypeLoadBalancer && svc.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal {
+				if !lbNodesListed {
+					lbNodes = c.loadBalancerNodes(ctx, client)
+					lbNodesListed = true
+				}
+				if missing := c.nodesWithoutLocalEndpoints(ep, backing, lbNodes); len(missing) > 0 {
+					localPolicyGaps++
+					result.Results = append(result.Results, c.localPolicyResult(&svc, missing, len(lbNodes)))
+				}
+			}
+
+			for _, mismatch := range c.findPortMismatches(&svc, backing) {
+				portMismatches++
+				result.Results = append(result.Results, probe.Result{
+					CheckName:		c.Name(),
+					Severity:		probe.SeverityWarning,
+					Message:		fmt.Sprintf("Service %s/%s targetPort %d does not match any container port", svc.Namespace, svc.Name, mismatch.targetPort),
+					Namespace:		svc.Namespace,
+					Details:		mismatch.details,
+					Remediation:		"Set the service targetPort to a port the backing containers listen on",
+					RemediationCommands:	[]string{fmt.Sprintf("kubectl describe service -n %s %s", svc.Namespace, svc.Name)},
+				})
+			}
 		} else {
 			withoutEndpoints++
 
@@ -105,6 +198,7 @@ func (c *ServiceEndpoints) Run(ctx context.Context, client kubernetes.Interface)
 				CheckName:	c.Name(),
 				Severity:	severity,
 				Message:	fmt.Sprintf("Service %s/%s has no endpoints", svc.Namespace, svc.Name),
+				Namespace:	svc.Namespace,
 				Details:	details,
 				Remediation:	"Check that pods matching the service selector exist and are ready",
 			})
@@ -112,7 +206,7 @@ func (c *ServiceEndpoints) Run(ctx context.Context, client kubernetes.Interface)
 	}
 
 	severity := probe.SeverityOK
-	if withoutEndpoints > 0 {
+	if withoutEndpoints > 0 || portMismatches > 0 || deprecatedTopology > 0 || missingFamilies > 0 || localPolicyGaps > 0 {
 		severity = probe.SeverityWarning
 	}
 
@@ -124,8 +218,215 @@ func (c *ServiceEndpoints) Run(ctx context.Context, client kubernetes.Interface)
 			fmt.Sprintf("Total services: %d", len(services.Items)),
 			fmt.Sprintf("ExternalName: %d", externalName),
 			fmt.Sprintf("Headless: %d", headless),
+			fmt.Sprintf("Target port mismatches: %d", portMismatches),
+			fmt.Sprintf("Deprecated topology annotations: %d", deprecatedTopology),
+			fmt.Sprintf("Dual-stack services missing an address family: %d", missingFamilies),
+			fmt.Sprintf("Local traffic policy services missing node endpoints: %d", localPolicyGaps),
 		},
 	})
 
 	return result, nil
 }
+
+func (c *ServiceEndpoints) endpointSliceFamilies(ctx context.Context, client kubernetes.Interface) (map[string]map[corev1.IPFamily]bool, bool) {
+	slices, err := client.DiscoveryV1().EndpointSlices("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, false
+	}
+
+	families := make(map[string]map[corev1.IPFamily]bool)
+	for _, slice := range slices.Items {
+		service := slice.Labels[discoveryv1.LabelServiceName]
+		if service == "" || len(slice.Endpoints) == 0 {
+			continue
+		}
+		var family corev1.IPFamily
+		switch slice.AddressType {
+		case discoveryv1.AddressTypeIPv4:
+			family = corev1.IPv4Protocol
+		case discoveryv1.AddressTypeIPv6:
+			family = corev1.IPv6Protocol
+		default:
+			continue
+		}
+		key := fmt.Sprintf("%s/%s", slice.Namespace, service)
+		if families[key] == nil {
+			families[key] = make(map[corev1.IPFamily]bool)
+		}
+		families[key][family] = true
+	}
+	return families, true
+}
+
+func (c *ServiceEndpoints) missingIPFamilies(svc *corev1.Service, present map[corev1.IPFamily]bool) []string {
+	if len(svc.Spec.IPFamilies) < 2 || len(present) == 0 {
+		return nil
+	}
+	var missing []string
+	for _, family := range svc.Spec.IPFamilies {
+		if !present[family] {
+			missing = append(missing, string(family))
+		}
+	}
+	return missing
+}
+
+func (c *ServiceEndpoints) loadBalancerNodes(ctx context.Context, client kubernetes.Interface) []string {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+
+	names := []string{}
+	for _, node := range nodes.Items {
+		if _, excluded := node.Labels[excludeFromLoadBalancersLabel]; excluded {
+			continue
+		}
+		ready := false
+		for _, cond := range node.Status.Conditions {
+			if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue {
+				ready = true
+				break
+			}
+		}
+		if ready {
+			names = append(names, node.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *ServiceEndpoints) nodesWithoutLocalEndpoints(ep *corev1.Endpoints, backing []*corev1.Pod, nodes []string) []string {
+	local := make(map[string]bool)
+	for _, subset := range ep.Subsets {
+		for _, addr := range subset.Addresses {
+			if addr.NodeName != nil && *addr.NodeName != "" {
+				local[*addr.NodeName] = true
+			}
+		}
+	}
+	for _, pod := range backing {
+		if pod.Spec.NodeName != "" {
+			local[pod.Spec.NodeName] = true
+		}
+	}
+	if len(local) == 0 {
+		return nil
+	}
+
+	missing := []string{}
+	for _, node := range nodes {
+		if !local[node] {
+			missing = append(missing, node)
+		}
+	}
+	return missing
+}
+
+func (c *ServiceEndpoints) localPolicyResult(svc *corev1.Service, missing []string, totalNodes int) probe.Result {
+	shown := missing
+	if len(shown) > 5 {
+		shown = shown[:5]
+	}
+	nodeList := strings.Join(shown, ", ")
+	if len(missing) > len(shown) {
+		nodeList += fmt.Sprintf(" (+%d more)", len(missing)-len(shown))
+	}
+
+	details := []string{
+		fmt.Sprintf("Nodes without a local endpoint: %s", nodeList),
+		"With externalTrafficPolicy: Local, a node only forwards traffic to pods running on that node and drops it otherwise",
+	}
+	if svc.Spec.HealthCheckNodePort != 0 {
+		details = append(details, fmt.Sprintf("healthCheckNodePort: %d (load balancers that ignore it will send traffic to these nodes)", svc.Spec.HealthCheckNodePort))
+	}
+
+	return probe.Result{
+		CheckName:	c.Name(),
+		Severity:	probe.SeverityWarning,
+		Message:	fmt.Sprintf("Service %s/%s uses externalTrafficPolicy Local but %d of %d nodes have no local endpoint", svc.Namespace, svc.Name, len(missing), totalNodes),
+		Namespace:	svc.Namespace,
+		Details:	details,
+		Remediation:	"Make sure the load balancer health-checks healthCheckNodePort, run a backing pod on every node, or set externalTrafficPolicy: Cluster if client source IPs need not be preserved",
+		RemediationCommands: []string{
+			fmt.Sprintf("kubectl get endpoints -n %s %s -o wide", svc.Namespace, svc.Name),
+			fmt.Sprintf("kubectl patch service -n %s %s -p '{\"spec\":{\"externalTrafficPolicy\":\"Cluster\"}}'", svc.Namespace, svc.Name),
+		},
+	}
+}
+
+type portMismatch struct {
+	targetPort	int32
+	details		[]string
+}
+
+func (c *ServiceEndpoints) backingPods(ep *corev1.Endpoints, podMap map[string]*corev1.Pod) []*corev1.Pod {
+	backing := []*corev1.Pod{}
+	for _, subset := range ep.Subsets {
+		for _, addr := range subset.Addresses {
+			if addr.TargetRef == nil || addr.TargetRef.Kind != "Pod" {
+				continue
+			}
+			if pod := podMap[fmt.Sprintf("%s/%s", addr.TargetRef.Namespace, addr.TargetRef.Name)]; pod != nil {
+				backing = append(backing, pod)
+			}
+		}
+	}
+	return backing
+}
+
+func (c *ServiceEndpoints) findPortMismatches(svc *corev1.Service, pods []*corev1.Pod) []portMismatch {
+	declared := make(map[string]bool)
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				declared[fmt.Sprintf("%d/%s", port.ContainerPort, protocolOrTCP(port.Protocol))] = true
+			}
+		}
+	}
+
+	if len(declared) == 0 {
+		return nil
+	}
+
+	containerPorts := make([]string, 0, len(declared))
+	for port := range declared {
+		containerPorts = append(containerPorts, port)
+	}
+	sort.Strings(containerPorts)
+
+	mismatches := []portMismatch{}
+	for _, port := range svc.Spec.Ports {
+		if port.TargetPort.Type == intstr.String {
+			continue
+		}
+
+		target := port.TargetPort.IntVal
+		if target == 0 {
+			target = port.Port
+		}
+
+		if declared[fmt.Sprintf("%d/%s", target, protocolOrTCP(port.Protocol))] {
+			continue
+		}
+
+		mismatches = append(mismatches, portMismatch{
+			targetPort:	target,
+			details: []string{
+				fmt.Sprintf("Service port: %d/%s -> targetPort %d", port.Port, protocolOrTCP(port.Protocol), target),
+				fmt.Sprintf("Container ports: %s", strings.Join(containerPorts, ", ")),
+				fmt.Sprintf("Backing pods: %d", len(pods)),
+			},
+		})
+	}
+
+	return mismatches
+}
+
+func protocolOrTCP(protocol corev1.Protocol) corev1.Protocol {
+	if protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return protocol
+}
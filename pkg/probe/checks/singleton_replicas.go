@@ -0,0 +1,147 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const leaderElectionAnnotation = "cluster-probe/leader-election"
+
+type SingletonReplicas struct {
+	deploymentNames []string
+}
+
+func NewSingletonReplicas() *SingletonReplicas {
+	return &SingletonReplicas{}
+}
+
+func (c *SingletonReplicas) Name() string {
+	return "singleton-replicas"
+}
+
+func (c *SingletonReplicas) Tier() int {
+	return 2
+}
+
+func (c *SingletonReplicas) OptIn() bool {
+	return true
+}
+
+func (c *SingletonReplicas) Configure(cfg *config.Config) {
+	c.deploymentNames = cfg.SingletonDeployments
+}
+
+func (c *SingletonReplicas) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	if len(c.deploymentNames) == 0 {
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityOK,
+			Message:   "Singleton replicas: no names configured in singleton_deployments",
+		})
+		return result, nil
+	}
+
+	deployments, err := client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	matched := 0
+	overScaled := 0
+	for i := range deployments.Items {
+		deploy := &deployments.Items[i]
+		if !c.isSingleton(deploy) {
+			continue
+		}
+		matched++
+
+		if deploy.Status.ReadyReplicas <= 1 || c.usesLeaderElection(deploy) {
+			continue
+		}
+		overScaled++
+
+		result.Results = append(result.Results, probe.Result{
+			CheckName: c.Name(),
+			Severity:  probe.SeverityWarning,
+			Message:   fmt.Sprintf("Deployment %s/%s must run as a singleton but has %d ready replicas", deploy.Namespace, deploy.Name, deploy.Status.ReadyReplicas),
+			Namespace: deploy.Namespace,
+			Details: []string{
+				fmt.Sprintf("Spec replicas: %d", specReplicas(deploy.Spec.Replicas)),
+				"No leader election was found: no --leader-elect flag and no " + leaderElectionAnnotation + " annotation",
+				"Several active copies of a singleton controller can reconcile the same objects concurrently and conflict",
+			},
+			Remediation: fmt.Sprintf("Scale the Deployment back to one replica, or enable leader election and annotate it with %s=true", leaderElectionAnnotation),
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl scale deployment -n %s %s --replicas=1", deploy.Namespace, deploy.Name),
+			},
+		})
+	}
+
+	severity := probe.SeverityOK
+	if overScaled > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Singleton replicas: %d of %d singleton Deployments run more than one ready replica", overScaled, matched),
+	})
+
+	return result, nil
+}
+
+func (c *SingletonReplicas) isSingleton(deploy *appsv1.Deployment) bool {
+	qualified := deploy.Namespace + "/" + deploy.Name
+	for _, name := range c.deploymentNames {
+		if name == deploy.Name || name == qualified {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *SingletonReplicas) usesLeaderElection(deploy *appsv1.Deployment) bool {
+	for _, annotations := range []map[string]string{deploy.Annotations, deploy.Spec.Template.Annotations} {
+		if value, ok := annotations[leaderElectionAnnotation]; ok && value != "false" {
+			return true
+		}
+	}
+
+	for _, container := range deploy.Spec.Template.Spec.Containers {
+		for _, arg := range append(append([]string{}, container.Command...), container.Args...) {
+			if isLeaderElectFlag(arg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isLeaderElectFlag(arg string) bool {
+	name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	if name != "leader-elect" && name != "enable-leader-election" && name != "leader-election" {
+		return false
+	}
+	return !hasValue || value != "false"
+}
+
+func specReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -18,25 +19,47 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	terminatingPodThreshold      = 5 * time.Minute
+	deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
 type stalledStats struct {
 	pendingPods        int
 	backoffPods        int
+	terminatingPods    int
 	pendingPVCs        int
 	pendingPVs         int
 	stalledDeploys     int
 	stalledStateful    int
 	stalledDaemonSets  int
 	stalledReplicaSets int
+	staleReplicaSets   int
 	backoffJobs        int
 	stalledCRs         int
+	scannedCRTypes     int
 }
 
-type StalledResources struct{}
+type StalledResources struct {
+	ignoreKinds map[string]bool
+	customOnly  bool
+}
 
 func NewStalledResources() *StalledResources {
 	return &StalledResources{}
 }
 
+func NewStalledCustomResources() *StalledResources {
+	return &StalledResources{customOnly: true}
+}
+
+func (c *StalledResources) Configure(cfg *config.Config) {
+	c.ignoreKinds = make(map[string]bool, len(cfg.StalledResources.IgnoreKinds))
+	for _, kind := range cfg.StalledResources.IgnoreKinds {
+		c.ignoreKinds[strings.ToLower(kind)] = true
+	}
+}
+
 func (c *StalledResources) Name() string {
 	return "stalled-resources"
 }
@@ -54,14 +77,9 @@ func (c *StalledResources) Run(ctx context.Context, client kubernetes.Interface)
 
 	stats := &stalledStats{}
 
-	c.checkPods(ctx, client, result, stats)
-	c.checkPVCs(ctx, client, result, stats)
-	c.checkPVs(ctx, client, result, stats)
-	c.checkDeployments(ctx, client, result, stats)
-	c.checkStatefulSets(ctx, client, result, stats)
-	c.checkDaemonSets(ctx, client, result, stats)
-	c.checkReplicaSets(ctx, client, result, stats)
-	c.checkJobs(ctx, client, result, stats)
+	if !c.customOnly {
+		c.checkCoreResources(ctx, client, result, stats)
+	}
 
 	c.appendSummary(result, stats)
 
@@ -77,6 +95,18 @@ func (c *StalledResources) RunDynamic(ctx context.Context, client kubernetes.Int
 
 	stats := &stalledStats{}
 
+	if !c.customOnly {
+		c.checkCoreResources(ctx, client, result, stats)
+	}
+
+	c.checkCustomResources(ctx, dynamicClient, discoveryClient, result, stats)
+
+	c.appendSummary(result, stats)
+
+	return result, nil
+}
+
+func (c *StalledResources) checkCoreResources(ctx context.Context, client kubernetes.Interface, result *probe.CheckResult, stats *stalledStats) {
 	c.checkPods(ctx, client, result, stats)
 	c.checkPVCs(ctx, client, result, stats)
 	c.checkPVs(ctx, client, result, stats)
@@ -84,19 +114,14 @@ func (c *StalledResources) RunDynamic(ctx context.Context, client kubernetes.Int
 	c.checkStatefulSets(ctx, client, result, stats)
 	c.checkDaemonSets(ctx, client, result, stats)
 	c.checkReplicaSets(ctx, client, result, stats)
+	c.checkStaleReplicaSets(ctx, client, result, stats)
 	c.checkJobs(ctx, client, result, stats)
-
-	c.checkCustomResources(ctx, dynamicClient, discoveryClient, result, stats)
-
-	c.appendSummary(result, stats)
-
-	return result, nil
 }
 
 func (c *StalledResources) appendSummary(result *probe.CheckResult, stats *stalledStats) {
-	total := stats.pendingPods + stats.backoffPods + stats.pendingPVCs + stats.pendingPVs +
+	total := stats.pendingPods + stats.backoffPods + stats.terminatingPods + stats.pendingPVCs + stats.pendingPVs +
 		stats.stalledDeploys + stats.stalledStateful + stats.stalledDaemonSets +
-		stats.stalledReplicaSets + stats.backoffJobs + stats.stalledCRs
+		stats.stalledReplicaSets + stats.staleReplicaSets + stats.backoffJobs + stats.stalledCRs
 
 	severity := probe.SeverityOK
 	if total > 0 {
@@ -113,6 +138,9 @@ func (c *StalledResources) appendSummary(result *probe.CheckResult, stats *stall
 	if stats.backoffPods > 0 {
 		details = append(details, fmt.Sprintf("Backoff pods: %d", stats.backoffPods))
 	}
+	if stats.terminatingPods > 0 {
+		details = append(details, fmt.Sprintf("Stuck terminating pods: %d", stats.terminatingPods))
+	}
 	if stats.pendingPVCs > 0 {
 		details = append(details, fmt.Sprintf("Pending PVCs: %d", stats.pendingPVCs))
 	}
@@ -131,12 +159,18 @@ func (c *StalledResources) appendSummary(result *probe.CheckResult, stats *stall
 	if stats.stalledReplicaSets > 0 {
 		details = append(details, fmt.Sprintf("Stalled ReplicaSets: %d", stats.stalledReplicaSets))
 	}
+	if stats.staleReplicaSets > 0 {
+		details = append(details, fmt.Sprintf("Stale ReplicaSets holding pods: %d", stats.staleReplicaSets))
+	}
 	if stats.backoffJobs > 0 {
 		details = append(details, fmt.Sprintf("Backoff Jobs: %d", stats.backoffJobs))
 	}
 	if stats.stalledCRs > 0 {
 		details = append(details, fmt.Sprintf("Stalled custom resources: %d", stats.stalledCRs))
 	}
+	if c.customOnly {
+		details = append(details, fmt.Sprintf("Custom resource types scanned: %d", stats.scannedCRTypes))
+	}
 
 	if len(details) == 0 {
 		details = append(details, "No stalled resources found")
@@ -201,6 +235,7 @@ func (c *StalledResources) checkCustomResources(ctx context.Context, dynamicClie
 				Resource: apiResource.Name,
 			}
 
+			stats.scannedCRTypes++
 			c.checkResourcesForGVR(ctx, dynamicClient, gvr, apiResource.Namespaced, apiResource.Kind, result, stats)
 		}
 	}
@@ -235,6 +270,10 @@ func (c *StalledResources) checkResourcesForGVR(ctx context.Context, dynamicClie
 }
 
 func (c *StalledResources) checkResourceStatus(item *unstructured.Unstructured, kind string, gvr schema.GroupVersionResource, result *probe.CheckResult, stats *stalledStats) {
+	if c.isIgnoredKind(kind, gvr.Group) {
+		return
+	}
+
 	status, found, err := unstructured.NestedMap(item.Object, "status")
 	if err != nil || !found {
 		return
@@ -251,8 +290,12 @@ func (c *StalledResources) checkResourceStatus(item *unstructured.Unstructured,
 				CheckName:   c.Name(),
 				Severity:    probe.SeverityWarning,
 				Message:     fmt.Sprintf("%s in %s phase", resourceID, phase),
+				Namespace:   namespace,
 				Details:     c.extractStatusDetails(status),
-				Remediation: fmt.Sprintf("kubectl describe %s %s", c.formatResourceType(kind, gvr, namespace), c.formatResourceRef(namespace, name)),
+				Remediation: "Inspect the resource status and events",
+				RemediationCommands: []string{
+					fmt.Sprintf("kubectl describe %s %s", c.formatResourceType(kind, gvr, namespace), c.formatResourceRef(namespace, name)),
+				},
 			})
 			return
 		}
@@ -265,8 +308,12 @@ func (c *StalledResources) checkResourceStatus(item *unstructured.Unstructured,
 				CheckName:   c.Name(),
 				Severity:    probe.SeverityWarning,
 				Message:     fmt.Sprintf("%s in %s state", resourceID, state),
+				Namespace:   namespace,
 				Details:     c.extractStatusDetails(status),
-				Remediation: fmt.Sprintf("kubectl describe %s %s", c.formatResourceType(kind, gvr, namespace), c.formatResourceRef(namespace, name)),
+				Remediation: "Inspect the resource status and events",
+				RemediationCommands: []string{
+					fmt.Sprintf("kubectl describe %s %s", c.formatResourceType(kind, gvr, namespace), c.formatResourceRef(namespace, name)),
+				},
 			})
 			return
 		}
@@ -291,8 +338,12 @@ func (c *StalledResources) checkResourceStatus(item *unstructured.Unstructured,
 					CheckName:   c.Name(),
 					Severity:    probe.SeverityWarning,
 					Message:     fmt.Sprintf("%s has %s=%s", resourceID, condType, condStatus),
+					Namespace:   namespace,
 					Details:     details,
-					Remediation: fmt.Sprintf("kubectl describe %s %s", c.formatResourceType(kind, gvr, namespace), c.formatResourceRef(namespace, name)),
+					Remediation: "Inspect the resource conditions and events",
+					RemediationCommands: []string{
+						fmt.Sprintf("kubectl describe %s %s", c.formatResourceType(kind, gvr, namespace), c.formatResourceRef(namespace, name)),
+					},
 				})
 				return
 			}
@@ -300,6 +351,14 @@ func (c *StalledResources) checkResourceStatus(item *unstructured.Unstructured,
 	}
 }
 
+func (c *StalledResources) isIgnoredKind(kind, group string) bool {
+	kind = strings.ToLower(kind)
+	if c.ignoreKinds[kind] {
+		return true
+	}
+	return group != "" && c.ignoreKinds[kind+"."+group]
+}
+
 func (c *StalledResources) isStalledPhase(phase string) bool {
 	stalledPhases := map[string]bool{
 		"Pending":      true,
@@ -409,12 +468,30 @@ func (c *StalledResources) formatResourceRef(namespace, name string) string {
 }
 
 func (c *StalledResources) checkPods(ctx context.Context, client kubernetes.Interface, result *probe.CheckResult, stats *stalledStats) {
-	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	pods, err := listPods(ctx, client, "")
 	if err != nil {
 		return
 	}
 
 	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			overdue := time.Since(pod.DeletionTimestamp.Time)
+			if overdue > terminatingPodThreshold {
+				stats.terminatingPods++
+				remediation, commands := c.getTerminatingRemediation(&pod)
+				result.Results = append(result.Results, probe.Result{
+					CheckName:           c.Name(),
+					Severity:            probe.SeverityWarning,
+					Message:             fmt.Sprintf("Pod %s/%s stuck terminating for %s past its grace period", pod.Namespace, pod.Name, stalledFormatDuration(overdue)),
+					Namespace:           pod.Namespace,
+					Details:             c.getTerminatingPodDetails(&pod),
+					Remediation:         remediation,
+					RemediationCommands: commands,
+				})
+			}
+			continue
+		}
+
 		if pod.Status.Phase == corev1.PodPending {
 			age := time.Since(pod.CreationTimestamp.Time)
 			if age > 5*time.Minute {
@@ -423,6 +500,7 @@ func (c *StalledResources) checkPods(ctx context.Context, client kubernetes.Inte
 					CheckName:   c.Name(),
 					Severity:    probe.SeverityWarning,
 					Message:     fmt.Sprintf("Pod %s/%s pending for %s", pod.Namespace, pod.Name, stalledFormatDuration(age)),
+					Namespace:   pod.Namespace,
 					Details:     c.getPendingPodDetails(&pod),
 					Remediation: "Check node resources, scheduling constraints, and pod events",
 				})
@@ -434,15 +512,18 @@ func (c *StalledResources) checkPods(ctx context.Context, client kubernetes.Inte
 				reason := cs.State.Waiting.Reason
 				if c.isBackoffReason(reason) {
 					stats.backoffPods++
+					remediation, commands := c.getBackoffRemediation(reason, &pod, cs)
 					result.Results = append(result.Results, probe.Result{
 						CheckName: c.Name(),
 						Severity:  probe.SeverityWarning,
 						Message:   fmt.Sprintf("Pod %s/%s container %s in %s", pod.Namespace, pod.Name, cs.Name, reason),
-						Details: []string{
+						Namespace: pod.Namespace,
+						Details: append([]string{
 							fmt.Sprintf("Restarts: %d", cs.RestartCount),
 							fmt.Sprintf("Message: %s", cs.State.Waiting.Message),
-						},
-						Remediation: c.getBackoffRemediation(reason, pod.Namespace, pod.Name, cs.Name),
+						}, terminationDetails(cs)...),
+						Remediation:         remediation,
+						RemediationCommands: commands,
 					})
 				}
 			}
@@ -453,15 +534,18 @@ func (c *StalledResources) checkPods(ctx context.Context, client kubernetes.Inte
 				reason := cs.State.Waiting.Reason
 				if c.isBackoffReason(reason) {
 					stats.backoffPods++
+					remediation, commands := c.getBackoffRemediation(reason, &pod, cs)
 					result.Results = append(result.Results, probe.Result{
 						CheckName: c.Name(),
 						Severity:  probe.SeverityWarning,
 						Message:   fmt.Sprintf("Pod %s/%s init container %s in %s", pod.Namespace, pod.Name, cs.Name, reason),
-						Details: []string{
+						Namespace: pod.Namespace,
+						Details: append([]string{
 							fmt.Sprintf("Restarts: %d", cs.RestartCount),
 							fmt.Sprintf("Message: %s", cs.State.Waiting.Message),
-						},
-						Remediation: c.getBackoffRemediation(reason, pod.Namespace, pod.Name, cs.Name),
+						}, terminationDetails(cs)...),
+						Remediation:         remediation,
+						RemediationCommands: commands,
 					})
 				}
 			}
@@ -490,6 +574,7 @@ func (c *StalledResources) checkPVCs(ctx context.Context, client kubernetes.Inte
 					CheckName:   c.Name(),
 					Severity:    probe.SeverityWarning,
 					Message:     fmt.Sprintf("PVC %s/%s pending for %s", pvc.Namespace, pvc.Name, stalledFormatDuration(age)),
+					Namespace:   pvc.Namespace,
 					Details:     details,
 					Remediation: "Check storage provisioner and available capacity",
 				})
@@ -545,6 +630,7 @@ func (c *StalledResources) checkDeployments(ctx context.Context, client kubernet
 						CheckName: c.Name(),
 						Severity:  probe.SeverityWarning,
 						Message:   fmt.Sprintf("Deployment %s/%s stalled with %d unavailable replicas", deploy.Namespace, deploy.Name, unavailable),
+						Namespace: deploy.Namespace,
 						Details: []string{
 							fmt.Sprintf("Desired: %d, Available: %d, Unavailable: %d", desired, deploy.Status.AvailableReplicas, unavailable),
 							fmt.Sprintf("Reason: %s", cond.Reason),
@@ -582,6 +668,7 @@ func (c *StalledResources) checkStatefulSets(ctx context.Context, client kuberne
 					CheckName: c.Name(),
 					Severity:  probe.SeverityWarning,
 					Message:   fmt.Sprintf("StatefulSet %s/%s has %d/%d ready replicas", sts.Namespace, sts.Name, ready, desired),
+					Namespace: sts.Namespace,
 					Details: []string{
 						fmt.Sprintf("Current: %d, Ready: %d, Desired: %d", sts.Status.CurrentReplicas, ready, desired),
 					},
@@ -608,6 +695,7 @@ func (c *StalledResources) checkDaemonSets(ctx context.Context, client kubernete
 					CheckName: c.Name(),
 					Severity:  probe.SeverityWarning,
 					Message:   fmt.Sprintf("DaemonSet %s/%s has %d unavailable pods", ds.Namespace, ds.Name, unavailable),
+					Namespace: ds.Namespace,
 					Details: []string{
 						fmt.Sprintf("Desired: %d, Ready: %d, Unavailable: %d", ds.Status.DesiredNumberScheduled, ds.Status.NumberReady, unavailable),
 					},
@@ -646,6 +734,7 @@ func (c *StalledResources) checkReplicaSets(ctx context.Context, client kubernet
 					CheckName: c.Name(),
 					Severity:  probe.SeverityWarning,
 					Message:   fmt.Sprintf("ReplicaSet %s/%s has %d/%d ready replicas", rs.Namespace, rs.Name, ready, desired),
+					Namespace: rs.Namespace,
 					Details: []string{
 						fmt.Sprintf("Replicas: %d, Ready: %d, Available: %d", rs.Status.Replicas, ready, rs.Status.AvailableReplicas),
 					},
@@ -656,6 +745,88 @@ func (c *StalledResources) checkReplicaSets(ctx context.Context, client kubernet
 	}
 }
 
+func (c *StalledResources) checkStaleReplicaSets(ctx context.Context, client kubernetes.Interface, result *probe.CheckResult, stats *stalledStats) {
+	deploys, err := client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return
+	}
+
+	replicasets, err := client.AppsV1().ReplicaSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return
+	}
+
+	staleByDeploy := make(map[string][]appsv1.ReplicaSet)
+	for _, rs := range replicasets.Items {
+		if rs.Status.Replicas == 0 {
+			continue
+		}
+		owner := metav1.GetControllerOf(&rs)
+		if owner == nil || owner.Kind != "Deployment" {
+			continue
+		}
+		key := rs.Namespace + "/" + owner.Name
+		staleByDeploy[key] = append(staleByDeploy[key], rs)
+	}
+
+	for _, deploy := range deploys.Items {
+		candidates := staleByDeploy[deploy.Namespace+"/"+deploy.Name]
+		if len(candidates) == 0 || !c.rolloutComplete(&deploy) {
+			continue
+		}
+
+		currentRevision := deploy.Annotations[deploymentRevisionAnnotation]
+		details := []string{fmt.Sprintf("Current revision: %s", currentRevision)}
+		stale := 0
+		for _, rs := range candidates {
+			revision := rs.Annotations[deploymentRevisionAnnotation]
+			if revision == "" || revision == currentRevision {
+				continue
+			}
+			stale++
+			details = append(details, fmt.Sprintf("%s/%s (revision %s): %d pods", rs.Namespace, rs.Name, revision, rs.Status.Replicas))
+		}
+
+		if stale == 0 {
+			continue
+		}
+
+		stats.staleReplicaSets += stale
+		result.Results = append(result.Results, probe.Result{
+			CheckName:   c.Name(),
+			Severity:    probe.SeverityWarning,
+			Message:     fmt.Sprintf("Deployment %s/%s has %d old ReplicaSets still holding pods after rollout completed", deploy.Namespace, deploy.Name, stale),
+			Namespace:   deploy.Namespace,
+			Details:     details,
+			Remediation: "Scale the old ReplicaSets to zero and check for controllers or manual edits keeping them alive",
+			RemediationCommands: []string{
+				fmt.Sprintf("kubectl get replicasets -n %s -o wide", deploy.Namespace),
+			},
+		})
+	}
+}
+
+func (c *StalledResources) rolloutComplete(deploy *appsv1.Deployment) bool {
+	if deploy.Status.ObservedGeneration < deploy.Generation {
+		return false
+	}
+
+	desired := int32(1)
+	if deploy.Spec.Replicas != nil {
+		desired = *deploy.Spec.Replicas
+	}
+	if deploy.Status.UpdatedReplicas < desired || deploy.Status.AvailableReplicas < desired {
+		return false
+	}
+
+	for _, cond := range deploy.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing {
+			return cond.Status == corev1.ConditionTrue && cond.Reason == "NewReplicaSetAvailable"
+		}
+	}
+	return false
+}
+
 func (c *StalledResources) checkJobs(ctx context.Context, client kubernetes.Interface, result *probe.CheckResult, stats *stalledStats) {
 	jobs, err := client.BatchV1().Jobs("").List(ctx, metav1.ListOptions{})
 	if err != nil {
@@ -671,11 +842,15 @@ func (c *StalledResources) checkJobs(ctx context.Context, client kubernetes.Inte
 						CheckName: c.Name(),
 						Severity:  probe.SeverityCritical,
 						Message:   fmt.Sprintf("Job %s/%s exceeded backoff limit", job.Namespace, job.Name),
+						Namespace: job.Namespace,
 						Details: []string{
 							fmt.Sprintf("Failed: %d", job.Status.Failed),
 							fmt.Sprintf("Message: %s", cond.Message),
 						},
-						Remediation: fmt.Sprintf("Check job logs and events: kubectl describe job -n %s %s", job.Namespace, job.Name),
+						Remediation: "Check job logs and events",
+						RemediationCommands: []string{
+							fmt.Sprintf("kubectl describe job -n %s %s", job.Namespace, job.Name),
+						},
 					})
 				}
 			}
@@ -695,16 +870,17 @@ func (c *StalledResources) isBackoffReason(reason string) bool {
 	return backoffReasons[reason]
 }
 
-func (c *StalledResources) getBackoffRemediation(reason, namespace, podName, containerName string) string {
+func (c *StalledResources) getBackoffRemediation(reason string, pod *corev1.Pod, cs corev1.ContainerStatus) (string, []string) {
+	namespace, podName := pod.Namespace, pod.Name
 	switch reason {
 	case "CrashLoopBackOff":
-		return fmt.Sprintf("Check logs: kubectl logs -n %s %s -c %s --previous", namespace, podName, containerName)
+		return crashLoopRemediation(pod, cs)
 	case "ImagePullBackOff", "ErrImagePull", "InvalidImageName":
-		return "Verify image name, registry credentials, and network access"
+		return "Verify image name, registry credentials, and network access", nil
 	case "CreateContainerError", "CreateContainerConfigError":
-		return fmt.Sprintf("Check pod events: kubectl describe pod -n %s %s", namespace, podName)
+		return "Check pod events", []string{fmt.Sprintf("kubectl describe pod -n %s %s", namespace, podName)}
 	default:
-		return fmt.Sprintf("Check pod status: kubectl describe pod -n %s %s", namespace, podName)
+		return "Check pod status", []string{fmt.Sprintf("kubectl describe pod -n %s %s", namespace, podName)}
 	}
 }
 
@@ -721,6 +897,32 @@ func (c *StalledResources) getPendingPodDetails(pod *corev1.Pod) []string {
 	return details
 }
 
+func (c *StalledResources) getTerminatingPodDetails(pod *corev1.Pod) []string {
+	node := pod.Spec.NodeName
+	if node == "" {
+		node = "<unscheduled>"
+	}
+
+	details := []string{fmt.Sprintf("Node: %s", node)}
+	if pod.DeletionGracePeriodSeconds != nil {
+		details = append(details, fmt.Sprintf("Grace period: %ds", *pod.DeletionGracePeriodSeconds))
+	}
+	if len(pod.Finalizers) > 0 {
+		details = append(details, fmt.Sprintf("Finalizers: %s", strings.Join(pod.Finalizers, ", ")))
+	}
+	return details
+}
+
+func (c *StalledResources) getTerminatingRemediation(pod *corev1.Pod) (string, []string) {
+	if len(pod.Finalizers) > 0 {
+		return "Check the controllers owning the finalizers", []string{fmt.Sprintf("kubectl get pod -n %s %s -o jsonpath='{.metadata.finalizers}'", pod.Namespace, pod.Name)}
+	}
+	if pod.Spec.NodeName != "" {
+		return fmt.Sprintf("Check kubelet health on node %s", pod.Spec.NodeName), []string{fmt.Sprintf("kubectl describe node %s", pod.Spec.NodeName)}
+	}
+	return "Check pod events", []string{fmt.Sprintf("kubectl describe pod -n %s %s", pod.Namespace, pod.Name)}
+}
+
 func stalledFormatDuration(d time.Duration) string {
 	if d < time.Minute {
 		return fmt.Sprintf("%ds", int(d.Seconds()))
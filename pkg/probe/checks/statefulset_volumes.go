@@ -0,0 +1,179 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type StatefulSetVolumes struct{}
+
+func NewStatefulSetVolumes() *StatefulSetVolumes {
+	return &StatefulSetVolumes{}
+}
+
+func (c *StatefulSetVolumes) Name() string {
+	return "statefulset-volumes"
+}
+
+func (c *StatefulSetVolumes) Tier() int {
+	return 3
+}
+
+func (c *StatefulSetVolumes) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	statefulSets, err := client.AppsV1().StatefulSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+
+	pvcs, err := client.CoreV1().PersistentVolumeClaims("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistentvolumeclaims: %w", err)
+	}
+
+	pods, err := listPods(ctx, client, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	existingPods := make(map[string]bool, len(pods.Items))
+	for _, pod := range pods.Items {
+		existingPods[pod.Namespace+"/"+pod.Name] = true
+	}
+
+	pvcsByNamespace := make(map[string][]corev1.PersistentVolumeClaim)
+	for _, pvc := range pvcs.Items {
+		pvcsByNamespace[pvc.Namespace] = append(pvcsByNamespace[pvc.Namespace], pvc)
+	}
+
+	sort.Slice(statefulSets.Items, func(i, j int) bool {
+		if statefulSets.Items[i].Namespace != statefulSets.Items[j].Namespace {
+			return statefulSets.Items[i].Namespace < statefulSets.Items[j].Namespace
+		}
+		return statefulSets.Items[i].Name < statefulSets.Items[j].Name
+	})
+
+	podsSampled := probe.IsSampled(pods.ListMeta)
+	orphaned := 0
+	unverified := 0
+	mismatched := 0
+	for _, sts := range statefulSets.Items {
+		replicas := int32(1)
+		if sts.Spec.Replicas != nil {
+			replicas = *sts.Spec.Replicas
+		}
+
+		for _, template := range sts.Spec.VolumeClaimTemplates {
+			prefix := fmt.Sprintf("%s-%s-", template.Name, sts.Name)
+			for _, pvc := range pvcsByNamespace[sts.Namespace] {
+				ordinal, ok := statefulSetOrdinal(pvc.Name, prefix)
+				if !ok {
+					continue
+				}
+
+				podName := fmt.Sprintf("%s-%d", sts.Name, ordinal)
+				if ordinal >= int(replicas) && !existingPods[sts.Namespace+"/"+podName] {
+					if podsSampled {
+						unverified++
+						continue
+					}
+					orphaned++
+					result.Results = append(result.Results, probe.Result{
+						CheckName: c.Name(),
+						Severity:  probe.SeverityWarning,
+						Message:   fmt.Sprintf("PVC %s/%s is left over from StatefulSet %s scale-down", pvc.Namespace, pvc.Name, sts.Name),
+						Namespace: pvc.Namespace,
+						Details: []string{
+							fmt.Sprintf("StatefulSet replicas: %d, PVC ordinal: %d, pod %s does not exist", replicas, ordinal, podName),
+							fmt.Sprintf("Requested size: %s", storageRequest(pvc.Spec.Resources.Requests)),
+						},
+						Remediation: "StatefulSets keep PVCs after scaling down so data survives a scale-up. Delete the PVC if the data is no longer needed, or set persistentVolumeClaimRetentionPolicy.whenScaled: Delete",
+						RemediationCommands: []string{
+							fmt.Sprintf("kubectl delete pvc -n %s %s", pvc.Namespace, pvc.Name),
+						},
+					})
+					continue
+				}
+
+				if problems := templateMismatches(template, pvc); len(problems) > 0 {
+					mismatched++
+					result.Results = append(result.Results, probe.Result{
+						CheckName:   c.Name(),
+						Severity:    probe.SeverityWarning,
+						Message:     fmt.Sprintf("PVC %s/%s does not match StatefulSet %s volumeClaimTemplate %s", pvc.Namespace, pvc.Name, sts.Name, template.Name),
+						Namespace:   pvc.Namespace,
+						Details:     problems,
+						Remediation: "Changes to volumeClaimTemplates only apply to new PVCs. Expand existing PVCs directly (the StorageClass must allow volume expansion), or migrate the data to a new claim",
+						RemediationCommands: []string{
+							fmt.Sprintf("kubectl get pvc -n %s %s -o yaml", pvc.Namespace, pvc.Name),
+						},
+					})
+				}
+			}
+		}
+	}
+
+	severity := probe.SeverityOK
+	if orphaned > 0 || mismatched > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	summary := probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("StatefulSet volumes: %d StatefulSets, %d orphaned PVCs, %d PVCs differing from their template", len(statefulSets.Items), orphaned, mismatched),
+	}
+	if unverified > 0 {
+		summary.Details = []string{fmt.Sprintf("%d PVCs beyond the replica count were not reported as orphaned because the pod list was sampled by --resource-budget", unverified)}
+	}
+	result.Results = append(result.Results, summary)
+
+	return result, nil
+}
+
+func statefulSetOrdinal(pvcName, prefix string) (int, bool) {
+	if !strings.HasPrefix(pvcName, prefix) {
+		return 0, false
+	}
+	ordinal, err := strconv.Atoi(strings.TrimPrefix(pvcName, prefix))
+	if err != nil || ordinal < 0 {
+		return 0, false
+	}
+	return ordinal, true
+}
+
+func templateMismatches(template corev1.PersistentVolumeClaim, pvc corev1.PersistentVolumeClaim) []string {
+	var problems []string
+
+	want, wantOK := template.Spec.Resources.Requests[corev1.ResourceStorage]
+	got, gotOK := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if wantOK && gotOK && got.Cmp(want) < 0 {
+		problems = append(problems, fmt.Sprintf("Requested size %s is smaller than the template's %s", got.String(), want.String()))
+	}
+
+	if template.Spec.StorageClassName != nil && pvc.Spec.StorageClassName != nil && *template.Spec.StorageClassName != *pvc.Spec.StorageClassName {
+		problems = append(problems, fmt.Sprintf("StorageClass %s differs from the template's %s", *pvc.Spec.StorageClassName, *template.Spec.StorageClassName))
+	}
+
+	return problems
+}
+
+func storageRequest(requests corev1.ResourceList) string {
+	if quantity, ok := requests[corev1.ResourceStorage]; ok {
+		return quantity.String()
+	}
+	return "unknown"
+}
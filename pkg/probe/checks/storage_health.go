@@ -57,11 +57,12 @@ func (c *StorageHealth) Run(ctx context.Context, client kubernetes.Interface) (*
 
 	if scCount > 0 && defaultSC == nil {
 		result.Results = append(result.Results, probe.Result{
-			CheckName:	c.Name(),
-			Severity:	probe.SeverityWarning,
-			Message:	"No default storage class defined",
-			Details:	[]string{fmt.Sprintf("Available storage classes: %d", scCount)},
-			Remediation:	"Mark a storage class as default: kubectl patch storageclass <name> -p '{\"metadata\":{\"annotations\":{\"storageclass.kubernetes.io/is-default-class\":\"true\"}}}'",
+			CheckName:		c.Name(),
+			Severity:		probe.SeverityWarning,
+			Message:		"No default storage class defined",
+			Details:		[]string{fmt.Sprintf("Available storage classes: %d", scCount)},
+			Remediation:		"Mark a storage class as default",
+			RemediationCommands:	[]string{"kubectl patch storageclass <name> -p '{\"metadata\":{\"annotations\":{\"storageclass.kubernetes.io/is-default-class\":\"true\"}}}'"},
 		})
 	}
 
@@ -109,7 +110,7 @@ func (c *StorageHealth) Run(ctx context.Context, client kubernetes.Interface) (*
 		}
 		result.Results = append(result.Results, probe.Result{
 			CheckName:	c.Name(),
-			Severity:	probe.SeverityOK,
+			Severity:	probe.SeverityInfo,
 			Message:	fmt.Sprintf("%d CSI drivers installed", len(csiDrivers.Items)),
 			Details:	driverNames,
 		})
@@ -0,0 +1,139 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type TopologySpread struct {
+	replicaThreshold int
+}
+
+func NewTopologySpread() *TopologySpread {
+	return &TopologySpread{
+		replicaThreshold: 5,
+	}
+}
+
+func (c *TopologySpread) Name() string {
+	return "topology-spread"
+}
+
+func (c *TopologySpread) Tier() int {
+	return 3
+}
+
+func (c *TopologySpread) OptIn() bool {
+	return true
+}
+
+func (c *TopologySpread) Configure(cfg *config.Config) {
+	c.replicaThreshold = cfg.GetThreshold("topology_spread_min_replicas")
+}
+
+func (c *TopologySpread) Run(ctx context.Context, client kubernetes.Interface) (*probe.CheckResult, error) {
+	result := &probe.CheckResult{
+		Name:    c.Name(),
+		Tier:    c.Tier(),
+		Results: []probe.Result{},
+	}
+
+	deployments, err := client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	statefulSets, err := client.AppsV1().StatefulSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+
+	unspread := []probe.Result{}
+	large := 0
+
+	for _, deploy := range deployments.Items {
+		replicas := replicaCount(deploy.Spec.Replicas)
+		if replicas < c.replicaThreshold {
+			continue
+		}
+		large++
+		if spreadsPods(&deploy.Spec.Template.Spec) {
+			continue
+		}
+		unspread = append(unspread, c.unspreadResult("Deployment", "deployment", deploy.Namespace, deploy.Name, replicas))
+	}
+
+	for _, sts := range statefulSets.Items {
+		replicas := replicaCount(sts.Spec.Replicas)
+		if replicas < c.replicaThreshold {
+			continue
+		}
+		large++
+		if spreadsPods(&sts.Spec.Template.Spec) {
+			continue
+		}
+		unspread = append(unspread, c.unspreadResult("StatefulSet", "statefulset", sts.Namespace, sts.Name, replicas))
+	}
+
+	sort.Slice(unspread, func(i, j int) bool {
+		return unspread[i].Message < unspread[j].Message
+	})
+	result.Results = append(result.Results, unspread...)
+
+	severity := probe.SeverityOK
+	if len(unspread) > 0 {
+		severity = probe.SeverityWarning
+	}
+
+	result.Results = append(result.Results, probe.Result{
+		CheckName: c.Name(),
+		Severity:  severity,
+		Message:   fmt.Sprintf("Topology spread: %d of %d workloads with %d+ replicas have no spread constraints or anti-affinity", len(unspread), large, c.replicaThreshold),
+	})
+
+	return result, nil
+}
+
+func (c *TopologySpread) unspreadResult(kind, resource, namespace, name string, replicas int) probe.Result {
+	return probe.Result{
+		CheckName: c.Name(),
+		Severity:  probe.SeverityWarning,
+		Message:   fmt.Sprintf("%s %s/%s runs %d replicas without topology spread constraints or pod anti-affinity", kind, namespace, name, replicas),
+		Namespace: namespace,
+		Details: []string{
+			fmt.Sprintf("Threshold: %d replicas", c.replicaThreshold),
+			"The scheduler may place most replicas in one zone or on one node, so a single failure can take out the workload",
+		},
+		Remediation: "Add a topologySpreadConstraints entry on topology.kubernetes.io/zone (and kubernetes.io/hostname), or a preferred podAntiAffinity rule, to the pod template",
+		RemediationCommands: []string{
+			fmt.Sprintf("kubectl get pods -n %s -o wide", namespace),
+			fmt.Sprintf("kubectl edit %s -n %s %s", resource, namespace, name),
+		},
+	}
+}
+
+func replicaCount(replicas *int32) int {
+	if replicas == nil {
+		return 1
+	}
+	return int(*replicas)
+}
+
+func spreadsPods(spec *corev1.PodSpec) bool {
+	if len(spec.TopologySpreadConstraints) > 0 {
+		return true
+	}
+	if spec.Affinity == nil || spec.Affinity.PodAntiAffinity == nil {
+		return false
+	}
+	antiAffinity := spec.Affinity.PodAntiAffinity
+	return len(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) > 0 ||
+		len(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) > 0
+}
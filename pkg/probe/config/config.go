@@ -3,14 +3,30 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+const DefaultDocBaseURL = "https://github.com/punasusi/cluster-probe/wiki"
+
 type Config struct {
-	Checks     map[string]CheckConfig `yaml:"checks,omitempty"`
-	Ignore     IgnoreConfig           `yaml:"ignore,omitempty"`
-	Thresholds ThresholdConfig        `yaml:"thresholds,omitempty"`
+	Checks                map[string]CheckConfig `yaml:"checks,omitempty"`
+	Ignore                IgnoreConfig           `yaml:"ignore,omitempty"`
+	Thresholds            ThresholdConfig        `yaml:"thresholds,omitempty"`
+	SystemNamespaces      []string               `yaml:"system_namespaces,omitempty"`
+	Networking            NetworkingConfig       `yaml:"networking,omitempty"`
+	NetworkTest           NetworkTestConfig      `yaml:"network_test,omitempty"`
+	EOLOSPatterns         []string               `yaml:"eol_os_patterns,omitempty"`
+	ExitCodeExcludeChecks []string               `yaml:"exit_code_exclude_checks,omitempty"`
+	UniqueDeploymentNames []string               `yaml:"unique_deployment_names,omitempty"`
+	SingletonDeployments  []string               `yaml:"singleton_deployments,omitempty"`
+	RequiredNodeLabels    []string               `yaml:"required_node_labels,omitempty"`
+	SensitivePorts        []int                  `yaml:"sensitive_ports,omitempty"`
+	StatefulNamespaces    string                 `yaml:"stateful_namespace_selector,omitempty"`
+	StalledResources      StalledResourcesConfig `yaml:"stalled_resources,omitempty"`
+	DocBaseURL            string                 `yaml:"doc_base_url,omitempty"`
+	RedactClusterName     bool                   `yaml:"redact_cluster_name,omitempty"`
 }
 
 type CheckConfig struct {
@@ -22,6 +38,7 @@ type IgnoreConfig struct {
 	Namespaces []string `yaml:"namespaces,omitempty"`
 	Checks     []string `yaml:"checks,omitempty"`
 	Patterns   []string `yaml:"patterns,omitempty"`
+	Tiers      []int    `yaml:"tiers,omitempty"`
 }
 
 type ThresholdConfig struct {
@@ -32,6 +49,33 @@ type ThresholdConfig struct {
 	NodeCPUWarning            int `yaml:"node_cpu_warning_percent,omitempty"`
 	NodeMemoryWarning         int `yaml:"node_memory_warning_percent,omitempty"`
 	NodeMemoryCritical        int `yaml:"node_memory_critical_percent,omitempty"`
+	WebhookTimeout            int `yaml:"webhook_timeout_seconds,omitempty"`
+	ConfigMapsPerNamespace    int `yaml:"configmaps_per_ns_warning,omitempty"`
+	EscalateAfterScans        int `yaml:"escalate_after_scans,omitempty"`
+	PodChurnWindow            int `yaml:"pod_churn_window_minutes,omitempty"`
+	PodChurnWarning           int `yaml:"pod_churn_warning,omitempty"`
+	TopologySpreadMinReplicas int `yaml:"topology_spread_min_replicas,omitempty"`
+	NodeHeartbeatStale        int `yaml:"node_heartbeat_stale_seconds,omitempty"`
+	EventStormWarning         int `yaml:"event_storm_warning,omitempty"`
+	NoMemoryLimitPercent      int `yaml:"no_memory_limit_percent,omitempty"`
+	HelmReleaseRevisions      int `yaml:"helm_release_revisions_warning,omitempty"`
+	ContainerRequestRatio     int `yaml:"container_request_ratio_warning,omitempty"`
+	NodeAllocatableMin        int `yaml:"node_allocatable_min_percent,omitempty"`
+}
+
+type NetworkingConfig struct {
+	KubeProxyDaemonSets []string `yaml:"kube_proxy_daemonsets,omitempty"`
+	CNIDaemonSets       []string `yaml:"cni_daemonsets,omitempty"`
+}
+
+type NetworkTestConfig struct {
+	Severity           map[string]string `yaml:"severity,omitempty"`
+	TestTimeoutSeconds int               `yaml:"test_timeout_seconds,omitempty"`
+	DeadlineSeconds    int               `yaml:"deadline_seconds,omitempty"`
+}
+
+type StalledResourcesConfig struct {
+	IgnoreKinds []string `yaml:"ignore_kinds,omitempty"`
 }
 
 func DefaultConfig() *Config {
@@ -67,6 +111,17 @@ func DefaultConfig() *Config {
 			NodeCPUWarning:			80,
 			NodeMemoryWarning:		80,
 			NodeMemoryCritical:		95,
+			WebhookTimeout:			10,
+			ConfigMapsPerNamespace:		500,
+			PodChurnWindow:			60,
+			PodChurnWarning:		100,
+			TopologySpreadMinReplicas:	5,
+			NodeHeartbeatStale:		600,
+			EventStormWarning:		1000,
+			NoMemoryLimitPercent:		50,
+			HelmReleaseRevisions:		10,
+			ContainerRequestRatio:		10,
+			NodeAllocatableMin:		80,
 		},
 	}
 }
@@ -105,6 +160,20 @@ func (c *Config) IsCheckEnabled(name string) bool {
 	return true
 }
 
+func (c *Config) IsCheckExplicitlyEnabled(name string) bool {
+	checkCfg, ok := c.Checks[name]
+	return ok && checkCfg.Enabled != nil && *checkCfg.Enabled
+}
+
+func (c *Config) IsTierIgnored(tier int) bool {
+	for _, ignored := range c.Ignore.Tiers {
+		if ignored == tier {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Config) IsNamespaceIgnored(namespace string) bool {
 	for _, ns := range c.Ignore.Namespaces {
 		if ns == namespace {
@@ -114,6 +183,31 @@ func (c *Config) IsNamespaceIgnored(namespace string) bool {
 	return false
 }
 
+func (c *Config) IsExitCodeExcluded(name string) bool {
+	for _, excluded := range c.ExitCodeExcludeChecks {
+		if excluded == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) AddIgnoredNamespaces(namespaces []string) {
+	for _, ns := range namespaces {
+		if ns != "" && !c.IsNamespaceIgnored(ns) {
+			c.Ignore.Namespaces = append(c.Ignore.Namespaces, ns)
+		}
+	}
+}
+
+func (c *Config) DocURL(checkName string) string {
+	base := c.DocBaseURL
+	if base == "" {
+		base = DefaultDocBaseURL
+	}
+	return strings.TrimSuffix(base, "/") + "/" + checkName
+}
+
 func (c *Config) GetThreshold(name string) int {
 	switch name {
 	case "default_service_account_pods":
@@ -151,11 +245,99 @@ func (c *Config) GetThreshold(name string) int {
 			return c.Thresholds.NodeMemoryCritical
 		}
 		return 95
+	case "webhook_timeout_seconds":
+		if c.Thresholds.WebhookTimeout > 0 {
+			return c.Thresholds.WebhookTimeout
+		}
+		return 10
+	case "configmaps_per_ns_warning":
+		if c.Thresholds.ConfigMapsPerNamespace > 0 {
+			return c.Thresholds.ConfigMapsPerNamespace
+		}
+		return 500
+	case "escalate_after_scans":
+		return c.Thresholds.EscalateAfterScans
+	case "pod_churn_window_minutes":
+		if c.Thresholds.PodChurnWindow > 0 {
+			return c.Thresholds.PodChurnWindow
+		}
+		return 60
+	case "pod_churn_warning":
+		if c.Thresholds.PodChurnWarning > 0 {
+			return c.Thresholds.PodChurnWarning
+		}
+		return 100
+	case "topology_spread_min_replicas":
+		if c.Thresholds.TopologySpreadMinReplicas > 0 {
+			return c.Thresholds.TopologySpreadMinReplicas
+		}
+		return 5
+	case "node_heartbeat_stale_seconds":
+		if c.Thresholds.NodeHeartbeatStale > 0 {
+			return c.Thresholds.NodeHeartbeatStale
+		}
+		return 600
+	case "event_storm_warning":
+		if c.Thresholds.EventStormWarning > 0 {
+			return c.Thresholds.EventStormWarning
+		}
+		return 1000
+	case "no_memory_limit_percent":
+		if c.Thresholds.NoMemoryLimitPercent > 0 {
+			return c.Thresholds.NoMemoryLimitPercent
+		}
+		return 50
+	case "helm_release_revisions_warning":
+		if c.Thresholds.HelmReleaseRevisions > 0 {
+			return c.Thresholds.HelmReleaseRevisions
+		}
+		return 10
+	case "container_request_ratio_warning":
+		if c.Thresholds.ContainerRequestRatio > 0 {
+			return c.Thresholds.ContainerRequestRatio
+		}
+		return 10
+	case "node_allocatable_min_percent":
+		if c.Thresholds.NodeAllocatableMin > 0 {
+			return c.Thresholds.NodeAllocatableMin
+		}
+		return 80
 	default:
 		return 0
 	}
 }
 
+func (c *Config) GetSystemNamespaces() []string {
+	if len(c.SystemNamespaces) > 0 {
+		return c.SystemNamespaces
+	}
+	return []string{"kube-system"}
+}
+
+func (c *Config) GetDaemonSets(name string) []string {
+	switch name {
+	case "kube_proxy_daemonsets":
+		if len(c.Networking.KubeProxyDaemonSets) > 0 {
+			return c.Networking.KubeProxyDaemonSets
+		}
+		return []string{"kube-proxy"}
+	case "cni_daemonsets":
+		if len(c.Networking.CNIDaemonSets) > 0 {
+			return c.Networking.CNIDaemonSets
+		}
+		return []string{"calico-node", "cilium", "kube-flannel-ds", "canal", "weave-net", "aws-node", "azure-cns", "antrea-agent", "kube-router"}
+	default:
+		return nil
+	}
+}
+
+func (c *Config) NetworkTestSeverity(testType string) string {
+	if severity := strings.ToLower(c.NetworkTest.Severity[testType]); severity != "" {
+		return severity
+	}
+	return "critical"
+}
+
 func SaveExample(path string) error {
 	example := `# Cluster Probe Configuration
 # Place this file at .probe/config.yaml
@@ -166,6 +348,21 @@ checks:
   #   enabled: false
   # network-policies:
   #   enabled: false
+  # Opt-in checks must be enabled explicitly
+  # dangling-owners:
+  #   enabled: true
+  # duplicate-names:
+  #   enabled: true
+  # pod-churn:
+  #   enabled: true
+  # pv-reclaim-policy:
+  #   enabled: true
+  # request-balance:
+  #   enabled: true
+  # singleton-replicas:
+  #   enabled: true
+  # topology-spread:
+  #   enabled: true
 
 # Ignore patterns
 ignore:
@@ -178,6 +375,10 @@ ignore:
   checks: []
     # - dns-resolution
 
+  # Tiers to skip entirely (1=critical, 2=workload, 3=resource, 4=networking, 5=security)
+  tiers: []
+    # - 5
+
 # Thresholds (adjust sensitivity)
 thresholds:
   # Warn if more than N pods use default service account
@@ -192,10 +393,122 @@ thresholds:
   # Warn if certificates expire within N days
   certificate_expiry_warning_days: 30
 
+  # Warn if a Ready node's last status heartbeat is older than N seconds
+  # (kubelets post status every 5 minutes when nothing changes)
+  node_heartbeat_stale_seconds: 600
+
   # Node resource thresholds (percent)
   node_cpu_warning_percent: 80
   node_memory_warning_percent: 80
   node_memory_critical_percent: 95
+
+  # Warn if a failurePolicy=Fail admission webhook has a timeout above N seconds
+  webhook_timeout_seconds: 10
+
+  # Warn if a namespace holds more than N ConfigMaps or N Secrets
+  configmaps_per_ns_warning: 500
+
+  # Escalate a warning to critical once it persists for more than N consecutive scans (0 disables)
+  escalate_after_scans: 0
+
+  # Warn if a namespace created more than N pods within the last M minutes (opt-in pod-churn check)
+  pod_churn_window_minutes: 60
+  pod_churn_warning: 100
+
+  # Warn if a Deployment or StatefulSet with at least N replicas has no topology
+  # spread constraints or pod anti-affinity (opt-in topology-spread check)
+  topology_spread_min_replicas: 5
+
+  # Warn if one controller reports N or more events with the same reason (event-storm check)
+  event_storm_warning: 1000
+
+  # Warn if at least N percent of a namespace's pods have a container without a memory limit
+  no_memory_limit_percent: 50
+
+  # Warn if a Helm release keeps more than N revision Secrets (helm-releases check)
+  helm_release_revisions_warning: 10
+
+  # Warn if one container in a pod requests N times more CPU or memory than another (opt-in request-balance check)
+  container_request_ratio_warning: 10
+
+  # Warn if a node's allocatable CPU or memory is below N percent of its capacity (node-capacity check)
+  node_allocatable_min_percent: 80
+
+# Namespaces holding control-plane, DNS and other system pods. Used by
+# critical-pods, control-plane, dns-resolution, pod-security and secrets-usage
+system_namespaces: []
+  # - kube-system
+  # - openshift-dns
+
+# Networking DaemonSets checked by networking-infra
+# Entries are DaemonSet names in kube-system, or namespace/name
+networking:
+  kube_proxy_daemonsets: []
+    # - kube-proxy
+  cni_daemonsets: []
+    # - calico-system/calico-node
+
+# Severity reported when a --network-test test type fails (critical or warning).
+# Test types: coredns, dns, external-tcp, kubelet, pod-to-pod
+network_test:
+  severity: {}
+    # external-tcp: warning
+  # Timeout for each individual test (default: 3s for TCP checks, 5s for
+  # external TCP, 10s for DNS lookups)
+  # test_timeout_seconds: 3
+  # Stop running tests after this many seconds; the rest are reported as skipped
+  # (default: no deadline)
+  # deadline_seconds: 300
+
+# Extra end-of-life OS image or kernel patterns (regular expressions) for node-os-eol,
+# added to the built-in list
+eol_os_patterns: []
+  # - "Ubuntu 20\\.04"
+
+# Checks that are still reported but never affect the exit code
+exit_code_exclude_checks: []
+  # - network-policies
+
+# Deployment names that must exist in at most one namespace (used by the
+# opt-in duplicate-names check)
+unique_deployment_names: []
+  # - api-gateway
+
+# Deployments that must run a single active replica, as name or namespace/name
+# (used by the opt-in singleton-replicas check)
+singleton_deployments: []
+  # - operators/cert-rotator
+
+# Labels every node must carry (used by node-labels). Setting this replaces the
+# defaults: topology.kubernetes.io/zone, topology.kubernetes.io/region and
+# node.kubernetes.io/instance-type
+# required_node_labels:
+#   - topology.kubernetes.io/zone
+
+# Ports node-ports warns about when a NodePort or LoadBalancer service exposes
+# them. Setting this replaces the defaults: 22, 2379, 2380, 3306, 5432, 6379,
+# 6443, 9200, 10250, 10255 and 27017
+# sensitive_ports:
+#   - 22
+
+# Namespace label selector limiting the opt-in pv-reclaim-policy check to
+# namespaces holding important data (empty checks every namespace)
+# stateful_namespace_selector: data-tier=stateful
+
+# Custom resource kinds stalled-resources never reports, as Kind.group
+# (or Kind to match any group)
+stalled_resources:
+  ignore_kinds: []
+    # - Workflow.argoproj.io
+    # - TaskRun.tekton.dev
+
+# Base URL for runbook links on findings; the check name is appended
+# (e.g. https://wiki.example.com/runbooks/pod-status)
+# doc_base_url: https://wiki.example.com/runbooks
+
+# Replace the cluster name in reports with a short hash so they can be shared
+# (same as --redact-cluster-name)
+# redact_cluster_name: true
 `
 
 	return os.WriteFile(path, []byte(example), 0644)
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -111,6 +112,26 @@ func TestIsCheckEnabled(t *testing.T) {
 	}
 }
 
+func TestIsCheckExplicitlyEnabled(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if cfg.IsCheckExplicitlyEnabled("dangling-owners") {
+		t.Error("unlisted check should not be explicitly enabled")
+	}
+
+	enabled := true
+	cfg.Checks["dangling-owners"] = CheckConfig{Enabled: &enabled}
+	if !cfg.IsCheckExplicitlyEnabled("dangling-owners") {
+		t.Error("dangling-owners should be explicitly enabled")
+	}
+
+	disabled := false
+	cfg.Checks["dangling-owners"] = CheckConfig{Enabled: &disabled}
+	if cfg.IsCheckExplicitlyEnabled("dangling-owners") {
+		t.Error("disabled check should not be explicitly enabled")
+	}
+}
+
 func TestIsNamespaceIgnored(t *testing.T) {
 	cfg := DefaultConfig()
 
@@ -131,6 +152,52 @@ func TestIsNamespaceIgnored(t *testing.T) {
 	}
 }
 
+func TestAddIgnoredNamespaces(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Ignore.Namespaces = []string{"monitoring"}
+
+	cfg.AddIgnoredNamespaces([]string{"scratch", "monitoring", ""})
+
+	if len(cfg.Ignore.Namespaces) != 2 {
+		t.Fatalf("expected config and CLI namespaces to merge without duplicates, got %v", cfg.Ignore.Namespaces)
+	}
+	if !cfg.IsNamespaceIgnored("monitoring") || !cfg.IsNamespaceIgnored("scratch") {
+		t.Errorf("expected monitoring and scratch to be ignored, got %v", cfg.Ignore.Namespaces)
+	}
+}
+
+func TestIsTierIgnored(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if cfg.IsTierIgnored(5) {
+		t.Error("no tier should be ignored by default")
+	}
+
+	cfg.Ignore.Tiers = []int{4, 5}
+	if !cfg.IsTierIgnored(5) {
+		t.Error("tier 5 should be ignored")
+	}
+	if cfg.IsTierIgnored(1) {
+		t.Error("tier 1 should not be ignored")
+	}
+}
+
+func TestIsExitCodeExcluded(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if cfg.IsExitCodeExcluded("network-policies") {
+		t.Error("no check should be excluded from the exit code by default")
+	}
+
+	cfg.ExitCodeExcludeChecks = []string{"network-policies"}
+	if !cfg.IsExitCodeExcluded("network-policies") {
+		t.Error("network-policies should be excluded")
+	}
+	if cfg.IsExitCodeExcluded("pod-status") {
+		t.Error("pod-status should not be excluded")
+	}
+}
+
 func TestGetThreshold(t *testing.T) {
 	cfg := DefaultConfig()
 
@@ -145,6 +212,17 @@ func TestGetThreshold(t *testing.T) {
 		{"node_cpu_warning_percent", 80},
 		{"node_memory_warning_percent", 80},
 		{"node_memory_critical_percent", 95},
+		{"configmaps_per_ns_warning", 500},
+		{"escalate_after_scans", 0},
+		{"pod_churn_window_minutes", 60},
+		{"pod_churn_warning", 100},
+		{"topology_spread_min_replicas", 5},
+		{"node_heartbeat_stale_seconds", 600},
+		{"event_storm_warning", 1000},
+		{"no_memory_limit_percent", 50},
+		{"helm_release_revisions_warning", 10},
+		{"container_request_ratio_warning", 10},
+		{"node_allocatable_min_percent", 80},
 		{"unknown_threshold", 0},
 	}
 
@@ -168,6 +246,52 @@ func TestGetThresholdCustom(t *testing.T) {
 	}
 }
 
+func TestGetDaemonSets(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if got := cfg.GetDaemonSets("kube_proxy_daemonsets"); len(got) != 1 || got[0] != "kube-proxy" {
+		t.Errorf("unexpected default kube-proxy DaemonSets: %v", got)
+	}
+	if got := cfg.GetDaemonSets("cni_daemonsets"); len(got) == 0 {
+		t.Error("expected built-in CNI DaemonSet names")
+	}
+	if got := cfg.GetDaemonSets("unknown"); got != nil {
+		t.Errorf("expected nil for unknown list, got %v", got)
+	}
+
+	cfg.Networking.CNIDaemonSets = []string{"calico-system/calico-node"}
+	if got := cfg.GetDaemonSets("cni_daemonsets"); len(got) != 1 || got[0] != "calico-system/calico-node" {
+		t.Errorf("expected custom CNI DaemonSets, got %v", got)
+	}
+}
+
+func TestGetSystemNamespaces(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.GetSystemNamespaces(); len(got) != 1 || got[0] != "kube-system" {
+		t.Errorf("unexpected default system namespaces: %v", got)
+	}
+
+	cfg.SystemNamespaces = []string{"kube-system", "platform-system"}
+	if got := cfg.GetSystemNamespaces(); len(got) != 2 || got[1] != "platform-system" {
+		t.Errorf("expected custom system namespaces, got %v", got)
+	}
+}
+
+func TestNetworkTestSeverity(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.NetworkTestSeverity("external-tcp"); got != "critical" {
+		t.Errorf("expected failures to default to critical, got %q", got)
+	}
+
+	cfg.NetworkTest.Severity = map[string]string{"external-tcp": "Warning"}
+	if got := cfg.NetworkTestSeverity("external-tcp"); got != "warning" {
+		t.Errorf("expected configured severity warning, got %q", got)
+	}
+	if got := cfg.NetworkTestSeverity("dns"); got != "critical" {
+		t.Errorf("expected unconfigured test types to stay critical, got %q", got)
+	}
+}
+
 func TestSaveExample(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "config.yaml")
@@ -192,4 +316,9 @@ func TestSaveExample(t *testing.T) {
 	if cfg == nil {
 		t.Error("should load config from example")
 	}
+	for _, name := range []string{"dangling-owners", "duplicate-names", "pod-churn", "pv-reclaim-policy", "request-balance", "singleton-replicas", "topology-spread"} {
+		if !strings.Contains(string(data), "# "+name+":") {
+			t.Errorf("example config should list the opt-in %s check", name)
+		}
+	}
 }
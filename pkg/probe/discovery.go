@@ -0,0 +1,40 @@
+package probe
+
+import (
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+)
+
+type CachedDiscovery struct {
+	discovery.DiscoveryInterface
+
+	versionOnce sync.Once
+	version     *version.Info
+	versionErr  error
+
+	resourcesOnce sync.Once
+	groups        []*metav1.APIGroup
+	resources     []*metav1.APIResourceList
+	resourcesErr  error
+}
+
+func NewCachedDiscovery(delegate discovery.DiscoveryInterface) *CachedDiscovery {
+	return &CachedDiscovery{DiscoveryInterface: delegate}
+}
+
+func (d *CachedDiscovery) ServerVersion() (*version.Info, error) {
+	d.versionOnce.Do(func() {
+		d.version, d.versionErr = d.DiscoveryInterface.ServerVersion()
+	})
+	return d.version, d.versionErr
+}
+
+func (d *CachedDiscovery) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	d.resourcesOnce.Do(func() {
+		d.groups, d.resources, d.resourcesErr = d.DiscoveryInterface.ServerGroupsAndResources()
+	})
+	return d.groups, d.resources, d.resourcesErr
+}
@@ -2,14 +2,19 @@ package probe
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/punasusi/cluster-probe/pkg/probe/config"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 )
 
+const DefaultMaxResults = 500
+
 type Check interface {
 	Name() string
 	Tier() int
@@ -21,27 +26,49 @@ type DynamicCheck interface {
 	RunDynamic(ctx context.Context, client kubernetes.Interface, dynamicClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface) (*CheckResult, error)
 }
 
+type MetadataCheck interface {
+	Check
+	RunMetadata(ctx context.Context, client kubernetes.Interface, metadataClient metadata.Interface) (*CheckResult, error)
+}
+
 type ConfigurableCheck interface {
 	Check
 	Configure(cfg *config.Config)
 }
 
+type OptInCheck interface {
+	Check
+	OptIn() bool
+}
+
 type Engine struct {
 	checks          []Check
 	verbose         bool
+	maxResults      int
+	resourceBudget  int
 	config          *config.Config
 	dynamicClient   dynamic.Interface
 	discoveryClient discovery.DiscoveryInterface
+	metadataClient  metadata.Interface
 }
 
 func NewEngine(verbose bool) *Engine {
 	return &Engine{
-		checks:  make([]Check, 0),
-		verbose: verbose,
-		config:  config.DefaultConfig(),
+		checks:     make([]Check, 0),
+		verbose:    verbose,
+		maxResults: DefaultMaxResults,
+		config:     config.DefaultConfig(),
 	}
 }
 
+func (e *Engine) SetMaxResults(max int) {
+	e.maxResults = max
+}
+
+func (e *Engine) SetResourceBudget(budget int) {
+	e.resourceBudget = budget
+}
+
 func (e *Engine) SetConfig(cfg *config.Config) {
 	e.config = cfg
 }
@@ -51,6 +78,10 @@ func (e *Engine) SetDynamicClients(dynamicClient dynamic.Interface, discoveryCli
 	e.discoveryClient = discoveryClient
 }
 
+func (e *Engine) SetMetadataClient(metadataClient metadata.Interface) {
+	e.metadataClient = metadataClient
+}
+
 func (e *Engine) Register(check Check) {
 	e.checks = append(e.checks, check)
 }
@@ -60,6 +91,11 @@ func (e *Engine) Run(ctx context.Context, client kubernetes.Interface) ([]CheckR
 		return []CheckResult{}, nil
 	}
 
+	var discoveryClient discovery.DiscoveryInterface
+	if e.discoveryClient != nil {
+		discoveryClient = NewCachedDiscovery(e.discoveryClient)
+	}
+
 	results := make([]CheckResult, 0, len(e.checks))
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -70,6 +106,14 @@ func (e *Engine) Run(ctx context.Context, client kubernetes.Interface) ([]CheckR
 			continue
 		}
 
+		if e.config != nil && e.config.IsTierIgnored(check.Tier()) {
+			continue
+		}
+
+		if optIn, ok := check.(OptInCheck); ok && optIn.OptIn() && (e.config == nil || !e.config.IsCheckExplicitlyEnabled(check.Name())) {
+			continue
+		}
+
 		if configurable, ok := check.(ConfigurableCheck); ok && e.config != nil {
 			configurable.Configure(e.config)
 		}
@@ -80,55 +124,57 @@ func (e *Engine) Run(ctx context.Context, client kubernetes.Interface) ([]CheckR
 			var result *CheckResult
 			var err error
 
-			if dc, ok := c.(DynamicCheck); ok && e.dynamicClient != nil && e.discoveryClient != nil {
-				result, err = dc.RunDynamic(ctx, client, e.dynamicClient, e.discoveryClient)
+			checkCtx := ctx
+			var budget *resourceBudget
+			if e.resourceBudget > 0 {
+				checkCtx, budget = withResourceBudget(ctx, e.resourceBudget)
+			}
+
+			if dc, ok := c.(DynamicCheck); ok && e.dynamicClient != nil && discoveryClient != nil {
+				result, err = dc.RunDynamic(checkCtx, client, e.dynamicClient, discoveryClient)
+			} else if mc, ok := c.(MetadataCheck); ok && e.metadataClient != nil {
+				result, err = mc.RunMetadata(checkCtx, client, e.metadataClient)
 			} else {
-				result, err = c.Run(ctx, client)
+				result, err = c.Run(checkCtx, client)
 			}
 
 			if err != nil {
 				mu.Lock()
 				results = append(results, CheckResult{
-					Name: c.Name(),
-					Tier: c.Tier(),
-					Results: []Result{{
-						CheckName: c.Name(),
-						Severity:  SeverityCritical,
-						Message:   "Check failed to execute",
-						Details:   []string{err.Error()},
-					}},
+					Name:    c.Name(),
+					Tier:    c.Tier(),
+					Results: []Result{},
+					Error:   err.Error(),
 				})
 				mu.Unlock()
 				return
 			}
 
+			if budget != nil {
+				result.Results = append(result.Results, budget.results(c.Name())...)
+			}
+
 			if e.config != nil {
+				for i := range result.Results {
+					if result.Results[i].Severity.IsIssue() && result.Results[i].DocURL == "" {
+						result.Results[i].DocURL = e.config.DocURL(c.Name())
+					}
+				}
+
 				filteredResults := make([]Result, 0, len(result.Results))
 				for _, r := range result.Results {
 
-					ignore := false
-					for _, ns := range e.config.Ignore.Namespaces {
-						for _, detail := range r.Details {
-							if containsNamespace(detail, ns) {
-								ignore = true
-								break
-							}
-						}
-
-						if containsNamespace(r.Message, ns) {
-							ignore = true
-						}
-						if ignore {
-							break
-						}
-					}
-					if !ignore {
+					if !e.isIgnored(r) {
 						filteredResults = append(filteredResults, r)
 					}
 				}
 				result.Results = filteredResults
 			}
 
+			if e.maxResults > 0 {
+				result.Results = truncateResults(c.Name(), result.Results, e.maxResults)
+			}
+
 			mu.Lock()
 			results = append(results, *result)
 			mu.Unlock()
@@ -139,6 +185,57 @@ func (e *Engine) Run(ctx context.Context, client kubernetes.Interface) ([]CheckR
 	return results, nil
 }
 
+func truncateResults(checkName string, results []Result, max int) []Result {
+	issues := make([]int, 0, len(results))
+	for i, r := range results {
+		if r.Severity.IsIssue() {
+			issues = append(issues, i)
+		}
+	}
+	if len(issues) <= max {
+		return results
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return results[issues[i]].Severity > results[issues[j]].Severity
+	})
+	shown := make(map[int]bool, max)
+	for _, i := range issues[:max] {
+		shown[i] = true
+	}
+
+	kept := make([]Result, 0, max+1)
+	dropped := 0
+	droppedSeverity := SeverityOK
+	for i, r := range results {
+		if !r.Severity.IsIssue() || shown[i] {
+			kept = append(kept, r)
+			continue
+		}
+		dropped++
+		if r.Severity > droppedSeverity {
+			droppedSeverity = r.Severity
+		}
+	}
+	return append(kept, Result{
+		CheckName: checkName,
+		Severity:  droppedSeverity,
+		Message:   fmt.Sprintf("…and %d more", dropped),
+		Details:   []string{fmt.Sprintf("Only the %d most severe findings are shown; raise --max-results to see the rest", max)},
+	})
+}
+
+func (e *Engine) isIgnored(r Result) bool {
+	if r.Namespace != "" {
+		return e.config.IsNamespaceIgnored(r.Namespace)
+	}
+	for _, ns := range e.config.Ignore.Namespaces {
+		if containsNamespace(r.Message, ns) {
+			return true
+		}
+	}
+	return false
+}
+
 func containsNamespace(s, ns string) bool {
 
 	return len(s) > len(ns)+1 && (s[:len(ns)+1] == ns+"/" ||
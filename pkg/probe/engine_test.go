@@ -3,11 +3,21 @@ package probe
 import (
 	"context"
 	"errors"
+	"strconv"
+	"sync/atomic"
 	"testing"
 
 	"github.com/punasusi/cluster-probe/pkg/probe/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/metadata"
+	metadatafake "k8s.io/client-go/metadata/fake"
 )
 
 type mockCheck struct {
@@ -106,6 +116,9 @@ func TestEngineRunError(t *testing.T) {
 	if results[0].MaxSeverity() != SeverityCritical {
 		t.Error("failed check should have critical severity")
 	}
+	if results[0].Error != "check failed" || len(results[0].Results) != 0 {
+		t.Errorf("failure should be recorded as the check's error, not a finding: %+v", results[0])
+	}
 }
 
 func TestEngineSetConfig(t *testing.T) {
@@ -137,6 +150,74 @@ func TestEngineDisabledCheck(t *testing.T) {
 	}
 }
 
+type optInMockCheck struct {
+	mockCheck
+}
+
+func (m *optInMockCheck) OptIn() bool { return true }
+
+func TestEngineOptInCheck(t *testing.T) {
+	newCheck := func() *optInMockCheck {
+		return &optInMockCheck{mockCheck{
+			name:   "opt-in-check",
+			tier:   2,
+			result: &CheckResult{Name: "opt-in-check", Tier: 2, Results: []Result{}},
+		}}
+	}
+
+	engine := NewEngine(false)
+	check := newCheck()
+	engine.Register(check)
+	engine.Run(context.Background(), fake.NewSimpleClientset())
+	if check.called {
+		t.Error("opt-in check should not run unless enabled in config")
+	}
+
+	engine = NewEngine(false)
+	cfg := config.DefaultConfig()
+	enabled := true
+	cfg.Checks["opt-in-check"] = config.CheckConfig{Enabled: &enabled}
+	engine.SetConfig(cfg)
+	check = newCheck()
+	engine.Register(check)
+	results, _ := engine.Run(context.Background(), fake.NewSimpleClientset())
+	if !check.called {
+		t.Error("opt-in check should run when enabled in config")
+	}
+	if len(results) != 1 {
+		t.Errorf("expected 1 result, got %d", len(results))
+	}
+}
+
+func TestEngineIgnoredTier(t *testing.T) {
+	engine := NewEngine(false)
+	cfg := config.DefaultConfig()
+	cfg.Ignore.Tiers = []int{5}
+	engine.SetConfig(cfg)
+
+	workload := &mockCheck{
+		name:   "workload-check",
+		tier:   2,
+		result: &CheckResult{Name: "workload-check", Tier: 2, Results: []Result{}},
+	}
+	rbac := &mockCheck{name: "rbac-check", tier: 5}
+	secrets := &mockCheck{name: "secrets-check", tier: 5}
+	engine.Register(workload)
+	engine.Register(rbac)
+	engine.Register(secrets)
+
+	results, _ := engine.Run(context.Background(), fake.NewSimpleClientset())
+	if rbac.called || secrets.called {
+		t.Error("checks in an ignored tier should not be called")
+	}
+	if !workload.called {
+		t.Error("checks outside the ignored tier should still run")
+	}
+	if len(results) != 1 || results[0].Name != "workload-check" {
+		t.Errorf("expected only workload-check results, got %v", results)
+	}
+}
+
 func TestEngineConfigurableCheck(t *testing.T) {
 	engine := NewEngine(false)
 	cfg := config.DefaultConfig()
@@ -179,6 +260,13 @@ func TestEngineMaxSeverity(t *testing.T) {
 			},
 			expected: SeverityOK,
 		},
+		{
+			name: "info never escalates",
+			results: []CheckResult{
+				{Results: []Result{{Severity: SeverityInfo}}},
+			},
+			expected: SeverityOK,
+		},
 		{
 			name: "with warning",
 			results: []CheckResult{
@@ -206,3 +294,367 @@ func TestEngineMaxSeverity(t *testing.T) {
 		})
 	}
 }
+
+func TestEngineAddedIgnoredNamespaceFiltersResults(t *testing.T) {
+	engine := NewEngine(false)
+	cfg := config.DefaultConfig()
+	cfg.AddIgnoredNamespaces([]string{"scratch"})
+	engine.SetConfig(cfg)
+
+	engine.Register(&mockCheck{
+		name: "pod-status",
+		tier: 2,
+		result: &CheckResult{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []Result{
+				{Severity: SeverityWarning, Message: "Pod scratch/debug is pending", Namespace: "scratch", Details: []string{"scratch/debug"}},
+				{Severity: SeverityWarning, Message: "Pod app/web is pending", Namespace: "app", Details: []string{"app/web"}},
+			},
+		},
+	})
+
+	results, err := engine.Run(context.Background(), fake.NewSimpleClientset())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Results) != 1 {
+		t.Fatalf("expected one unfiltered result, got %+v", results)
+	}
+	if results[0].Results[0].Message != "Pod app/web is pending" {
+		t.Errorf("expected scratch result to be filtered, got %q", results[0].Results[0].Message)
+	}
+}
+
+func TestEngineIgnoredNamespaceKeepsAggregatedResults(t *testing.T) {
+	engine := NewEngine(false)
+	cfg := config.DefaultConfig()
+	cfg.AddIgnoredNamespaces([]string{"scratch"})
+	engine.SetConfig(cfg)
+
+	engine.Register(&mockCheck{
+		name: "image-pull-policy",
+		tier: 3,
+		result: &CheckResult{
+			Name: "image-pull-policy",
+			Tier: 3,
+			Results: []Result{
+				{Severity: SeverityWarning, Message: "3 containers use mutable image tags", Details: []string{"scratch/debug", "app/web", "app/worker"}},
+			},
+		},
+	})
+
+	results, err := engine.Run(context.Background(), fake.NewSimpleClientset())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Results) != 1 {
+		t.Errorf("an ignored namespace in the details should not hide a cluster-wide result, got %+v", results)
+	}
+}
+
+type countingDiscovery struct {
+	discovery.DiscoveryInterface
+	versionCalls   int32
+	resourcesCalls int32
+}
+
+func (d *countingDiscovery) ServerVersion() (*version.Info, error) {
+	atomic.AddInt32(&d.versionCalls, 1)
+	return d.DiscoveryInterface.ServerVersion()
+}
+
+func (d *countingDiscovery) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	atomic.AddInt32(&d.resourcesCalls, 1)
+	return d.DiscoveryInterface.ServerGroupsAndResources()
+}
+
+type discoveryCheck struct {
+	name string
+}
+
+func (c *discoveryCheck) Name() string { return c.name }
+func (c *discoveryCheck) Tier() int    { return 1 }
+func (c *discoveryCheck) Run(ctx context.Context, client kubernetes.Interface) (*CheckResult, error) {
+	return nil, errors.New("expected RunDynamic")
+}
+func (c *discoveryCheck) RunDynamic(ctx context.Context, client kubernetes.Interface, dynamicClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface) (*CheckResult, error) {
+	if _, err := discoveryClient.ServerVersion(); err != nil {
+		return nil, err
+	}
+	if _, _, err := discoveryClient.ServerGroupsAndResources(); err != nil {
+		return nil, err
+	}
+	return &CheckResult{Name: c.name, Tier: 1, Results: []Result{}}, nil
+}
+
+func TestEngineSharesDiscoveryAcrossChecks(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	counting := &countingDiscovery{DiscoveryInterface: client.Discovery()}
+
+	engine := NewEngine(false)
+	engine.SetDynamicClients(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), counting)
+	for _, name := range []string{"first", "second", "third"} {
+		engine.Register(&discoveryCheck{name: name})
+	}
+
+	results, err := engine.Run(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for _, r := range results {
+		if r.MaxSeverity() != SeverityOK {
+			t.Fatalf("check %s failed: %+v", r.Name, r.Results)
+		}
+	}
+	if counting.versionCalls != 1 {
+		t.Errorf("expected ServerVersion to be called once, got %d", counting.versionCalls)
+	}
+	if counting.resourcesCalls != 1 {
+		t.Errorf("expected ServerGroupsAndResources to be called once, got %d", counting.resourcesCalls)
+	}
+
+	if _, err := engine.Run(context.Background(), client); err != nil {
+		t.Fatalf("second Run failed: %v", err)
+	}
+	if counting.versionCalls != 2 {
+		t.Errorf("expected a fresh discovery cache per run, got %d ServerVersion calls", counting.versionCalls)
+	}
+}
+
+type dynamicMockCheck struct {
+	mockCheck
+	dynamicCalled bool
+}
+
+func (m *dynamicMockCheck) RunDynamic(ctx context.Context, client kubernetes.Interface, dynamicClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface) (*CheckResult, error) {
+	m.dynamicCalled = true
+	return m.result, m.err
+}
+
+func TestEngineRunDynamic(t *testing.T) {
+	newCheck := func() *dynamicMockCheck {
+		return &dynamicMockCheck{mockCheck: mockCheck{
+			name:   "stalled-resources",
+			tier:   2,
+			result: &CheckResult{Name: "stalled-resources", Tier: 2, Results: []Result{}},
+		}}
+	}
+
+	check := newCheck()
+	engine := NewEngine(false)
+	engine.SetDynamicClients(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), fake.NewSimpleClientset().Discovery())
+	engine.Register(check)
+	if _, err := engine.Run(context.Background(), fake.NewSimpleClientset()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !check.dynamicCalled || check.called {
+		t.Errorf("expected RunDynamic instead of Run when dynamic clients are set (dynamic=%v, run=%v)", check.dynamicCalled, check.called)
+	}
+
+	check = newCheck()
+	engine = NewEngine(false)
+	engine.Register(check)
+	if _, err := engine.Run(context.Background(), fake.NewSimpleClientset()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if check.dynamicCalled || !check.called {
+		t.Errorf("expected Run without dynamic clients (dynamic=%v, run=%v)", check.dynamicCalled, check.called)
+	}
+}
+
+type metadataMockCheck struct {
+	mockCheck
+	metadataCalled bool
+}
+
+func (m *metadataMockCheck) RunMetadata(ctx context.Context, client kubernetes.Interface, metadataClient metadata.Interface) (*CheckResult, error) {
+	m.metadataCalled = true
+	return m.result, m.err
+}
+
+func TestEngineRunMetadata(t *testing.T) {
+	newCheck := func() *metadataMockCheck {
+		return &metadataMockCheck{mockCheck: mockCheck{
+			name:   "secret-references",
+			tier:   2,
+			result: &CheckResult{Name: "secret-references", Tier: 2, Results: []Result{}},
+		}}
+	}
+
+	check := newCheck()
+	engine := NewEngine(false)
+	engine.SetMetadataClient(metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()))
+	engine.Register(check)
+	if _, err := engine.Run(context.Background(), fake.NewSimpleClientset()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !check.metadataCalled || check.called {
+		t.Errorf("expected RunMetadata instead of Run when a metadata client is set (metadata=%v, run=%v)", check.metadataCalled, check.called)
+	}
+
+	check = newCheck()
+	engine = NewEngine(false)
+	engine.Register(check)
+	if _, err := engine.Run(context.Background(), fake.NewSimpleClientset()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if check.metadataCalled || !check.called {
+		t.Errorf("expected Run without a metadata client (metadata=%v, run=%v)", check.metadataCalled, check.called)
+	}
+}
+
+func TestEnginePopulatesDocURL(t *testing.T) {
+	newCheck := func() *mockCheck {
+		return &mockCheck{
+			name: "pod-status",
+			tier: 2,
+			result: &CheckResult{
+				Name: "pod-status",
+				Tier: 2,
+				Results: []Result{
+					{Severity: SeverityWarning, Message: "Pod app/web is restarting"},
+					{Severity: SeverityOK, Message: "10 pods running"},
+				},
+			},
+		}
+	}
+
+	engine := NewEngine(false)
+	engine.Register(newCheck())
+	results, _ := engine.Run(context.Background(), fake.NewSimpleClientset())
+	if got := results[0].Results[0].DocURL; got != config.DefaultDocBaseURL+"/pod-status" {
+		t.Errorf("expected default doc URL, got %q", got)
+	}
+	if got := results[0].Results[1].DocURL; got != "" {
+		t.Errorf("OK results should not get a doc URL, got %q", got)
+	}
+
+	engine = NewEngine(false)
+	cfg := config.DefaultConfig()
+	cfg.DocBaseURL = "https://wiki.example.com/runbooks/"
+	engine.SetConfig(cfg)
+	engine.Register(newCheck())
+	results, _ = engine.Run(context.Background(), fake.NewSimpleClientset())
+	if got := results[0].Results[0].DocURL; got != "https://wiki.example.com/runbooks/pod-status" {
+		t.Errorf("expected configured doc URL, got %q", got)
+	}
+}
+
+func TestEngineTruncatesResultsBeyondMax(t *testing.T) {
+	newCheck := func() *mockCheck {
+		results := make([]Result, 0, 7)
+		for i := 0; i < 5; i++ {
+			results = append(results, Result{Severity: SeverityWarning, Message: "Pod app/web is pending"})
+		}
+		results = append(results, Result{Severity: SeverityCritical, Message: "Pod app/db is crashing"})
+		results = append(results, Result{Severity: SeverityOK, Message: "Pod summary"})
+		return &mockCheck{
+			name:   "pod-status",
+			tier:   2,
+			result: &CheckResult{Name: "pod-status", Tier: 2, Results: results},
+		}
+	}
+
+	engine := NewEngine(false)
+	engine.SetMaxResults(3)
+	engine.Register(newCheck())
+
+	got, err := engine.Run(context.Background(), fake.NewSimpleClientset())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(got[0].Results) != 5 {
+		t.Fatalf("expected 3 findings, the summary and a truncation note, got %d: %+v", len(got[0].Results), got[0].Results)
+	}
+	if got[0].Results[2].Message != "Pod app/db is crashing" {
+		t.Errorf("the most severe finding should be kept even when it comes last, got %q", got[0].Results[2].Message)
+	}
+	if got[0].Results[3].Message != "Pod summary" {
+		t.Errorf("OK results should be kept, got %q", got[0].Results[3].Message)
+	}
+	note := got[0].Results[4]
+	if note.Message != "…and 3 more" {
+		t.Errorf("unexpected truncation note: %q", note.Message)
+	}
+	if note.Severity != SeverityWarning || got[0].MaxSeverity() != SeverityCritical {
+		t.Errorf("truncation note should carry the highest dropped severity, got %s", note.Severity)
+	}
+
+	engine = NewEngine(false)
+	engine.SetMaxResults(0)
+	engine.Register(newCheck())
+	got, _ = engine.Run(context.Background(), fake.NewSimpleClientset())
+	if len(got[0].Results) != 7 {
+		t.Errorf("max results of 0 should disable truncation, got %d results", len(got[0].Results))
+	}
+}
+
+type listingCheck struct {
+	total   int
+	fetched int
+	sampled bool
+}
+
+func (c *listingCheck) Name() string { return "pod-status" }
+func (c *listingCheck) Tier() int    { return 2 }
+func (c *listingCheck) Run(ctx context.Context, client kubernetes.Interface) (*CheckResult, error) {
+	items, meta, err := ListAll(ctx, "pods", func(opts metav1.ListOptions) ([]int, metav1.ListMeta, error) {
+		start, _ := strconv.Atoi(opts.Continue)
+		end := start + int(opts.Limit)
+		if end > c.total {
+			end = c.total
+		}
+		page := make([]int, end-start)
+		remaining := int64(c.total - end)
+		meta := metav1.ListMeta{RemainingItemCount: &remaining}
+		if end < c.total {
+			meta.Continue = strconv.Itoa(end)
+		}
+		return page, meta, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.fetched = len(items)
+	c.sampled = IsSampled(meta)
+	return &CheckResult{Name: c.Name(), Tier: c.Tier(), Results: []Result{{Severity: SeverityOK, Message: "Pod summary"}}}, nil
+}
+
+func TestEngineResourceBudgetTruncatesListing(t *testing.T) {
+	check := &listingCheck{total: 1200}
+	engine := NewEngine(false)
+	engine.SetResourceBudget(700)
+	engine.Register(check)
+
+	results, err := engine.Run(context.Background(), fake.NewSimpleClientset())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if check.fetched != 700 {
+		t.Errorf("expected the budget to cap the listing at 700 objects, got %d", check.fetched)
+	}
+	if !check.sampled {
+		t.Error("ListAll should tell the caller that the listing was sampled")
+	}
+	if len(results[0].Results) != 2 {
+		t.Fatalf("expected the check result and a sampling note, got %+v", results[0].Results)
+	}
+	if note := results[0].Results[1]; note.Message != "Sampled 700 of 1200 pods (resource budget reached)" {
+		t.Errorf("unexpected sampling note: %q", note.Message)
+	}
+
+	check = &listingCheck{total: 1200}
+	engine = NewEngine(false)
+	engine.Register(check)
+	results, _ = engine.Run(context.Background(), fake.NewSimpleClientset())
+	if check.fetched != 1200 {
+		t.Errorf("without a budget every page should be fetched, got %d objects", check.fetched)
+	}
+	if check.sampled {
+		t.Error("a complete listing should not be reported as sampled")
+	}
+	if len(results[0].Results) != 1 {
+		t.Errorf("no sampling note expected without a budget, got %+v", results[0].Results)
+	}
+}
@@ -0,0 +1,41 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+)
+
+func openSince(r probe.Result) *time.Time {
+	if !r.Severity.IsIssue() || r.OpenSince.IsZero() {
+		return nil
+	}
+	since := r.OpenSince.UTC()
+	return &since
+}
+
+func oldestOpenSince(results []ResultOutput) *time.Time {
+	var oldest *time.Time
+	for _, r := range results {
+		if r.OpenSince != nil && (oldest == nil || r.OpenSince.Before(*oldest)) {
+			oldest = r.OpenSince
+		}
+	}
+	return oldest
+}
+
+func formatOpenSince(since, now time.Time) string {
+	return fmt.Sprintf("%s (%s)", since.Format("2006-01-02 15:04 UTC"), formatAge(now.Sub(since)))
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
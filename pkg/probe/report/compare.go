@@ -0,0 +1,145 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/storage"
+)
+
+type Comparison struct {
+	Cluster      string      `json:"cluster"`
+	PreviousTime time.Time   `json:"previous_time"`
+	CurrentTime  time.Time   `json:"current_time"`
+	Summary      Summary     `json:"summary"`
+	Diff         *DiffOutput `json:"diff"`
+}
+
+func LoadScan(path string) (*storage.ScanRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan %s: %w", path, err)
+	}
+
+	var shape struct {
+		Checks json.RawMessage `json:"checks"`
+	}
+	if err := json.Unmarshal(data, &shape); err != nil {
+		return nil, fmt.Errorf("failed to parse scan %s: %w", path, err)
+	}
+
+	if shape.Checks == nil {
+		var record storage.ScanRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("failed to parse scan %s: %w", path, err)
+		}
+		return &record, nil
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return ScanRecordFromReport(&report), nil
+}
+
+func ScanRecordFromReport(report *Report) *storage.ScanRecord {
+	record := &storage.ScanRecord{
+		Timestamp: report.Timestamp,
+		Cluster:   report.Cluster,
+		Summary: storage.ScanSummary{
+			Total:    report.Summary.Total,
+			Critical: report.Summary.Critical + report.Summary.Errored,
+			Warning:  report.Summary.Warning,
+			OK:       report.Summary.OK + report.Summary.Info,
+		},
+		Issues: make([]storage.StoredIssue, 0),
+	}
+
+	for _, check := range report.CheckResults {
+		if check.Error != "" {
+			severity := probe.SeverityCritical.String()
+			record.Issues = append(record.Issues, storage.StoredIssue{
+				CheckName:   check.Name,
+				Severity:    severity,
+				Message:     "Check failed to execute",
+				Fingerprint: storage.GenerateFingerprint(check.Name, "", "Check failed to execute"),
+			})
+		}
+		for _, r := range check.Results {
+			if !isIssue(r.Severity) {
+				continue
+			}
+			record.Issues = append(record.Issues, storage.StoredIssue{
+				CheckName:   check.Name,
+				Severity:    r.Severity,
+				Namespace:   r.Namespace,
+				Message:     r.Message,
+				Fingerprint: storage.GenerateFingerprint(check.Name, r.Namespace, r.Message),
+			})
+		}
+	}
+
+	return record
+}
+
+func (w *Writer) WriteComparison(previous, current *storage.ScanRecord, diff *storage.ScanDiff) error {
+	comparison := &Comparison{
+		Cluster:      w.clusterName(current.Cluster),
+		PreviousTime: previous.Timestamp,
+		CurrentTime:  current.Timestamp,
+		Summary: Summary{
+			Total:    current.Summary.Total,
+			Critical: current.Summary.Critical,
+			Warning:  current.Summary.Warning,
+			OK:       current.Summary.OK,
+		},
+		Diff: sortedDiffOutput(diff),
+	}
+
+	if w.format == FormatJSON {
+		encoder := json.NewEncoder(w.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(comparison)
+	}
+
+	fmt.Fprintln(w.w)
+	fmt.Fprintln(w.w, "  CLUSTER PROBE COMPARISON")
+	fmt.Fprintln(w.w, strings.Repeat("─", 60))
+	if comparison.Cluster != "" {
+		fmt.Fprintf(w.w, "  Cluster:  %s\n", comparison.Cluster)
+	}
+	fmt.Fprintf(w.w, "  Previous: %s\n", comparison.PreviousTime.Format("2006-01-02 15:04:05 UTC"))
+	fmt.Fprintf(w.w, "  Current:  %s\n", comparison.CurrentTime.Format("2006-01-02 15:04:05 UTC"))
+	fmt.Fprintln(w.w)
+
+	if len(comparison.Diff.NewIssues) == 0 && len(comparison.Diff.ResolvedIssues) == 0 {
+		fmt.Fprintln(w.w, "  No issues changed between the two scans.")
+		fmt.Fprintln(w.w)
+	} else {
+		w.writeDiff(comparison.Diff)
+	}
+
+	fmt.Fprintf(w.w, "  Summary: %s\n", formatSummary(comparison.Summary, comparison.Diff))
+	fmt.Fprintln(w.w)
+
+	return nil
+}
+
+func sortedDiffOutput(diff *storage.ScanDiff) *DiffOutput {
+	out := diffOutput(diff)
+	for _, issues := range [][]IssueOutput{out.NewIssues, out.ResolvedIssues} {
+		sort.Slice(issues, func(i, j int) bool {
+			if issues[i].Check != issues[j].Check {
+				return issues[i].Check < issues[j].Check
+			}
+			return issues[i].Message < issues[j].Message
+		})
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	ConfigMapReportKey    = "report.json"
+	ConfigMapSummaryKey   = "summary.json"
+	ConfigMapTruncatedKey = "truncated"
+
+	configMapMaxBytes = 900 * 1024
+)
+
+func ParseConfigMapRef(ref string) (string, string, error) {
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid ConfigMap reference %q (expected namespace/name)", ref)
+	}
+	return namespace, name, nil
+}
+
+func (w *Writer) WriteConfigMap(ctx context.Context, client kubernetes.Interface, namespace, name string, results []probe.CheckResult, clusterInfo string) error {
+	jsonWriter := *w
+	jsonWriter.format = FormatJSON
+
+	data, err := jsonWriter.configMapData(jsonWriter.buildReport(results, clusterInfo))
+	if err != nil {
+		return err
+	}
+
+	configMaps := client.CoreV1().ConfigMaps(namespace)
+	existing, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, name, err)
+		}
+
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":       "cluster-probe",
+					"app.kubernetes.io/managed-by": "cluster-probe",
+				},
+			},
+			Data: data,
+		}
+		if _, err := configMaps.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create ConfigMap %s/%s: %w", namespace, name, err)
+		}
+		return nil
+	}
+
+	existing.Data = data
+	if _, err := configMaps.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+func (w *Writer) configMapData(report *Report) (map[string]string, error) {
+	summary, err := json.Marshal(report.Summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode summary: %w", err)
+	}
+
+	full, err := w.marshalReport(report)
+	if err != nil {
+		return nil, err
+	}
+	if len(full)+len(summary) <= configMapMaxBytes {
+		return map[string]string{
+			ConfigMapReportKey:  string(full),
+			ConfigMapSummaryKey: string(summary),
+		}, nil
+	}
+
+	issuesOnly, err := w.marshalReport(withoutOKResults(report))
+	if err != nil {
+		return nil, err
+	}
+	if len(issuesOnly)+len(summary) <= configMapMaxBytes {
+		return map[string]string{
+			ConfigMapReportKey:    string(issuesOnly),
+			ConfigMapSummaryKey:   string(summary),
+			ConfigMapTruncatedKey: "ok results omitted",
+		}, nil
+	}
+
+	return map[string]string{
+		ConfigMapSummaryKey:   string(summary),
+		ConfigMapTruncatedKey: "report omitted",
+	}, nil
+}
+
+func (w *Writer) marshalReport(report *Report) ([]byte, error) {
+	var v interface{} = report
+	if w.formatVersion == FormatVersion1 {
+		v = toReportV1(report)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	return data, nil
+}
+
+func withoutOKResults(report *Report) *Report {
+	trimmed := *report
+	trimmed.CheckResults = make([]CheckOutput, 0, len(report.CheckResults))
+	for _, check := range report.CheckResults {
+		if check.Severity == probe.SeverityOK.String() || check.Severity == probe.SeverityInfo.String() {
+			continue
+		}
+		out := check
+		out.Results = make([]ResultOutput, 0, len(check.Results))
+		for _, r := range check.Results {
+			if isIssue(r.Severity) {
+				out.Results = append(out.Results, r)
+			}
+		}
+		trimmed.CheckResults = append(trimmed.CheckResults, out)
+	}
+	return &trimmed
+}
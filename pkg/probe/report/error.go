@@ -0,0 +1,21 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type errorOutput struct {
+	Error string `json:"error"`
+	Code  int    `json:"code"`
+}
+
+func WriteError(w io.Writer, message string, code int) error {
+	data, err := json.Marshal(errorOutput{Error: message, Code: code})
+	if err != nil {
+		return fmt.Errorf("failed to encode error: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,54 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+)
+
+func (w *Writer) WriteFixScript(results []probe.CheckResult, clusterInfo string) error {
+	report := w.buildReport(results, clusterInfo)
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Remediation commands collected by cluster-probe\n")
+	if report.Cluster != "" {
+		fmt.Fprintf(&b, "# Cluster: %s\n", report.Cluster)
+	}
+	fmt.Fprintf(&b, "# Generated: %s\n", report.Timestamp.Format(time.RFC3339))
+	b.WriteString("#\n")
+	b.WriteString("# Every command is commented out. Review each one against the finding above it,\n")
+	b.WriteString("# then uncomment the ones you want to run.\n")
+	b.WriteString("\nset -eu\n")
+
+	commands := 0
+	for _, check := range report.CheckResults {
+		header := false
+		for _, r := range check.Results {
+			if !isIssue(r.Severity) || len(r.RemediationCommands) == 0 {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(&b, "\n# ==== %s ====\n", check.Name)
+				header = true
+			}
+			fmt.Fprintf(&b, "\n# [%s] %s\n", r.Severity, r.Message)
+			if r.Remediation != "" {
+				fmt.Fprintf(&b, "#   %s\n", r.Remediation)
+			}
+			for _, cmd := range r.RemediationCommands {
+				fmt.Fprintf(&b, "# %s\n", strings.ReplaceAll(cmd, "\n", "\n# "))
+				commands++
+			}
+		}
+	}
+
+	if commands == 0 {
+		b.WriteString("\n# No findings with remediation commands\n")
+	}
+
+	_, err := fmt.Fprint(w.w, b.String())
+	return err
+}
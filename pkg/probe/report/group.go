@@ -0,0 +1,161 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+)
+
+const (
+	GroupByCheck     = "check"
+	GroupByNamespace = "namespace"
+)
+
+type GroupedReport struct {
+	FormatVersion int                        `json:"format_version"`
+	Timestamp     time.Time                  `json:"timestamp"`
+	Cluster       string                     `json:"cluster"`
+	Summary       Summary                    `json:"summary"`
+	Namespaces    map[string]*NamespaceGroup `json:"namespaces"`
+	ClusterScoped *NamespaceGroup            `json:"cluster_scoped,omitempty"`
+	Diff          *DiffOutput                `json:"diff,omitempty"`
+}
+
+type NamespaceGroup struct {
+	Checks []CheckOutput `json:"checks"`
+}
+
+func (w *Writer) SetGroupBy(groupBy string) error {
+	switch groupBy {
+	case "", GroupByCheck:
+		w.groupBy = GroupByCheck
+	case GroupByNamespace:
+		w.groupBy = GroupByNamespace
+	default:
+		return fmt.Errorf("unsupported group-by %q (supported: %s, %s)", groupBy, GroupByCheck, GroupByNamespace)
+	}
+	return nil
+}
+
+func (w *Writer) writeGroupedJSON(report *Report, results []probe.CheckResult) error {
+	encoder := json.NewEncoder(w.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(groupByNamespace(report, results))
+}
+
+func groupByNamespace(report *Report, results []probe.CheckResult) *GroupedReport {
+	grouped := &GroupedReport{
+		FormatVersion: CurrentFormatVersion,
+		Timestamp:     report.Timestamp,
+		Cluster:       report.Cluster,
+		Summary:       report.Summary,
+		Namespaces:    make(map[string]*NamespaceGroup),
+		Diff:          report.Diff,
+	}
+
+	for i, cr := range results {
+		if cr.Errored() {
+			if grouped.ClusterScoped == nil {
+				grouped.ClusterScoped = &NamespaceGroup{}
+			}
+			grouped.ClusterScoped.Checks = append(grouped.ClusterScoped.Checks, checkOutput(&results[i]))
+			continue
+		}
+
+		byNamespace := make(map[string]*probe.CheckResult)
+		var order []string
+
+		for _, r := range cr.Results {
+			ns := r.Namespace
+			if _, ok := byNamespace[ns]; !ok {
+				byNamespace[ns] = &probe.CheckResult{Name: cr.Name, Tier: cr.Tier}
+				order = append(order, ns)
+			}
+			byNamespace[ns].Results = append(byNamespace[ns].Results, r)
+		}
+
+		for _, ns := range order {
+			group := grouped.ClusterScoped
+			if ns != "" {
+				group = grouped.Namespaces[ns]
+			}
+			if group == nil {
+				group = &NamespaceGroup{}
+				if ns == "" {
+					grouped.ClusterScoped = group
+				} else {
+					grouped.Namespaces[ns] = group
+				}
+			}
+			group.Checks = append(group.Checks, checkOutput(byNamespace[ns]))
+		}
+	}
+
+	return grouped
+}
+
+func checkOutput(cr *probe.CheckResult) CheckOutput {
+	out := CheckOutput{
+		Name:     cr.Name,
+		Tier:     cr.Tier,
+		Severity: checkSeverity(*cr),
+		Error:    cr.Error,
+		Results:  make([]ResultOutput, 0, len(cr.Results)),
+	}
+	for _, r := range cr.Results {
+		out.Results = append(out.Results, ResultOutput{
+			Severity:            r.Severity.String(),
+			Message:             r.Message,
+			Namespace:           r.Namespace,
+			Details:             r.Details,
+			Remediation:         r.Remediation,
+			RemediationCommands: r.RemediationCommands,
+			DocURL:              r.DocURL,
+			OpenSince:           openSince(r),
+		})
+	}
+	out.OpenSince = oldestOpenSince(out.Results)
+	return out
+}
+
+func (w *Writer) writeNamespaceIssues(report *Report) {
+	byNamespace := make(map[string][]string)
+	for _, check := range report.CheckResults {
+		for _, r := range check.Results {
+			if r.Severity != "CRITICAL" && r.Severity != "WARNING" {
+				continue
+			}
+			byNamespace[r.Namespace] = append(byNamespace[r.Namespace], fmt.Sprintf("%s [%s] %s", severityIcon(r.Severity), check.Name, r.Message))
+		}
+	}
+	if len(byNamespace) == 0 {
+		return
+	}
+
+	namespaces := make([]string, 0, len(byNamespace))
+	for ns := range byNamespace {
+		if ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+	if _, ok := byNamespace[""]; ok {
+		namespaces = append(namespaces, "")
+	}
+
+	fmt.Fprintln(w.w, "  Issues by Namespace:")
+	for _, ns := range namespaces {
+		label := ns
+		if label == "" {
+			label = "(cluster-scoped)"
+		}
+		fmt.Fprintf(w.w, "  %s:\n", label)
+		for _, line := range byNamespace[ns] {
+			fmt.Fprintf(w.w, "    %s\n", line)
+		}
+	}
+	fmt.Fprintln(w.w)
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/punasusi/cluster-probe/pkg/probe"
+)
+
+const DefaultPushgatewayJob = "cluster-probe"
+
+type checkMetrics struct {
+	severity *prometheus.GaugeVec
+	results  *prometheus.GaugeVec
+	lastRun  prometheus.Gauge
+}
+
+func newCheckMetrics() *checkMetrics {
+	return &checkMetrics{
+		severity: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cluster_probe_check_severity",
+			Help: "Highest severity reported by a check (0=OK, 1=WARNING, 2=CRITICAL).",
+		}, []string{"check", "tier"}),
+		results: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cluster_probe_check_results",
+			Help: "Number of results reported by a check, by severity.",
+		}, []string{"check", "severity"}),
+		lastRun: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "cluster_probe_last_run_timestamp_seconds",
+			Help: "Unix time of the scan that produced these metrics.",
+		}),
+	}
+}
+
+func NewMetricsRegistry(results []probe.CheckResult, timestamp time.Time) *prometheus.Registry {
+	metrics := newCheckMetrics()
+	for _, cr := range results {
+		severity := cr.MaxSeverity()
+		if severity < probe.SeverityOK {
+			severity = probe.SeverityOK
+		}
+		metrics.severity.WithLabelValues(cr.Name, strconv.Itoa(cr.Tier)).Set(float64(severity))
+		for _, severity := range []probe.Severity{probe.SeverityInfo, probe.SeverityOK, probe.SeverityWarning, probe.SeverityCritical} {
+			metrics.results.WithLabelValues(cr.Name, severity.String()).Set(0)
+		}
+		for _, r := range cr.Results {
+			metrics.results.WithLabelValues(cr.Name, r.Severity.String()).Inc()
+		}
+	}
+	metrics.lastRun.Set(float64(timestamp.Unix()))
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics.severity, metrics.results, metrics.lastRun)
+	return registry
+}
+
+func PushMetrics(url, job string, results []probe.CheckResult) error {
+	if job == "" {
+		job = DefaultPushgatewayJob
+	}
+	registry := NewMetricsRegistry(results, time.Now())
+	if err := push.New(url, job).Gatherer(registry).Push(); err != nil {
+		return fmt.Errorf("failed to push metrics to %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+func RedactClusterName(name string) string {
+	if name == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(name))
+	return "redacted-" + hex.EncodeToString(sum[:])[:8]
+}
+
+func (w *Writer) SetRedactCluster(redact bool) {
+	w.redactCluster = redact
+}
+
+func (w *Writer) clusterName(name string) string {
+	if w.redactCluster {
+		return RedactClusterName(name)
+	}
+	return name
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/punasusi/cluster-probe/pkg/probe"
@@ -19,12 +20,19 @@ const (
 	FormatJSON	Format	= "json"
 )
 
+const (
+	topNamespacesLimit	= 10
+	SeverityError		= "ERROR"
+)
+
 type Report struct {
-	Timestamp	time.Time	`json:"timestamp"`
-	Cluster		string		`json:"cluster"`
-	Summary		Summary		`json:"summary"`
-	CheckResults	[]CheckOutput	`json:"checks"`
-	Diff		*DiffOutput	`json:"diff,omitempty"`
+	FormatVersion	int			`json:"format_version"`
+	Timestamp	time.Time		`json:"timestamp"`
+	Cluster		string			`json:"cluster"`
+	Summary		Summary			`json:"summary"`
+	CheckResults	[]CheckOutput		`json:"checks"`
+	TopNamespaces	[]NamespaceIssues	`json:"top_namespaces,omitempty"`
+	Diff		*DiffOutput		`json:"diff,omitempty"`
 }
 
 type Summary struct {
@@ -32,20 +40,35 @@ type Summary struct {
 	Critical	int	`json:"critical"`
 	Warning		int	`json:"warning"`
 	OK		int	`json:"ok"`
+	Info		int	`json:"info,omitempty"`
+	Errored		int	`json:"errored,omitempty"`
 }
 
 type CheckOutput struct {
 	Name		string		`json:"name"`
 	Tier		int		`json:"tier"`
 	Severity	string		`json:"severity"`
+	Error		string		`json:"error,omitempty"`
+	OpenSince	*time.Time	`json:"open_since,omitempty"`
 	Results		[]ResultOutput	`json:"results"`
 }
 
 type ResultOutput struct {
-	Severity	string		`json:"severity"`
-	Message		string		`json:"message"`
-	Details		[]string	`json:"details,omitempty"`
-	Remediation	string		`json:"remediation,omitempty"`
+	Severity		string		`json:"severity"`
+	Message			string		`json:"message"`
+	Namespace		string		`json:"namespace,omitempty"`
+	Details			[]string	`json:"details,omitempty"`
+	Remediation		string		`json:"remediation,omitempty"`
+	RemediationCommands	[]string	`json:"remediation_commands,omitempty"`
+	DocURL			string		`json:"doc_url,omitempty"`
+	OpenSince		*time.Time	`json:"open_since,omitempty"`
+}
+
+type NamespaceIssues struct {
+	Namespace	string	`json:"namespace"`
+	Critical	int	`json:"critical"`
+	Warning		int	`json:"warning"`
+	Total		int	`json:"total"`
 }
 
 type DiffOutput struct {
@@ -63,10 +86,16 @@ type IssueOutput struct {
 }
 
 type Writer struct {
-	w	io.Writer
-	format	Format
-	verbose	bool
-	diff	*storage.ScanDiff
+	w		io.Writer
+	format		Format
+	verbose		bool
+	includeOK	bool
+	redactCluster	bool
+	diff		*storage.ScanDiff
+	template	*template.Template
+	formatVersion	int
+	groupBy		string
+	sortBy		string
 }
 
 func NewWriter(w io.Writer, format Format, verbose bool) *Writer {
@@ -74,6 +103,9 @@ func NewWriter(w io.Writer, format Format, verbose bool) *Writer {
 		w:		w,
 		format:		format,
 		verbose:	verbose,
+		formatVersion:	CurrentFormatVersion,
+		groupBy:	GroupByCheck,
+		sortBy:		SortSeverity,
 	}
 }
 
@@ -81,13 +113,35 @@ func (w *Writer) SetDiff(diff *storage.ScanDiff) {
 	w.diff = diff
 }
 
+func (w *Writer) SetIncludeOK(include bool) {
+	w.includeOK = include
+}
+
+func (w *Writer) SetTemplate(tmpl *template.Template) {
+	w.template = tmpl
+}
+
+func (w *Writer) SetFormatVersion(version int) error {
+	if err := validateFormatVersion(version); err != nil {
+		return err
+	}
+	w.formatVersion = version
+	return nil
+}
+
 func (w *Writer) Write(results []probe.CheckResult, clusterInfo string) error {
 	report := w.buildReport(results, clusterInfo)
 
 	switch w.format {
 	case FormatJSON:
+		if w.groupBy == GroupByNamespace {
+			return w.writeGroupedJSON(report, results)
+		}
 		return w.writeJSON(report)
 	default:
+		if w.template != nil {
+			return w.template.Execute(w.w, report)
+		}
 		return w.writeText(report)
 	}
 }
@@ -102,78 +156,97 @@ func (w *Writer) buildReport(results []probe.CheckResult, clusterInfo string) *R
 	})
 
 	report := &Report{
+		FormatVersion:	w.formatVersion,
 		Timestamp:	time.Now().UTC(),
-		Cluster:	clusterInfo,
+		Cluster:	w.clusterName(clusterInfo),
 		CheckResults:	make([]CheckOutput, 0, len(results)),
 	}
 
-	for _, cr := range results {
-		severity := cr.MaxSeverity()
-
-		switch severity {
-		case probe.SeverityCritical:
-			report.Summary.Critical++
-		case probe.SeverityWarning:
-			report.Summary.Warning++
-		case probe.SeverityOK:
-			report.Summary.OK++
-		}
-		report.Summary.Total++
+	report.Summary = Summarize(results)
 
+	for _, cr := range results {
 		checkOutput := CheckOutput{
 			Name:		cr.Name,
 			Tier:		cr.Tier,
-			Severity:	severity.String(),
+			Severity:	checkSeverity(cr),
+			Error:		cr.Error,
 			Results:	make([]ResultOutput, 0),
 		}
 
-		for _, r := range cr.Results {
+		for _, r := range w.orderResults(cr.Results) {
 
-			if r.Severity == probe.SeverityOK && !w.verbose && w.format == FormatText {
+			if !r.Severity.IsIssue() && !w.verbose && !w.includeOK && w.format == FormatText {
 				continue
 			}
 
 			checkOutput.Results = append(checkOutput.Results, ResultOutput{
-				Severity:	r.Severity.String(),
-				Message:	r.Message,
-				Details:	r.Details,
-				Remediation:	r.Remediation,
+				Severity:		r.Severity.String(),
+				Message:		r.Message,
+				Namespace:		r.Namespace,
+				Details:		r.Details,
+				Remediation:		r.Remediation,
+				RemediationCommands:	r.RemediationCommands,
+				DocURL:			r.DocURL,
+				OpenSince:		openSince(r),
 			})
 		}
+		checkOutput.OpenSince = oldestOpenSince(checkOutput.Results)
 
 		report.CheckResults = append(report.CheckResults, checkOutput)
 	}
 
+	report.TopNamespaces = topNamespaces(results)
+
 	if w.diff != nil && w.diff.HasPrevious {
-		report.Diff = &DiffOutput{
-			PreviousTime:	w.diff.PreviousTime,
-			CriticalDelta:	w.diff.SummaryChange.CriticalDelta,
-			WarningDelta:	w.diff.SummaryChange.WarningDelta,
-		}
+		report.Diff = diffOutput(w.diff)
+	}
 
-		for _, issue := range w.diff.NewIssues {
-			report.Diff.NewIssues = append(report.Diff.NewIssues, IssueOutput{
-				Check:		issue.CheckName,
-				Severity:	issue.Severity,
-				Message:	issue.Message,
-			})
-		}
+	return report
+}
 
-		for _, issue := range w.diff.ResolvedIssues {
-			report.Diff.ResolvedIssues = append(report.Diff.ResolvedIssues, IssueOutput{
-				Check:		issue.CheckName,
-				Severity:	issue.Severity,
-				Message:	issue.Message,
-			})
-		}
+func isIssue(severity string) bool {
+	return severity == probe.SeverityWarning.String() || severity == probe.SeverityCritical.String()
+}
+
+func checkSeverity(cr probe.CheckResult) string {
+	if cr.Errored() {
+		return SeverityError
 	}
+	return cr.MaxSeverity().String()
+}
 
-	return report
+func diffOutput(diff *storage.ScanDiff) *DiffOutput {
+	out := &DiffOutput{
+		PreviousTime:	diff.PreviousTime,
+		CriticalDelta:	diff.SummaryChange.CriticalDelta,
+		WarningDelta:	diff.SummaryChange.WarningDelta,
+	}
+
+	for _, issue := range diff.NewIssues {
+		out.NewIssues = append(out.NewIssues, IssueOutput{
+			Check:		issue.CheckName,
+			Severity:	issue.Severity,
+			Message:	issue.Message,
+		})
+	}
+
+	for _, issue := range diff.ResolvedIssues {
+		out.ResolvedIssues = append(out.ResolvedIssues, IssueOutput{
+			Check:		issue.CheckName,
+			Severity:	issue.Severity,
+			Message:	issue.Message,
+		})
+	}
+
+	return out
 }
 
 func (w *Writer) writeJSON(report *Report) error {
 	encoder := json.NewEncoder(w.w)
 	encoder.SetIndent("", "  ")
+	if w.formatVersion == FormatVersion1 {
+		return encoder.Encode(toReportV1(report))
+	}
 	return encoder.Encode(report)
 }
 
@@ -193,50 +266,81 @@ func (w *Writer) writeText(report *Report) error {
 		w.writeVerboseChecks(report)
 	} else {
 
-		w.writeCriticalIssues(report)
+		w.writeErroredChecks(report)
+		if w.groupBy == GroupByNamespace {
+			w.writeNamespaceIssues(report)
+		} else {
+			w.writeCriticalIssues(report)
+		}
+		if w.includeOK {
+			w.writePassedResults(report)
+		}
+	}
+
+	if len(report.TopNamespaces) > 0 {
+		w.writeTopNamespaces(report.TopNamespaces)
 	}
 
 	if report.Diff != nil {
 		w.writeDiff(report.Diff)
 	}
 
+	fmt.Fprintf(w.w, "  Summary: %s\n", formatSummary(report.Summary, report.Diff))
+	fmt.Fprintln(w.w)
+
+	return nil
+}
+
+func formatSummary(summary Summary, diff *DiffOutput) string {
 	summaryParts := []string{}
-	if report.Summary.Critical > 0 {
-		summaryParts = append(summaryParts, fmt.Sprintf("✗ %d critical", report.Summary.Critical))
+	if summary.Errored > 0 {
+		summaryParts = append(summaryParts, fmt.Sprintf("! %d errored", summary.Errored))
 	}
-	if report.Summary.Warning > 0 {
-		summaryParts = append(summaryParts, fmt.Sprintf("⚠ %d warning", report.Summary.Warning))
+	if summary.Critical > 0 {
+		summaryParts = append(summaryParts, fmt.Sprintf("✗ %d critical", summary.Critical))
 	}
-	if report.Summary.OK > 0 {
-		summaryParts = append(summaryParts, fmt.Sprintf("✓ %d passed", report.Summary.OK))
+	if summary.Warning > 0 {
+		summaryParts = append(summaryParts, fmt.Sprintf("⚠ %d warning", summary.Warning))
+	}
+	if summary.OK > 0 {
+		summaryParts = append(summaryParts, fmt.Sprintf("✓ %d passed", summary.OK))
+	}
+	if summary.Info > 0 {
+		summaryParts = append(summaryParts, fmt.Sprintf("ℹ %d info", summary.Info))
 	}
 
 	deltaStr := ""
-	if report.Diff != nil {
+	if diff != nil {
 		deltas := []string{}
-		if report.Diff.CriticalDelta != 0 {
+		if diff.CriticalDelta != 0 {
 			sign := "+"
-			if report.Diff.CriticalDelta < 0 {
+			if diff.CriticalDelta < 0 {
 				sign = ""
 			}
-			deltas = append(deltas, fmt.Sprintf("%s%d critical", sign, report.Diff.CriticalDelta))
+			deltas = append(deltas, fmt.Sprintf("%s%d critical", sign, diff.CriticalDelta))
 		}
-		if report.Diff.WarningDelta != 0 {
+		if diff.WarningDelta != 0 {
 			sign := "+"
-			if report.Diff.WarningDelta < 0 {
+			if diff.WarningDelta < 0 {
 				sign = ""
 			}
-			deltas = append(deltas, fmt.Sprintf("%s%d warning", sign, report.Diff.WarningDelta))
+			deltas = append(deltas, fmt.Sprintf("%s%d warning", sign, diff.WarningDelta))
 		}
 		if len(deltas) > 0 {
 			deltaStr = fmt.Sprintf(" (%s since last scan)", strings.Join(deltas, ", "))
 		}
 	}
 
-	fmt.Fprintf(w.w, "  Summary: %s%s\n", strings.Join(summaryParts, "  "), deltaStr)
-	fmt.Fprintln(w.w)
+	return strings.Join(summaryParts, "  ") + deltaStr
+}
 
-	return nil
+func (w *Writer) writeTopNamespaces(namespaces []NamespaceIssues) {
+	fmt.Fprintln(w.w, "  Top Namespaces by Issues:")
+	fmt.Fprintf(w.w, "    %-30s %8s %8s %6s\n", "NAMESPACE", "CRITICAL", "WARNING", "TOTAL")
+	for _, ns := range namespaces {
+		fmt.Fprintf(w.w, "    %-30s %8d %8d %6d\n", ns.Namespace, ns.Critical, ns.Warning, ns.Total)
+	}
+	fmt.Fprintln(w.w)
 }
 
 func (w *Writer) writeDiff(diff *DiffOutput) {
@@ -259,6 +363,27 @@ func (w *Writer) writeDiff(diff *DiffOutput) {
 	}
 }
 
+func (w *Writer) writeErroredChecks(report *Report) {
+	hasErrored := false
+
+	for _, check := range report.CheckResults {
+		if check.Error == "" {
+			continue
+		}
+
+		if !hasErrored {
+			fmt.Fprintln(w.w, "  Errored Checks (failed to run):")
+			hasErrored = true
+		}
+
+		fmt.Fprintf(w.w, "  ! [%s] %s\n", check.Name, check.Error)
+	}
+
+	if hasErrored {
+		fmt.Fprintln(w.w)
+	}
+}
+
 func (w *Writer) writeCriticalIssues(report *Report) {
 	hasCritical := false
 
@@ -278,9 +403,15 @@ func (w *Writer) writeCriticalIssues(report *Report) {
 			}
 
 			fmt.Fprintf(w.w, "  ✗ [%s] %s\n", check.Name, r.Message)
+			if r.OpenSince != nil {
+				fmt.Fprintf(w.w, "    open since %s\n", formatOpenSince(*r.OpenSince, report.Timestamp))
+			}
 			if r.Remediation != "" {
 				fmt.Fprintf(w.w, "    → %s\n", r.Remediation)
 			}
+			for _, cmd := range r.RemediationCommands {
+				fmt.Fprintf(w.w, "      $ %s\n", cmd)
+			}
 		}
 	}
 
@@ -289,6 +420,29 @@ func (w *Writer) writeCriticalIssues(report *Report) {
 	}
 }
 
+func (w *Writer) writePassedResults(report *Report) {
+	hasPassed := false
+
+	for _, check := range report.CheckResults {
+		for _, r := range check.Results {
+			if isIssue(r.Severity) {
+				continue
+			}
+
+			if !hasPassed {
+				fmt.Fprintln(w.w, "  Passed:")
+				hasPassed = true
+			}
+
+			fmt.Fprintf(w.w, "  %s [%s] %s\n", severityIcon(r.Severity), check.Name, r.Message)
+		}
+	}
+
+	if hasPassed {
+		fmt.Fprintln(w.w)
+	}
+}
+
 func (w *Writer) writeVerboseChecks(report *Report) {
 
 	currentTier := 0
@@ -311,18 +465,36 @@ func (w *Writer) writeVerboseChecks(report *Report) {
 		}
 
 		icon := severityIcon(check.Severity)
-		fmt.Fprintf(w.w, "  │ %s %s\n", icon, check.Name)
+		if check.OpenSince != nil {
+			fmt.Fprintf(w.w, "  │ %s %s (oldest issue open since %s)\n", icon, check.Name, formatOpenSince(*check.OpenSince, report.Timestamp))
+		} else {
+			fmt.Fprintf(w.w, "  │ %s %s\n", icon, check.Name)
+		}
+		if check.Error != "" {
+			fmt.Fprintf(w.w, "  │   failed to run: %s\n", check.Error)
+		}
 
 		for _, r := range check.Results {
 			rIcon := severityIcon(r.Severity)
 			fmt.Fprintf(w.w, "  │   %s %s\n", rIcon, r.Message)
+			if r.OpenSince != nil {
+				fmt.Fprintf(w.w, "  │       open since %s\n", formatOpenSince(*r.OpenSince, report.Timestamp))
+			}
 
 			for _, d := range r.Details {
 				fmt.Fprintf(w.w, "  │       %s\n", d)
 			}
 
-			if r.Remediation != "" && r.Severity != "OK" {
-				fmt.Fprintf(w.w, "  │       → %s\n", r.Remediation)
+			if isIssue(r.Severity) {
+				if r.Remediation != "" {
+					fmt.Fprintf(w.w, "  │       → %s\n", r.Remediation)
+				}
+				for _, cmd := range r.RemediationCommands {
+					fmt.Fprintf(w.w, "  │         $ %s\n", cmd)
+				}
+				if r.DocURL != "" {
+					fmt.Fprintf(w.w, "  │       see: %s\n", r.DocURL)
+				}
 			}
 		}
 	}
@@ -333,13 +505,69 @@ func (w *Writer) writeVerboseChecks(report *Report) {
 
 func severityIcon(s string) string {
 	switch s {
+	case "INFO":
+		return "ℹ"
 	case "OK":
 		return "✓"
 	case "WARNING":
 		return "⚠"
 	case "CRITICAL":
 		return "✗"
+	case SeverityError:
+		return "!"
 	default:
 		return "?"
 	}
 }
+
+func topNamespaces(results []probe.CheckResult) []NamespaceIssues {
+	counts := make(map[string]*NamespaceIssues)
+
+	for _, cr := range results {
+		for _, r := range cr.Results {
+			if !r.Severity.IsIssue() {
+				continue
+			}
+
+			ns := r.Namespace
+			if ns == "" {
+				continue
+			}
+
+			entry, ok := counts[ns]
+			if !ok {
+				entry = &NamespaceIssues{Namespace: ns}
+				counts[ns] = entry
+			}
+
+			switch r.Severity {
+			case probe.SeverityCritical:
+				entry.Critical++
+			case probe.SeverityWarning:
+				entry.Warning++
+			}
+			entry.Total++
+		}
+	}
+
+	ranked := make([]NamespaceIssues, 0, len(counts))
+	for _, entry := range counts {
+		ranked = append(ranked, *entry)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Total != ranked[j].Total {
+			return ranked[i].Total > ranked[j].Total
+		}
+		if ranked[i].Critical != ranked[j].Critical {
+			return ranked[i].Critical > ranked[j].Critical
+		}
+		return ranked[i].Namespace < ranked[j].Namespace
+	})
+
+	if len(ranked) > topNamespacesLimit {
+		ranked = ranked[:topNamespacesLimit]
+	}
+
+	return ranked
+}
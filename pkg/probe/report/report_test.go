@@ -2,13 +2,23 @@ package report
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"github.com/punasusi/cluster-probe/pkg/probe"
 	"github.com/punasusi/cluster-probe/pkg/probe/storage"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestNewWriter(t *testing.T) {
@@ -90,6 +100,83 @@ func TestWriteTextVerbose(t *testing.T) {
 	}
 }
 
+func TestWriteTextIncludeOK(t *testing.T) {
+	results := []probe.CheckResult{
+		{
+			Name: "test-check",
+			Tier: 1,
+			Results: []probe.Result{
+				{Severity: probe.SeverityOK, Message: "ok message"},
+				{Severity: probe.SeverityCritical, Message: "critical issue"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatText, false)
+	if err := w.Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "ok message") {
+		t.Error("default output should not include OK messages")
+	}
+
+	buf.Reset()
+	w = NewWriter(&buf, FormatText, false)
+	w.SetIncludeOK(true)
+	if err := w.Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "✓ [test-check] ok message") {
+		t.Errorf("output with include-ok should list OK messages, got:\n%s", output)
+	}
+	if !strings.Contains(output, "critical issue") {
+		t.Error("output with include-ok should still include critical issues")
+	}
+	if strings.Contains(output, "Critical Checks") {
+		t.Error("output with include-ok should keep the flat layout")
+	}
+}
+
+func TestWriteRedactClusterName(t *testing.T) {
+	results := []probe.CheckResult{
+		{
+			Name: "test-check",
+			Tier: 1,
+			Results: []probe.Result{
+				{Severity: probe.SeverityWarning, Message: "warning issue"},
+			},
+		},
+	}
+
+	redacted := RedactClusterName("prod-east-1")
+	if !strings.HasPrefix(redacted, "redacted-") || len(redacted) != len("redacted-")+8 {
+		t.Fatalf("unexpected redacted name %q", redacted)
+	}
+	if RedactClusterName("prod-east-1") != redacted {
+		t.Error("redaction should be stable for the same cluster name")
+	}
+
+	for _, format := range []Format{FormatText, FormatJSON} {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, format, false)
+		w.SetRedactCluster(true)
+		if err := w.Write(results, "prod-east-1"); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+
+		output := buf.String()
+		if strings.Contains(output, "prod-east-1") {
+			t.Errorf("%s output should not contain the cluster name, got:\n%s", format, output)
+		}
+		if !strings.Contains(output, redacted) {
+			t.Errorf("%s output should contain %q, got:\n%s", format, redacted, output)
+		}
+	}
+}
+
 func TestWriteJSON(t *testing.T) {
 	var buf bytes.Buffer
 	w := NewWriter(&buf, FormatJSON, false)
@@ -131,6 +218,446 @@ func TestWriteJSON(t *testing.T) {
 	}
 }
 
+func TestWriteRemediationCommands(t *testing.T) {
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{
+					Severity:            probe.SeverityCritical,
+					Message:             "pod crashing",
+					Remediation:         "Check logs from the previous container run",
+					RemediationCommands: []string{"kubectl logs -n default web -c app --previous"},
+				},
+			},
+		},
+	}
+
+	var text bytes.Buffer
+	if err := NewWriter(&text, FormatText, false).Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(text.String(), "→ Check logs from the previous container run") {
+		t.Error("text output missing remediation prose")
+	}
+	if !strings.Contains(text.String(), "$ kubectl logs -n default web -c app --previous") {
+		t.Error("text output missing remediation command")
+	}
+
+	var js bytes.Buffer
+	if err := NewWriter(&js, FormatJSON, false).Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(js.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	commands := report.CheckResults[0].Results[0].RemediationCommands
+	if len(commands) != 1 || commands[0] != "kubectl logs -n default web -c app --previous" {
+		t.Errorf("JSON should expose remediation commands, got %v", commands)
+	}
+}
+
+func TestWriteSummaryLine(t *testing.T) {
+	results := []probe.CheckResult{
+		{Name: "node-status", Tier: 1, Results: []probe.Result{{Severity: probe.SeverityCritical, Message: "node down"}}},
+		{Name: "pod-status", Tier: 2, Results: []probe.Result{{Severity: probe.SeverityWarning, Message: "pod pending"}}},
+		{Name: "pvc-status", Tier: 2, Results: []probe.Result{{Severity: probe.SeverityOK, Message: "all bound"}}},
+		{Name: "dns-resolution", Tier: 4, Results: []probe.Result{{Severity: probe.SeverityOK, Message: "healthy"}}},
+	}
+
+	var stderr bytes.Buffer
+	if err := WriteSummaryLine(&stderr, results); err != nil {
+		t.Fatalf("WriteSummaryLine failed: %v", err)
+	}
+
+	expected := `{"critical":1,"warning":1,"ok":2}` + "\n"
+	if stderr.String() != expected {
+		t.Errorf("expected %q, got %q", expected, stderr.String())
+	}
+}
+
+func TestWriteDocURL(t *testing.T) {
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{
+					Severity: probe.SeverityWarning,
+					Message:  "Pod app/web is in CrashLoopBackOff",
+					DocURL:   "https://wiki.example.com/runbooks/pod-status",
+				},
+			},
+		},
+	}
+
+	var jsonBuf bytes.Buffer
+	if err := NewWriter(&jsonBuf, FormatJSON, false).Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(jsonBuf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if got := report.CheckResults[0].Results[0].DocURL; got != "https://wiki.example.com/runbooks/pod-status" {
+		t.Errorf("expected doc_url in JSON, got %q", got)
+	}
+
+	var textBuf bytes.Buffer
+	if err := NewWriter(&textBuf, FormatText, true).Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(textBuf.String(), "see: https://wiki.example.com/runbooks/pod-status") {
+		t.Errorf("verbose text should include the doc link, got:\n%s", textBuf.String())
+	}
+}
+
+func TestWriteJSONGroupByNamespace(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatJSON, false)
+	if err := w.SetGroupBy(GroupByNamespace); err != nil {
+		t.Fatalf("SetGroupBy failed: %v", err)
+	}
+
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{Severity: probe.SeverityCritical, Message: "Pod app/web is in CrashLoopBackOff", Namespace: "app"},
+				{Severity: probe.SeverityWarning, Message: "Pod is restarting", Namespace: "billing"},
+				{Severity: probe.SeverityOK, Message: "Pods checked: 12"},
+			},
+		},
+		{
+			Name: "node-status",
+			Tier: 1,
+			Results: []probe.Result{
+				{Severity: probe.SeverityOK, Message: "All 3 nodes are Ready"},
+			},
+		},
+	}
+
+	if err := w.Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if _, ok := raw["checks"]; ok {
+		t.Error("grouped report should not include the check-keyed layout")
+	}
+
+	var grouped GroupedReport
+	if err := json.Unmarshal(buf.Bytes(), &grouped); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if len(grouped.Namespaces) != 2 {
+		t.Fatalf("expected namespaces app and billing, got %v", grouped.Namespaces)
+	}
+
+	app := grouped.Namespaces["app"]
+	if app == nil || len(app.Checks) != 1 || app.Checks[0].Name != "pod-status" || app.Checks[0].Severity != "CRITICAL" {
+		t.Errorf("unexpected app group: %+v", app)
+	}
+	billing := grouped.Namespaces["billing"]
+	if billing == nil || len(billing.Checks) != 1 || billing.Checks[0].Severity != "WARNING" || billing.Checks[0].Results[0].Namespace != "billing" {
+		t.Errorf("unexpected billing group: %+v", billing)
+	}
+
+	if grouped.ClusterScoped == nil || len(grouped.ClusterScoped.Checks) != 2 {
+		t.Fatalf("expected node-status and the pod-status summary under cluster_scoped, got %+v", grouped.ClusterScoped)
+	}
+	if grouped.ClusterScoped.Checks[0].Name != "node-status" {
+		t.Errorf("expected cluster-scoped checks sorted by tier, got %s first", grouped.ClusterScoped.Checks[0].Name)
+	}
+	if grouped.Summary.Critical != 1 || grouped.Summary.Total != 2 {
+		t.Errorf("unexpected summary: %+v", grouped.Summary)
+	}
+}
+
+func TestWriteJSONSortsResultsBySeverity(t *testing.T) {
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{CheckName: "pod-status", Severity: probe.SeverityOK, Message: "12 pods running"},
+				{CheckName: "pod-status", Severity: probe.SeverityWarning, Message: "Pod app/web is restarting"},
+				{CheckName: "pod-status", Severity: probe.SeverityCritical, Message: "Pod app/db is crashing"},
+			},
+		},
+	}
+
+	messages := func(sortBy string) []string {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, FormatJSON, false)
+		if err := w.SetSortBy(sortBy); err != nil {
+			t.Fatalf("SetSortBy failed: %v", err)
+		}
+		if err := w.Write(results, "test-cluster"); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		var report Report
+		if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+			t.Fatalf("failed to parse JSON: %v", err)
+		}
+		var out []string
+		for _, r := range report.CheckResults[0].Results {
+			out = append(out, r.Message)
+		}
+		return out
+	}
+
+	got := messages(SortSeverity)
+	want := []string{"Pod app/db is crashing", "Pod app/web is restarting", "12 pods running"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected critical before OK, got %v", got)
+	}
+
+	got = messages(SortInsertion)
+	want = []string{"12 pods running", "Pod app/web is restarting", "Pod app/db is crashing"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected insertion order, got %v", got)
+	}
+	if results[0].Results[0].Severity != probe.SeverityOK {
+		t.Error("sorting should not reorder the caller's results")
+	}
+
+	if err := NewWriter(&bytes.Buffer{}, FormatJSON, false).SetSortBy("name"); err == nil {
+		t.Error("expected an error for an unsupported sort")
+	}
+}
+
+func TestSetGroupByUnsupported(t *testing.T) {
+	w := NewWriter(&bytes.Buffer{}, FormatJSON, false)
+	if err := w.SetGroupBy("tier"); err == nil {
+		t.Error("expected an error for an unsupported grouping")
+	}
+}
+
+func TestWriteReportStyles(t *testing.T) {
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{Severity: probe.SeverityCritical, Message: "Pod app/web is in CrashLoopBackOff", Namespace: "app"},
+				{Severity: probe.SeverityWarning, Message: "Pod batch/job-1 is pending", Namespace: "batch"},
+				{Severity: probe.SeverityOK, Message: "Pods: 12 running"},
+			},
+		},
+		{
+			Name: "node-status",
+			Tier: 1,
+			Results: []probe.Result{
+				{Severity: probe.SeverityWarning, Message: "Node worker-1 has disk pressure", Details: []string{"Condition: DiskPressure"}},
+			},
+		},
+	}
+
+	write := func(style string) string {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, FormatText, false)
+		if err := w.SetStyle(style); err != nil {
+			t.Fatalf("SetStyle(%q) failed: %v", style, err)
+		}
+		if err := w.Write(results, "test-cluster"); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	compact := write(StyleCompact)
+	if !strings.Contains(compact, "✗ [pod-status] Pod app/web is in CrashLoopBackOff") {
+		t.Errorf("compact output should list critical issues, got:\n%s", compact)
+	}
+	for _, unwanted := range []string{"is pending", "Pods: 12 running", "Critical Checks", "Issues by Namespace"} {
+		if strings.Contains(compact, unwanted) {
+			t.Errorf("compact output should not contain %q, got:\n%s", unwanted, compact)
+		}
+	}
+
+	detailed := write(StyleDetailed)
+	for _, want := range []string{"┌─ Critical Checks", "┌─ Workload Checks", "Condition: DiskPressure", "✓ Pods: 12 running"} {
+		if !strings.Contains(detailed, want) {
+			t.Errorf("detailed output should contain %q, got:\n%s", want, detailed)
+		}
+	}
+
+	ci := write(StyleCI)
+	grouped := "  Issues by Namespace:\n" +
+		"  app:\n    ✗ [pod-status] Pod app/web is in CrashLoopBackOff\n" +
+		"  batch:\n    ⚠ [pod-status] Pod batch/job-1 is pending\n" +
+		"  (cluster-scoped):\n    ⚠ [node-status] Node worker-1 has disk pressure\n"
+	if !strings.Contains(ci, grouped) {
+		t.Errorf("ci output should group issues by namespace, got:\n%s", ci)
+	}
+	if strings.Contains(ci, "Critical Issues:") || strings.Contains(ci, "Pods: 12 running") {
+		t.Errorf("ci output should replace the critical-only list, got:\n%s", ci)
+	}
+
+	if err := NewWriter(&bytes.Buffer{}, FormatText, false).SetStyle("fancy"); err == nil {
+		t.Error("expected an error for an unknown report style")
+	}
+}
+
+func TestWriteFixScript(t *testing.T) {
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{Severity: probe.SeverityOK, Message: "Pods: 12 running", RemediationCommands: []string{"kubectl get pods -A"}},
+				{
+					Severity:            probe.SeverityCritical,
+					Message:             "Pod app/web is in CrashLoopBackOff",
+					Remediation:         "Check the container logs",
+					RemediationCommands: []string{"kubectl logs -n app web --previous", "kubectl describe pod -n app web"},
+				},
+			},
+		},
+		{
+			Name: "node-status",
+			Tier: 1,
+			Results: []probe.Result{
+				{Severity: probe.SeverityWarning, Message: "Node worker-1 has disk pressure"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewWriter(&buf, FormatText, false).WriteFixScript(results, "test-cluster"); err != nil {
+		t.Fatalf("WriteFixScript failed: %v", err)
+	}
+	script := buf.String()
+
+	if !strings.HasPrefix(script, "#!/bin/sh\n") {
+		t.Errorf("script should start with a shebang, got:\n%s", script)
+	}
+	want := "# ==== pod-status ====\n\n" +
+		"# [CRITICAL] Pod app/web is in CrashLoopBackOff\n" +
+		"#   Check the container logs\n" +
+		"# kubectl logs -n app web --previous\n" +
+		"# kubectl describe pod -n app web\n"
+	if !strings.Contains(script, want) {
+		t.Errorf("script should list the commented-out commands under their finding, got:\n%s", script)
+	}
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(line, "kubectl") {
+			t.Errorf("commands must be commented out, found %q", line)
+		}
+	}
+	if strings.Contains(script, "kubectl get pods -A") {
+		t.Error("OK results should not contribute commands")
+	}
+	if strings.Contains(script, "node-status") {
+		t.Error("checks without remediation commands should be omitted")
+	}
+}
+
+func TestWriteOpenSince(t *testing.T) {
+	opened := time.Now().UTC().Add(-72 * time.Hour)
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{Severity: probe.SeverityCritical, Message: "Pod app/web is in CrashLoopBackOff", OpenSince: opened},
+				{Severity: probe.SeverityCritical, Message: "Pod app/api is in CrashLoopBackOff"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewWriter(&buf, FormatText, false).Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := "  ✗ [pod-status] Pod app/web is in CrashLoopBackOff\n    open since " + opened.Format("2006-01-02 15:04 UTC") + " (3d)\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("text output should annotate the issue's age, got:\n%s", buf.String())
+	}
+	if strings.Count(buf.String(), "open since") != 1 {
+		t.Errorf("issues without history should not be annotated, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := NewWriter(&buf, FormatJSON, false).Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	check := report.CheckResults[0]
+	if check.OpenSince == nil || !check.OpenSince.Equal(opened) {
+		t.Errorf("check should carry its oldest open issue, got %v", check.OpenSince)
+	}
+	for _, r := range check.Results {
+		hasAge := r.OpenSince != nil
+		if hasAge != (r.Message == "Pod app/web is in CrashLoopBackOff") {
+			t.Errorf("%s: unexpected open_since %v", r.Message, r.OpenSince)
+		}
+	}
+}
+
+func TestWriteJSONFormatVersion1(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatJSON, false)
+	if err := w.SetFormatVersion(FormatVersion1); err != nil {
+		t.Fatalf("SetFormatVersion failed: %v", err)
+	}
+
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{
+					Severity:            probe.SeverityWarning,
+					Message:             "Pod app/web is in CrashLoopBackOff",
+					Remediation:         "Check logs from the previous container run",
+					RemediationCommands: []string{"kubectl logs -n app web --previous"},
+				},
+			},
+		},
+	}
+
+	if err := w.Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	output := buf.String()
+	for _, field := range []string{"format_version", "top_namespaces", "remediation_commands", "doc_url"} {
+		if strings.Contains(output, field) {
+			t.Errorf("version 1 output should not contain %q", field)
+		}
+	}
+
+	var report Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	expected := "Check logs from the previous container run: kubectl logs -n app web --previous"
+	if got := report.CheckResults[0].Results[0].Remediation; got != expected {
+		t.Errorf("expected legacy remediation %q, got %q", expected, got)
+	}
+}
+
+func TestSetFormatVersionUnsupported(t *testing.T) {
+	w := NewWriter(&bytes.Buffer{}, FormatJSON, false)
+	if err := w.SetFormatVersion(99); err == nil {
+		t.Error("expected error for unsupported format version")
+	}
+	if w.formatVersion != CurrentFormatVersion {
+		t.Errorf("format version should stay at %d, got %d", CurrentFormatVersion, w.formatVersion)
+	}
+}
+
 func TestWriteWithDiff(t *testing.T) {
 	var buf bytes.Buffer
 	w := NewWriter(&buf, FormatText, false)
@@ -268,6 +795,123 @@ func TestSummaryCalculation(t *testing.T) {
 	}
 }
 
+func TestWriteErroredCheck(t *testing.T) {
+	results := []probe.CheckResult{
+		{Name: "node-status", Tier: 1, Results: []probe.Result{{Severity: probe.SeverityOK, Message: "All 3 nodes are Ready"}}},
+		{Name: "rbac-audit", Tier: 5, Results: []probe.Result{}, Error: "failed to list clusterroles: forbidden"},
+	}
+
+	var text bytes.Buffer
+	if err := NewWriter(&text, FormatText, false).Write(results, "test"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	output := text.String()
+	if !strings.Contains(output, "Errored Checks (failed to run):\n  ! [rbac-audit] failed to list clusterroles: forbidden") {
+		t.Errorf("missing errored checks section:\n%s", output)
+	}
+	if strings.Contains(output, "Critical Issues:") {
+		t.Errorf("an errored check is not a finding:\n%s", output)
+	}
+	if !strings.Contains(output, "Summary: ! 1 errored  ✓ 1 passed") {
+		t.Errorf("summary should count the errored check separately:\n%s", output)
+	}
+
+	var buf bytes.Buffer
+	if err := NewWriter(&buf, FormatJSON, false).Write(results, "test"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if report.Summary.Errored != 1 || report.Summary.Critical != 0 || report.Summary.Total != 2 {
+		t.Errorf("unexpected summary: %+v", report.Summary)
+	}
+	errored := report.CheckResults[1]
+	if errored.Severity != SeverityError || errored.Error != "failed to list clusterroles: forbidden" || len(errored.Results) != 0 {
+		t.Errorf("unexpected errored check output: %+v", errored)
+	}
+
+	buf.Reset()
+	w := NewWriter(&buf, FormatJSON, false)
+	if err := w.SetFormatVersion(FormatVersion1); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(results, "test"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var v1 Report
+	if err := json.Unmarshal(buf.Bytes(), &v1); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if v1.Summary.Critical != 1 || v1.CheckResults[1].Severity != "CRITICAL" || v1.CheckResults[1].Results[0].Message != "Check failed to execute" {
+		t.Errorf("version 1 should keep reporting errored checks as critical findings, got %+v", v1)
+	}
+}
+
+func TestWriteInfoResults(t *testing.T) {
+	results := []probe.CheckResult{
+		{Name: "storage-health", Tier: 3, Results: []probe.Result{
+			{Severity: probe.SeverityInfo, Message: "2 CSI drivers installed"},
+			{Severity: probe.SeverityOK, Message: "Storage classes: 3"},
+		}},
+		{Name: "ingress-status", Tier: 3, Results: []probe.Result{
+			{Severity: probe.SeverityInfo, Message: "Ingress classes: nginx"},
+		}},
+	}
+
+	var text bytes.Buffer
+	if err := NewWriter(&text, FormatText, false).Write(results, "test"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	output := text.String()
+	if strings.Contains(output, "CSI drivers") {
+		t.Errorf("info results should be hidden like OK results in the default report:\n%s", output)
+	}
+	if !strings.Contains(output, "Summary: ✓ 1 passed  ℹ 1 info") {
+		t.Errorf("summary should count info-only checks separately:\n%s", output)
+	}
+
+	var verbose bytes.Buffer
+	if err := NewWriter(&verbose, FormatText, true).Write(results, "test"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(verbose.String(), "│   ℹ 2 CSI drivers installed") || !strings.Contains(verbose.String(), "│ ℹ ingress-status") {
+		t.Errorf("verbose output should render info results with the info icon:\n%s", verbose.String())
+	}
+
+	var buf bytes.Buffer
+	if err := NewWriter(&buf, FormatJSON, false).Write(results, "test"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if report.Summary.Info != 1 || report.Summary.OK != 1 || report.Summary.Total != 2 {
+		t.Errorf("unexpected summary: %+v", report.Summary)
+	}
+
+	buf.Reset()
+	w := NewWriter(&buf, FormatJSON, false)
+	if err := w.SetFormatVersion(FormatVersion1); err != nil {
+		t.Fatalf("SetFormatVersion failed: %v", err)
+	}
+	if err := w.Write(results, "test"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "INFO") {
+		t.Errorf("version 1 predates INFO and should not emit it:\n%s", buf.String())
+	}
+	var v1 reportV1
+	if err := json.Unmarshal(buf.Bytes(), &v1); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if v1.Summary.OK != 2 || v1.Summary.Info != 0 || v1.CheckResults[1].Severity != "OK" || v1.CheckResults[0].Results[0].Severity != "OK" {
+		t.Errorf("version 1 should fold info results into OK, got %+v", v1)
+	}
+}
+
 func TestSeverityIcon(t *testing.T) {
 	tests := []struct {
 		severity string
@@ -276,6 +920,8 @@ func TestSeverityIcon(t *testing.T) {
 		{"OK", "✓"},
 		{"WARNING", "⚠"},
 		{"CRITICAL", "✗"},
+		{"ERROR", "!"},
+		{"INFO", "ℹ"},
 		{"UNKNOWN", "?"},
 	}
 
@@ -286,3 +932,300 @@ func TestSeverityIcon(t *testing.T) {
 		}
 	}
 }
+
+func TestTopNamespacesRanking(t *testing.T) {
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{Severity: probe.SeverityWarning, Message: "Pod shop/web-1 is in CrashLoopBackOff", Namespace: "shop"},
+				{Severity: probe.SeverityWarning, Message: "Pod shop/web-2 cannot pull image", Namespace: "shop"},
+				{Severity: probe.SeverityCritical, Message: "Pod billing/api-0 is unschedulable", Namespace: "billing"},
+				{Severity: probe.SeverityOK, Message: "Pod status: 3 running, 0 pending, 0 failed, 0 succeeded"},
+			},
+		},
+		{
+			Name: "network-policies",
+			Tier: 4,
+			Results: []probe.Result{
+				{Severity: probe.SeverityWarning, Message: "2 pods in namespace billing are cut off from all ingress traffic", Namespace: "billing"},
+				{Severity: probe.SeverityWarning, Message: "Job batch-jobs/nightly has failed", Namespace: "batch-jobs"},
+				{Severity: probe.SeverityWarning, Message: "Node worker-1 has MemoryPressure"},
+				{Severity: probe.SeverityWarning, Message: "PersistentVolume pv-1 uses the in-tree kubernetes.io/aws-ebs plugin"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatJSON, false)
+	if err := w.Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+
+	expected := []NamespaceIssues{
+		{Namespace: "billing", Critical: 1, Warning: 1, Total: 2},
+		{Namespace: "shop", Critical: 0, Warning: 2, Total: 2},
+		{Namespace: "batch-jobs", Critical: 0, Warning: 1, Total: 1},
+	}
+	if len(report.TopNamespaces) != len(expected) {
+		t.Fatalf("expected %d namespaces, got %d: %+v", len(expected), len(report.TopNamespaces), report.TopNamespaces)
+	}
+	for i, want := range expected {
+		if report.TopNamespaces[i] != want {
+			t.Errorf("rank %d: expected %+v, got %+v", i+1, want, report.TopNamespaces[i])
+		}
+	}
+
+	buf.Reset()
+	w = NewWriter(&buf, FormatText, false)
+	if err := w.Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Top Namespaces by Issues") {
+		t.Error("text report should include the top namespaces section")
+	}
+}
+
+func TestWriteCustomTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("custom", `{{.Cluster}}:{{range .CheckResults}} {{severityIcon .Severity}}{{.Name}}{{end}} ({{.Summary.Critical}} critical)`)
+	if err != nil {
+		t.Fatalf("ParseTemplate failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatText, false)
+	w.SetTemplate(tmpl)
+
+	results := []probe.CheckResult{
+		{
+			Name:    "node-status",
+			Tier:    1,
+			Results: []probe.Result{{Severity: probe.SeverityCritical, Message: "node down"}},
+		},
+		{
+			Name:    "pod-status",
+			Tier:    2,
+			Results: []probe.Result{{Severity: probe.SeverityOK, Message: "all good"}},
+		},
+	}
+
+	if err := w.Write(results, "test-cluster"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	expected := "test-cluster: ✗node-status ✓pod-status (1 critical)"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestLoadTemplateMissingFile(t *testing.T) {
+	if _, err := LoadTemplate("/nonexistent/report.tmpl"); err == nil {
+		t.Error("expected error for missing template file")
+	}
+}
+
+func TestParseConfigMapRef(t *testing.T) {
+	namespace, name, err := ParseConfigMapRef("monitoring/cluster-probe-report")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if namespace != "monitoring" || name != "cluster-probe-report" {
+		t.Errorf("unexpected ref: %s/%s", namespace, name)
+	}
+
+	for _, ref := range []string{"", "report", "/report", "monitoring/", "a/b/c"} {
+		if _, _, err := ParseConfigMapRef(ref); err == nil {
+			t.Errorf("expected error for %q", ref)
+		}
+	}
+}
+
+func TestWriteConfigMap(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	w := NewWriter(&bytes.Buffer{}, FormatText, false)
+
+	results := []probe.CheckResult{
+		{
+			Name: "node-status",
+			Tier: 1,
+			Results: []probe.Result{
+				{Severity: probe.SeverityWarning, Message: "node pressure"},
+				{Severity: probe.SeverityOK, Message: "3 nodes ready"},
+			},
+		},
+	}
+
+	if err := w.WriteConfigMap(context.Background(), client, "monitoring", "probe-report", results, "test-cluster"); err != nil {
+		t.Fatalf("WriteConfigMap failed: %v", err)
+	}
+
+	cm, err := client.CoreV1().ConfigMaps("monitoring").Get(context.Background(), "probe-report", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get ConfigMap: %v", err)
+	}
+	if _, ok := cm.Data[ConfigMapTruncatedKey]; ok {
+		t.Error("small report should not be truncated")
+	}
+
+	var report Report
+	if err := json.Unmarshal([]byte(cm.Data[ConfigMapReportKey]), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	if report.Cluster != "test-cluster" || report.Summary.Warning != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if len(report.CheckResults) != 1 || len(report.CheckResults[0].Results) != 2 {
+		t.Errorf("expected OK results to be kept in the ConfigMap report, got %+v", report.CheckResults)
+	}
+
+	var summary Summary
+	if err := json.Unmarshal([]byte(cm.Data[ConfigMapSummaryKey]), &summary); err != nil {
+		t.Fatalf("invalid summary JSON: %v", err)
+	}
+	if summary.Warning != 1 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestWriteConfigMapUpdatesExisting(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "probe-report", Namespace: "monitoring"},
+		Data:       map[string]string{"stale": "data"},
+	})
+	w := NewWriter(&bytes.Buffer{}, FormatJSON, false)
+
+	results := []probe.CheckResult{
+		{Name: "pod-status", Tier: 2, Results: []probe.Result{{Severity: probe.SeverityOK, Message: "all good"}}},
+	}
+	if err := w.WriteConfigMap(context.Background(), client, "monitoring", "probe-report", results, "test-cluster"); err != nil {
+		t.Fatalf("WriteConfigMap failed: %v", err)
+	}
+
+	cm, _ := client.CoreV1().ConfigMaps("monitoring").Get(context.Background(), "probe-report", metav1.GetOptions{})
+	if _, ok := cm.Data["stale"]; ok {
+		t.Error("stale data should have been replaced")
+	}
+	if !strings.Contains(cm.Data[ConfigMapReportKey], "all good") {
+		t.Errorf("expected report in ConfigMap, got %v", cm.Data)
+	}
+}
+
+func TestWriteConfigMapTruncatesLargeReport(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	w := NewWriter(&bytes.Buffer{}, FormatJSON, false)
+
+	filler := strings.Repeat("x", 1024)
+	okResults := make([]probe.Result, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		okResults = append(okResults, probe.Result{Severity: probe.SeverityOK, Message: filler})
+	}
+	results := []probe.CheckResult{
+		{Name: "noisy", Tier: 3, Results: okResults},
+		{Name: "pod-status", Tier: 2, Results: []probe.Result{{Severity: probe.SeverityCritical, Message: "crashloop"}}},
+	}
+
+	if err := w.WriteConfigMap(context.Background(), client, "monitoring", "probe-report", results, "test-cluster"); err != nil {
+		t.Fatalf("WriteConfigMap failed: %v", err)
+	}
+
+	cm, _ := client.CoreV1().ConfigMaps("monitoring").Get(context.Background(), "probe-report", metav1.GetOptions{})
+	if cm.Data[ConfigMapTruncatedKey] == "" {
+		t.Error("expected truncation marker for oversized report")
+	}
+	report := cm.Data[ConfigMapReportKey]
+	if len(report) > configMapMaxBytes {
+		t.Errorf("report exceeds ConfigMap budget: %d bytes", len(report))
+	}
+	if !strings.Contains(report, "crashloop") || strings.Contains(report, filler) {
+		t.Errorf("expected only non-OK results in truncated report")
+	}
+}
+
+func TestPushMetrics(t *testing.T) {
+	var method, path string
+	families := map[string]*dto.MetricFamily{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		decoder := expfmt.NewDecoder(r.Body, expfmt.ResponseFormat(r.Header))
+		for {
+			mf := &dto.MetricFamily{}
+			if err := decoder.Decode(mf); err != nil {
+				if !errors.Is(err, io.EOF) {
+					t.Errorf("failed to decode pushed metrics: %v", err)
+				}
+				break
+			}
+			families[mf.GetName()] = mf
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	results := []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{Severity: probe.SeverityWarning, Message: "Pod app/web is restarting"},
+				{Severity: probe.SeverityOK, Message: "10 pods running"},
+			},
+		},
+	}
+
+	if err := PushMetrics(server.URL, "nightly-probe", results); err != nil {
+		t.Fatalf("PushMetrics failed: %v", err)
+	}
+
+	if method != http.MethodPut {
+		t.Errorf("expected PUT, got %s", method)
+	}
+	if path != "/metrics/job/nightly-probe" {
+		t.Errorf("unexpected push path: %s", path)
+	}
+
+	severity, ok := families["cluster_probe_check_severity"]
+	if !ok {
+		t.Fatalf("cluster_probe_check_severity not pushed, got %v", families)
+	}
+	if got := severity.GetMetric()[0].GetGauge().GetValue(); got != float64(probe.SeverityWarning) {
+		t.Errorf("expected severity %d, got %v", probe.SeverityWarning, got)
+	}
+
+	counts := map[string]float64{}
+	for _, m := range families["cluster_probe_check_results"].GetMetric() {
+		for _, label := range m.GetLabel() {
+			if label.GetName() == "severity" {
+				counts[label.GetValue()] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	if counts["WARNING"] != 1 || counts["OK"] != 1 || counts["CRITICAL"] != 0 {
+		t.Errorf("unexpected result counts: %v", counts)
+	}
+
+	if _, ok := families["cluster_probe_last_run_timestamp_seconds"]; !ok {
+		t.Error("cluster_probe_last_run_timestamp_seconds not pushed")
+	}
+}
+
+func TestPushMetricsServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := PushMetrics(server.URL, "", []probe.CheckResult{})
+	if err == nil {
+		t.Fatal("expected an error when the Pushgateway rejects the push")
+	}
+	if !strings.Contains(err.Error(), server.URL) {
+		t.Errorf("expected error to mention the Pushgateway URL, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+)
+
+const (
+	SortSeverity  = "severity"
+	SortInsertion = "insertion"
+)
+
+func (w *Writer) SetSortBy(sortBy string) error {
+	switch sortBy {
+	case "", SortSeverity:
+		w.sortBy = SortSeverity
+	case SortInsertion:
+		w.sortBy = SortInsertion
+	default:
+		return fmt.Errorf("unsupported sort %q (supported: %s, %s)", sortBy, SortSeverity, SortInsertion)
+	}
+	return nil
+}
+
+func (w *Writer) orderResults(results []probe.Result) []probe.Result {
+	if w.sortBy == SortInsertion {
+		return results
+	}
+	ordered := append([]probe.Result(nil), results...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Severity > ordered[j].Severity
+	})
+	return ordered
+}
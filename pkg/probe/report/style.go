@@ -0,0 +1,50 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	StyleCompact  = "compact"
+	StyleDetailed = "detailed"
+	StyleCI       = "ci"
+)
+
+type Style struct {
+	Verbose   bool
+	IncludeOK bool
+	GroupBy   string
+}
+
+var styles = map[string]Style{
+	StyleCompact:  {},
+	StyleDetailed: {Verbose: true, IncludeOK: true},
+	StyleCI:       {GroupBy: GroupByNamespace},
+}
+
+func StyleNames() []string {
+	names := make([]string, 0, len(styles))
+	for name := range styles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (w *Writer) SetStyle(name string) error {
+	if name == "" {
+		return nil
+	}
+	style, ok := styles[name]
+	if !ok {
+		return fmt.Errorf("unsupported report style %q (supported: %s)", name, strings.Join(StyleNames(), ", "))
+	}
+	w.verbose = w.verbose || style.Verbose
+	w.includeOK = w.includeOK || style.IncludeOK
+	if style.GroupBy != "" {
+		return w.SetGroupBy(style.GroupBy)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+)
+
+type summaryLine struct {
+	Critical int `json:"critical"`
+	Warning  int `json:"warning"`
+	OK       int `json:"ok"`
+	Info     int `json:"info,omitempty"`
+	Errored  int `json:"errored,omitempty"`
+}
+
+func Summarize(results []probe.CheckResult) Summary {
+	var summary Summary
+	for _, cr := range results {
+		summary.Total++
+		if cr.Errored() {
+			summary.Errored++
+			continue
+		}
+		switch cr.MaxSeverity() {
+		case probe.SeverityCritical:
+			summary.Critical++
+		case probe.SeverityWarning:
+			summary.Warning++
+		case probe.SeverityOK:
+			summary.OK++
+		case probe.SeverityInfo:
+			summary.Info++
+		}
+	}
+	return summary
+}
+
+func WriteSummaryLine(w io.Writer, results []probe.CheckResult) error {
+	summary := Summarize(results)
+	data, err := json.Marshal(summaryLine{
+		Critical: summary.Critical,
+		Warning:  summary.Warning,
+		OK:       summary.OK,
+		Info:     summary.Info,
+		Errored:  summary.Errored,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,35 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"severityIcon": severityIcon,
+		"join":         strings.Join,
+		"repeat":       strings.Repeat,
+		"upper":        strings.ToUpper,
+		"lower":        strings.ToLower,
+	}
+}
+
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func LoadTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	return ParseTemplate(filepath.Base(path), string(data))
+}
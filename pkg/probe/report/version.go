@@ -0,0 +1,104 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	FormatVersion1 = 1
+	FormatVersion2 = 2
+
+	CurrentFormatVersion = FormatVersion2
+)
+
+type reportV1 struct {
+	Timestamp    time.Time       `json:"timestamp"`
+	Cluster      string          `json:"cluster"`
+	Summary      Summary         `json:"summary"`
+	CheckResults []checkOutputV1 `json:"checks"`
+	Diff         *DiffOutput     `json:"diff,omitempty"`
+}
+
+type checkOutputV1 struct {
+	Name     string           `json:"name"`
+	Tier     int              `json:"tier"`
+	Severity string           `json:"severity"`
+	Results  []resultOutputV1 `json:"results"`
+}
+
+type resultOutputV1 struct {
+	Severity    string   `json:"severity"`
+	Message     string   `json:"message"`
+	Details     []string `json:"details,omitempty"`
+	Remediation string   `json:"remediation,omitempty"`
+}
+
+func validateFormatVersion(version int) error {
+	if version < FormatVersion1 || version > CurrentFormatVersion {
+		return fmt.Errorf("unsupported format version %d (supported: %d-%d)", version, FormatVersion1, CurrentFormatVersion)
+	}
+	return nil
+}
+
+func toReportV1(report *Report) *reportV1 {
+	summary := report.Summary
+	summary.Critical += summary.Errored
+	summary.Errored = 0
+	summary.OK += summary.Info
+	summary.Info = 0
+
+	v1 := &reportV1{
+		Timestamp:    report.Timestamp,
+		Cluster:      report.Cluster,
+		Summary:      summary,
+		CheckResults: make([]checkOutputV1, 0, len(report.CheckResults)),
+		Diff:         report.Diff,
+	}
+
+	for _, check := range report.CheckResults {
+		out := checkOutputV1{
+			Name:     check.Name,
+			Tier:     check.Tier,
+			Severity: legacySeverity(check.Severity),
+			Results:  make([]resultOutputV1, 0, len(check.Results)),
+		}
+		for _, r := range check.Results {
+			out.Results = append(out.Results, resultOutputV1{
+				Severity:    legacySeverity(r.Severity),
+				Message:     r.Message,
+				Details:     r.Details,
+				Remediation: legacyRemediation(r.Remediation, r.RemediationCommands),
+			})
+		}
+		if check.Error != "" {
+			out.Severity = "CRITICAL"
+			out.Results = append(out.Results, resultOutputV1{
+				Severity: "CRITICAL",
+				Message:  "Check failed to execute",
+				Details:  []string{check.Error},
+			})
+		}
+		v1.CheckResults = append(v1.CheckResults, out)
+	}
+
+	return v1
+}
+
+func legacySeverity(severity string) string {
+	if severity == "INFO" {
+		return "OK"
+	}
+	return severity
+}
+
+func legacyRemediation(remediation string, commands []string) string {
+	if len(commands) == 0 {
+		return remediation
+	}
+	if remediation == "" {
+		return strings.Join(commands, "; ")
+	}
+	return remediation + ": " + strings.Join(commands, "; ")
+}
@@ -1,15 +1,20 @@
 package probe
 
+import "time"
+
 type Severity int
 
 const (
-	SeverityOK	Severity	= iota
+	SeverityInfo	Severity	= iota - 1
+	SeverityOK
 	SeverityWarning
 	SeverityCritical
 )
 
 func (s Severity) String() string {
 	switch s {
+	case SeverityInfo:
+		return "INFO"
 	case SeverityOK:
 		return "OK"
 	case SeverityWarning:
@@ -21,22 +26,41 @@ func (s Severity) String() string {
 	}
 }
 
+func (s Severity) IsIssue() bool {
+	return s > SeverityOK
+}
+
 type Result struct {
-	CheckName	string
-	Severity	Severity
-	Message		string
-	Details		[]string
-	Remediation	string
+	CheckName		string
+	Severity		Severity
+	Message			string
+	Namespace		string
+	Details			[]string
+	Remediation		string
+	RemediationCommands	[]string
+	DocURL			string
+	OpenSince		time.Time
 }
 
 type CheckResult struct {
 	Name	string
 	Tier	int
 	Results	[]Result
+	Error	string
+}
+
+func (cr *CheckResult) Errored() bool {
+	return cr.Error != ""
 }
 
 func (cr *CheckResult) MaxSeverity() Severity {
-	max := SeverityOK
+	if cr.Errored() {
+		return SeverityCritical
+	}
+	if len(cr.Results) == 0 {
+		return SeverityOK
+	}
+	max := SeverityInfo
 	for _, r := range cr.Results {
 		if r.Severity > max {
 			max = r.Severity
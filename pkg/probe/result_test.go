@@ -7,6 +7,7 @@ func TestSeverityString(t *testing.T) {
 		severity Severity
 		expected string
 	}{
+		{SeverityInfo, "INFO"},
 		{SeverityOK, "OK"},
 		{SeverityWarning, "WARNING"},
 		{SeverityCritical, "CRITICAL"},
@@ -22,6 +23,15 @@ func TestSeverityString(t *testing.T) {
 }
 
 func TestSeverityOrdering(t *testing.T) {
+	if SeverityInfo >= SeverityOK {
+		t.Error("SeverityInfo should be less than SeverityOK")
+	}
+	if SeverityOK != 0 {
+		t.Error("SeverityOK should remain the zero value")
+	}
+	if SeverityInfo.IsIssue() || SeverityOK.IsIssue() || !SeverityWarning.IsIssue() || !SeverityCritical.IsIssue() {
+		t.Error("only warnings and criticals should count as issues")
+	}
 	if SeverityOK >= SeverityWarning {
 		t.Error("SeverityOK should be less than SeverityWarning")
 	}
@@ -67,6 +77,21 @@ func TestCheckResultMaxSeverity(t *testing.T) {
 			},
 			expected: SeverityCritical,
 		},
+		{
+			name: "only info",
+			results: []Result{
+				{Severity: SeverityInfo},
+			},
+			expected: SeverityInfo,
+		},
+		{
+			name: "info and OK",
+			results: []Result{
+				{Severity: SeverityInfo},
+				{Severity: SeverityOK},
+			},
+			expected: SeverityOK,
+		},
 		{
 			name: "critical first",
 			results: []Result{
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"time"
 )
 
 const (
 	ProbeDir	= ".probe"
 	LastScanFile	= "last-scan.json"
+	HistoryFile	= "history.json"
 	ConfigFile	= "config.yaml"
 )
 
@@ -31,8 +33,11 @@ type ScanSummary struct {
 type StoredIssue struct {
 	CheckName	string	`json:"check"`
 	Severity	string	`json:"severity"`
+	Namespace	string	`json:"namespace,omitempty"`
 	Message		string	`json:"message"`
 	Fingerprint	string	`json:"fingerprint"`	// Unique identifier for comparison
+	Occurrences	int	`json:"occurrences,omitempty"`
+	FirstSeen	time.Time	`json:"first_seen,omitempty"`
 }
 
 type ScanDiff struct {
@@ -73,6 +78,10 @@ func (s *Storage) LastScanPath() string {
 	return filepath.Join(s.ProbeDirPath(), LastScanFile)
 }
 
+func (s *Storage) HistoryPath() string {
+	return filepath.Join(s.ProbeDirPath(), HistoryFile)
+}
+
 func (s *Storage) ConfigPath() string {
 	return filepath.Join(s.ProbeDirPath(), ConfigFile)
 }
@@ -96,17 +105,59 @@ func (s *Storage) LoadLastScan() (*ScanRecord, error) {
 	return &record, nil
 }
 
+func (s *Storage) LoadHistory() (*ScanRecord, error) {
+	lastScan, err := s.LoadLastScan()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(s.HistoryPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lastScan, nil
+		}
+		return nil, fmt.Errorf("failed to read scan history: %w", err)
+	}
+
+	var history ScanRecord
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse scan history: %w", err)
+	}
+
+	if lastScan != nil && lastScan.Timestamp.After(history.Timestamp) {
+		return lastScan, nil
+	}
+	return &history, nil
+}
+
+func LoadScanFile(path string) (*ScanRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline scan: %w", err)
+	}
+
+	var record ScanRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline scan: %w", err)
+	}
+
+	return &record, nil
+}
+
 func (s *Storage) SaveScan(record *ScanRecord) error {
 	if err := s.EnsureProbeDir(); err != nil {
 		return fmt.Errorf("failed to create .probe directory: %w", err)
 	}
 
+	return WriteScanRecord(s.LastScanPath(), record)
+}
+
+func WriteScanRecord(path string, record *ScanRecord) error {
 	data, err := json.MarshalIndent(record, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal scan record: %w", err)
 	}
 
-	path := s.LastScanPath()
 	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write scan record: %w", err)
 	}
@@ -114,6 +165,46 @@ func (s *Storage) SaveScan(record *ScanRecord) error {
 	return nil
 }
 
+func (s *Storage) SaveIfChanged(record *ScanRecord) (bool, error) {
+	previous, err := s.LoadLastScan()
+	if err != nil {
+		return false, err
+	}
+
+	if previous != nil && sameFingerprints(record, previous) {
+		if err := WriteScanRecord(s.HistoryPath(), record); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if err := s.SaveScan(record); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func sameFingerprints(current, previous *ScanRecord) bool {
+	if len(current.Issues) != len(previous.Issues) {
+		return false
+	}
+
+	counts := make(map[string]int, len(previous.Issues))
+	for _, issue := range previous.Issues {
+		counts[issue.Fingerprint]++
+	}
+
+	for _, issue := range current.Issues {
+		if counts[issue.Fingerprint] == 0 {
+			return false
+		}
+		counts[issue.Fingerprint]--
+	}
+
+	return true
+}
+
 func ComputeDiff(current, previous *ScanRecord) *ScanDiff {
 	diff := &ScanDiff{
 		HasPrevious: previous != nil,
@@ -156,7 +247,46 @@ func ComputeDiff(current, previous *ScanRecord) *ScanDiff {
 	return diff
 }
 
-func GenerateFingerprint(checkName, severity, message string) string {
+func TrackOccurrences(current, previous *ScanRecord) {
+	counts := make(map[string]int)
+	firstSeen := make(map[string]time.Time)
+	if previous != nil {
+		for _, issue := range previous.Issues {
+			count := issue.Occurrences
+			if count == 0 {
+				count = 1
+			}
+			counts[issue.Fingerprint] = count
+
+			seen := issue.FirstSeen
+			if seen.IsZero() {
+				seen = previous.Timestamp
+			}
+			firstSeen[issue.Fingerprint] = seen
+		}
+	}
+
+	for i := range current.Issues {
+		fingerprint := current.Issues[i].Fingerprint
+		current.Issues[i].Occurrences = counts[fingerprint] + 1
+		if seen, ok := firstSeen[fingerprint]; ok && !seen.IsZero() {
+			current.Issues[i].FirstSeen = seen
+		} else {
+			current.Issues[i].FirstSeen = current.Timestamp
+		}
+	}
+}
+
+var (
+	volatileNumber	= regexp.MustCompile(`(^|[\s(:=,])\d+(\.\d+)?([a-zµ]+\d+(\.\d+)?)*[a-zµ%]*`)
+	numberRun	= regexp.MustCompile(`#(\s+#)+`)
+)
+
+func GenerateFingerprint(checkName, namespace, message string) string {
+	return fmt.Sprintf("%s|%s|%s", checkName, namespace, stableMessage(message))
+}
 
-	return fmt.Sprintf("%s|%s|%s", checkName, severity, message)
+func stableMessage(message string) string {
+	message = volatileNumber.ReplaceAllString(message, "${1}#")
+	return numberRun.ReplaceAllString(message, "#")
 }
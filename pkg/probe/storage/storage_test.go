@@ -125,6 +125,92 @@ func TestLoadLastScanInvalid(t *testing.T) {
 	}
 }
 
+func TestLoadScanFileBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	data := `{"timestamp":"2024-01-01T00:00:00Z","cluster":"golden","summary":{"total":3,"critical":0,"warning":1,"ok":2},"issues":[{"check":"node-status","severity":"WARNING","message":"node pressure","fingerprint":"node-status|WARNING|node pressure"}]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := LoadScanFile(path)
+	if err != nil {
+		t.Fatalf("LoadScanFile failed: %v", err)
+	}
+	if baseline.Cluster != "golden" {
+		t.Errorf("cluster mismatch: got %q, want %q", baseline.Cluster, "golden")
+	}
+	if len(baseline.Issues) != 1 {
+		t.Fatalf("issues count mismatch: got %d, want 1", len(baseline.Issues))
+	}
+
+	current := &ScanRecord{
+		Summary: ScanSummary{Warning: 2},
+		Issues: []StoredIssue{
+			baseline.Issues[0],
+			{CheckName: "pod-status", Severity: "WARNING", Message: "crashloop", Fingerprint: "pod-status|WARNING|crashloop"},
+		},
+	}
+	diff := ComputeDiff(current, baseline)
+	if len(diff.NewIssues) != 1 || diff.NewIssues[0].CheckName != "pod-status" {
+		t.Errorf("expected one new issue since baseline, got %+v", diff.NewIssues)
+	}
+}
+
+func TestLoadScanFileMissing(t *testing.T) {
+	if _, err := LoadScanFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing baseline file")
+	}
+}
+
+func TestTrackOccurrences(t *testing.T) {
+	persisting := StoredIssue{CheckName: "pod-status", Severity: "WARNING", Message: "crashloop", Fingerprint: "pod-status|WARNING|crashloop"}
+	legacy := StoredIssue{CheckName: "node-status", Severity: "WARNING", Message: "pressure", Fingerprint: "node-status|WARNING|pressure"}
+	fresh := StoredIssue{CheckName: "pvc-status", Severity: "WARNING", Message: "pending", Fingerprint: "pvc-status|WARNING|pending"}
+
+	persisting.Occurrences = 3
+	previous := &ScanRecord{Issues: []StoredIssue{persisting, legacy}}
+	current := &ScanRecord{Issues: []StoredIssue{persisting, legacy, fresh}}
+
+	TrackOccurrences(current, previous)
+
+	want := []int{4, 2, 1}
+	for i, issue := range current.Issues {
+		if issue.Occurrences != want[i] {
+			t.Errorf("%s: got %d occurrences, want %d", issue.Fingerprint, issue.Occurrences, want[i])
+		}
+	}
+
+	first := &ScanRecord{Issues: []StoredIssue{fresh}}
+	TrackOccurrences(first, nil)
+	if first.Issues[0].Occurrences != 1 {
+		t.Errorf("expected 1 occurrence without previous scan, got %d", first.Issues[0].Occurrences)
+	}
+}
+
+func TestTrackOccurrencesFirstSeen(t *testing.T) {
+	start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	scan := func(at time.Time, fingerprints ...string) *ScanRecord {
+		record := &ScanRecord{Timestamp: at}
+		for _, fp := range fingerprints {
+			record.Issues = append(record.Issues, StoredIssue{Fingerprint: fp})
+		}
+		return record
+	}
+
+	legacy := scan(start, "a")
+	second := scan(start.Add(24*time.Hour), "a", "b")
+	TrackOccurrences(second, legacy)
+	third := scan(start.Add(48*time.Hour), "a", "b")
+	TrackOccurrences(third, second)
+
+	if !third.Issues[0].FirstSeen.Equal(start) {
+		t.Errorf("issue from a scan without first_seen should date from that scan, got %v", third.Issues[0].FirstSeen)
+	}
+	if !third.Issues[1].FirstSeen.Equal(start.Add(24 * time.Hour)) {
+		t.Errorf("issue should keep the time of the scan it first appeared in, got %v", third.Issues[1].FirstSeen)
+	}
+}
+
 func TestComputeDiffNoPrevious(t *testing.T) {
 	current := &ScanRecord{
 		Summary: ScanSummary{Critical: 1},
@@ -191,13 +277,45 @@ func TestComputeDiffWithPrevious(t *testing.T) {
 }
 
 func TestGenerateFingerprint(t *testing.T) {
-	fp := GenerateFingerprint("check", "WARNING", "message")
-	expected := "check|WARNING|message"
+	fp := GenerateFingerprint("check", "app", "message")
+	expected := "check|app|message"
 	if fp != expected {
 		t.Errorf("expected %q, got %q", expected, fp)
 	}
 }
 
+func TestGenerateFingerprintIgnoresCountsAndDurations(t *testing.T) {
+	stable := []struct {
+		before, after string
+	}{
+		{"Pod app/web pending for 5m", "Pod app/web pending for 2h"},
+		{"Job app/backup has been running for 59m", "Job app/backup has been running for 1d"},
+		{"Pod app/web has 12 BackOff events", "Pod app/web has 240 BackOff events"},
+		{"Stalled resources: 3 total", "Stalled resources: 4 total"},
+	}
+	for _, tc := range stable {
+		if GenerateFingerprint("check", "app", tc.before) != GenerateFingerprint("check", "app", tc.after) {
+			t.Errorf("expected %q and %q to share a fingerprint", tc.before, tc.after)
+		}
+	}
+
+	distinct := []struct {
+		first, second string
+	}{
+		{"Node worker-1 is NotReady", "Node worker-2 is NotReady"},
+		{"Pod app/web-7d9f8 is restarting", "Pod app/web-5c4b2 is restarting"},
+	}
+	for _, tc := range distinct {
+		if GenerateFingerprint("check", "", tc.first) == GenerateFingerprint("check", "", tc.second) {
+			t.Errorf("expected %q and %q to have different fingerprints", tc.first, tc.second)
+		}
+	}
+
+	if GenerateFingerprint("check", "app", "message") == GenerateFingerprint("check", "other", "message") {
+		t.Error("expected the namespace to be part of the fingerprint")
+	}
+}
+
 func TestSaveCreatesDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 	nestedPath := filepath.Join(tmpDir, "nested", "path")
@@ -212,3 +330,64 @@ func TestSaveCreatesDirectory(t *testing.T) {
 		t.Error("probe dir should exist after save")
 	}
 }
+
+func TestSaveIfChangedSkipsIdenticalScan(t *testing.T) {
+	tmpDir := t.TempDir()
+	s := NewStorage(tmpDir)
+
+	first := &ScanRecord{
+		Timestamp: time.Now().Add(-time.Hour),
+		Cluster:   "test-cluster",
+		Issues: []StoredIssue{
+			{CheckName: "pod-status", Severity: "WARNING", Message: "pod issue", Fingerprint: GenerateFingerprint("pod-status", "", "pod issue")},
+			{CheckName: "node-status", Severity: "CRITICAL", Message: "node down", Fingerprint: GenerateFingerprint("node-status", "", "node down")},
+		},
+	}
+
+	saved, err := s.SaveIfChanged(first)
+	if err != nil {
+		t.Fatalf("SaveIfChanged failed: %v", err)
+	}
+	if !saved {
+		t.Fatal("first save should be written")
+	}
+
+	second := &ScanRecord{
+		Timestamp: time.Now(),
+		Cluster:   "test-cluster",
+		Issues:    []StoredIssue{first.Issues[1], first.Issues[0]},
+	}
+
+	saved, err = s.SaveIfChanged(second)
+	if err != nil {
+		t.Fatalf("SaveIfChanged failed: %v", err)
+	}
+	if saved {
+		t.Error("identical scan should not be written")
+	}
+
+	loaded, err := s.LoadLastScan()
+	if err != nil {
+		t.Fatalf("LoadLastScan failed: %v", err)
+	}
+	if !loaded.Timestamp.Equal(first.Timestamp) {
+		t.Error("baseline should still be the first scan")
+	}
+
+	history, err := s.LoadHistory()
+	if err != nil {
+		t.Fatalf("LoadHistory failed: %v", err)
+	}
+	if !history.Timestamp.Equal(second.Timestamp) {
+		t.Error("history should track the unsaved scan")
+	}
+
+	second.Issues = second.Issues[:1]
+	saved, err = s.SaveIfChanged(second)
+	if err != nil {
+		t.Fatalf("SaveIfChanged failed: %v", err)
+	}
+	if !saved {
+		t.Error("changed scan should be written")
+	}
+}
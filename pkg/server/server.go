@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/report"
+)
+
+const (
+	DefaultAddr     = ":8080"
+	DefaultInterval = 5 * time.Minute
+
+	shutdownTimeout = 10 * time.Second
+)
+
+type ScanFunc func(ctx context.Context) ([]probe.CheckResult, error)
+
+type Server struct {
+	addr        string
+	interval    time.Duration
+	clusterInfo string
+	scan        ScanFunc
+	verbose     bool
+
+	mu        sync.RWMutex
+	results   []probe.CheckResult
+	scannedAt time.Time
+	scanErr   error
+}
+
+func New(addr string, interval time.Duration, clusterInfo string, scan ScanFunc) *Server {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Server{
+		addr:        addr,
+		interval:    interval,
+		clusterInfo: clusterInfo,
+		scan:        scan,
+	}
+}
+
+func (s *Server) SetVerbose(verbose bool) {
+	s.verbose = verbose
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/report", s.handleReport)
+	return mux
+}
+
+func (s *Server) Scan(ctx context.Context) error {
+	results, err := s.scan(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scanErr = err
+	if err != nil {
+		return err
+	}
+	s.results = results
+	s.scannedAt = time.Now()
+	return nil
+}
+
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	httpServer := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	if s.verbose {
+		fmt.Fprintf(os.Stderr, "[serve] Listening on %s, scanning every %s\n", listener.Addr(), s.interval)
+	}
+
+	scanCtx, stopScans := context.WithCancel(ctx)
+	defer stopScans()
+	scanDone := make(chan struct{})
+	go func() {
+		defer close(scanDone)
+		s.scanLoop(scanCtx)
+	}()
+
+	select {
+	case err := <-serveErr:
+		stopScans()
+		<-scanDone
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+	<-scanDone
+	return nil
+}
+
+func (s *Server) scanLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Scan(ctx); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Warning: scan failed: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) snapshot() ([]probe.CheckResult, time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	results := append([]probe.CheckResult(nil), s.results...)
+	return results, s.scannedAt, s.scanErr
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	results, scannedAt, scanErr := s.snapshot()
+	if scannedAt.IsZero() {
+		writeUnavailable(w, scanErr)
+		return
+	}
+	registry := report.NewMetricsRegistry(results, scannedAt)
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	results, scannedAt, scanErr := s.snapshot()
+	if scannedAt.IsZero() {
+		writeUnavailable(w, scanErr)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := report.NewWriter(w, report.FormatJSON, false).Write(results, s.clusterInfo); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write report: %v\n", err)
+	}
+}
+
+func writeUnavailable(w http.ResponseWriter, scanErr error) {
+	message := "no scan has completed yet"
+	if scanErr != nil {
+		message = fmt.Sprintf("%s: last scan failed: %v", message, scanErr)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	report.WriteError(w, message, http.StatusServiceUnavailable)
+}
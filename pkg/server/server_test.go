@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/punasusi/cluster-probe/pkg/probe"
+	"github.com/punasusi/cluster-probe/pkg/probe/report"
+)
+
+func testResults() []probe.CheckResult {
+	return []probe.CheckResult{
+		{
+			Name: "pod-status",
+			Tier: 2,
+			Results: []probe.Result{
+				{CheckName: "pod-status", Severity: probe.SeverityWarning, Message: "Pod app/web is restarting"},
+			},
+		},
+	}
+}
+
+func TestServeReportAfterScan(t *testing.T) {
+	scanned := make(chan struct{}, 1)
+	srv := New("", time.Hour, "test-cluster", func(ctx context.Context) ([]probe.CheckResult, error) {
+		defer func() { scanned <- struct{}{} }()
+		return testResults(), nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(ctx, listener)
+	}()
+
+	select {
+	case <-scanned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the first scan")
+	}
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/report")
+	if err != nil {
+		t.Fatalf("GET /report failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var got report.Report
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if got.Cluster != "test-cluster" {
+		t.Errorf("unexpected cluster: %q", got.Cluster)
+	}
+	if got.Summary.Warning != 1 {
+		t.Errorf("expected 1 warning, got %+v", got.Summary)
+	}
+	if len(got.CheckResults) != 1 || got.CheckResults[0].Name != "pod-status" {
+		t.Errorf("unexpected checks: %+v", got.CheckResults)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+func TestHandlerBeforeFirstScan(t *testing.T) {
+	srv := New("", time.Hour, "test-cluster", func(ctx context.Context) ([]probe.CheckResult, error) {
+		return nil, errors.New("connection refused")
+	})
+	srv.Scan(context.Background())
+
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected /healthz to return 200, got %d", resp.StatusCode)
+	}
+
+	for _, path := range []string{"/report", "/metrics"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected %s to return 503 before a scan completes, got %d", path, resp.StatusCode)
+		}
+		if !strings.Contains(string(body), "connection refused") {
+			t.Errorf("expected %s to include the scan error, got %s", path, body)
+		}
+	}
+}
+
+func TestHandlerMetrics(t *testing.T) {
+	srv := New("", time.Hour, "test-cluster", func(ctx context.Context) ([]probe.CheckResult, error) {
+		return testResults(), nil
+	})
+	if err := srv.Scan(context.Background()); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if !strings.Contains(string(body), `cluster_probe_check_severity{check="pod-status",tier="2"} 1`) {
+		t.Errorf("expected check severity metric, got:\n%s", body)
+	}
+}
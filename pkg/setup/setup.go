@@ -6,15 +6,21 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/punasusi/cluster-probe/pkg/k8s"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
@@ -27,17 +33,33 @@ const (
 	TokenSecretName		= "cluster-reader-token"
 )
 
+const (
+	tokenPollInterval	= time.Second
+	tokenWaitTimeout	= 30 * time.Second
+)
+
 type Setup struct {
 	client		kubernetes.Interface
+	restConfig	*rest.Config
 	verbose		bool
 	kubeconfigPath	string
+	namespace	string
+	tokenInterval	time.Duration
+	tokenTimeout	time.Duration
 }
 
-func NewSetup(client kubernetes.Interface, kubeconfigPath string, verbose bool) *Setup {
+func NewSetup(client kubernetes.Interface, restConfig *rest.Config, kubeconfigPath string, namespace string, verbose bool) *Setup {
+	if namespace == "" {
+		namespace = ServiceAccountNamespace
+	}
 	return &Setup{
 		client:		client,
+		restConfig:	restConfig,
 		verbose:	verbose,
 		kubeconfigPath:	kubeconfigPath,
+		namespace:	namespace,
+		tokenInterval:	tokenPollInterval,
+		tokenTimeout:	tokenWaitTimeout,
 	}
 }
 
@@ -48,6 +70,10 @@ func (s *Setup) log(format string, args ...interface{}) {
 }
 
 func (s *Setup) Run(ctx context.Context, outputPath string) error {
+	if err := s.ensureNamespace(ctx); err != nil {
+		return fmt.Errorf("failed to ensure namespace %s: %w", s.namespace, err)
+	}
+
 	s.log("Creating read-only service account...")
 
 	if err := s.createServiceAccount(ctx); err != nil {
@@ -72,7 +98,7 @@ func (s *Setup) Run(ctx context.Context, outputPath string) error {
 		return fmt.Errorf("failed to create token secret: %w", err)
 	}
 
-	token, err := s.getToken(ctx)
+	token, err := s.waitForToken(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get token: %w", err)
 	}
@@ -85,11 +111,94 @@ func (s *Setup) Run(ctx context.Context, outputPath string) error {
 	return nil
 }
 
+func (s *Setup) Preflight(ctx context.Context) error {
+	required := []authorizationv1.ResourceAttributes{
+		{Verb: "get", Resource: "namespaces", Name: s.namespace},
+		{Verb: "create", Resource: "namespaces"},
+		{Verb: "create", Resource: "serviceaccounts", Namespace: s.namespace},
+		{Verb: "create", Resource: "secrets", Namespace: s.namespace},
+		{Verb: "get", Resource: "secrets", Namespace: s.namespace},
+		{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+		{Verb: "get", Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+		{Verb: "update", Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+		{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+		{Verb: "update", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+	}
+
+	var missing []string
+	for i := range required {
+		attrs := required[i]
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+		}
+		result, err := s.client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to check permission to %s: %w", describePermission(attrs), err)
+		}
+		if result.Status.Allowed {
+			s.log("Preflight: allowed to %s", describePermission(attrs))
+			continue
+		}
+		permission := describePermission(attrs)
+		if result.Status.Reason != "" {
+			permission = fmt.Sprintf("%s (%s)", permission, result.Status.Reason)
+		}
+		missing = append(missing, permission)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("current user is missing permissions required for setup: %s", strings.Join(missing, "; "))
+	}
+	return nil
+}
+
+func describePermission(attrs authorizationv1.ResourceAttributes) string {
+	resource := attrs.Resource
+	if attrs.Group != "" {
+		resource = attrs.Resource + "." + attrs.Group
+	}
+	if attrs.Namespace != "" {
+		return fmt.Sprintf("%s %s in namespace %s", attrs.Verb, resource, attrs.Namespace)
+	}
+	return fmt.Sprintf("%s %s", attrs.Verb, resource)
+}
+
+func (s *Setup) ensureNamespace(ctx context.Context) error {
+	_, err := s.client.CoreV1().Namespaces().Get(ctx, s.namespace, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return err
+	}
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:	s.namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":	"cluster-probe",
+				"app.kubernetes.io/managed-by":	"cluster-probe",
+			},
+		},
+	}
+
+	_, err = s.client.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+
+	s.log("Created Namespace %s", s.namespace)
+	return nil
+}
+
 func (s *Setup) createServiceAccount(ctx context.Context) error {
 	sa := &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:		ServiceAccountName,
-			Namespace:	ServiceAccountNamespace,
+			Namespace:	s.namespace,
 			Labels: map[string]string{
 				"app.kubernetes.io/name":	"cluster-probe",
 				"app.kubernetes.io/managed-by":	"cluster-probe",
@@ -97,7 +206,7 @@ func (s *Setup) createServiceAccount(ctx context.Context) error {
 		},
 	}
 
-	_, err := s.client.CoreV1().ServiceAccounts(ServiceAccountNamespace).Create(ctx, sa, metav1.CreateOptions{})
+	_, err := s.client.CoreV1().ServiceAccounts(s.namespace).Create(ctx, sa, metav1.CreateOptions{})
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			s.log("ServiceAccount already exists")
@@ -106,18 +215,16 @@ func (s *Setup) createServiceAccount(ctx context.Context) error {
 		return err
 	}
 
-	s.log("Created ServiceAccount %s/%s", ServiceAccountNamespace, ServiceAccountName)
+	s.log("Created ServiceAccount %s/%s", s.namespace, ServiceAccountName)
 	return nil
 }
 
 func (s *Setup) getCRDAPIGroups(ctx context.Context) ([]string, error) {
-
-	config, err := clientcmd.BuildConfigFromFlags("", s.kubeconfigPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build config: %w", err)
+	if s.restConfig == nil {
+		return nil, fmt.Errorf("no REST config available")
 	}
 
-	apiextClient, err := apiextensionsclient.NewForConfig(config)
+	apiextClient, err := apiextensionsclient.NewForConfig(s.restConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create apiextensions client: %w", err)
 	}
@@ -348,7 +455,7 @@ func (s *Setup) createClusterRoleBinding(ctx context.Context) error {
 			{
 				Kind:		"ServiceAccount",
 				Name:		ServiceAccountName,
-				Namespace:	ServiceAccountNamespace,
+				Namespace:	s.namespace,
 			},
 		},
 	}
@@ -371,7 +478,7 @@ func (s *Setup) createTokenSecret(ctx context.Context) error {
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:		TokenSecretName,
-			Namespace:	ServiceAccountNamespace,
+			Namespace:	s.namespace,
 			Labels: map[string]string{
 				"app.kubernetes.io/name":	"cluster-probe",
 				"app.kubernetes.io/managed-by":	"cluster-probe",
@@ -383,7 +490,7 @@ func (s *Setup) createTokenSecret(ctx context.Context) error {
 		Type:	corev1.SecretTypeServiceAccountToken,
 	}
 
-	_, err := s.client.CoreV1().Secrets(ServiceAccountNamespace).Create(ctx, secret, metav1.CreateOptions{})
+	_, err := s.client.CoreV1().Secrets(s.namespace).Create(ctx, secret, metav1.CreateOptions{})
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			s.log("Token Secret already exists")
@@ -392,13 +499,13 @@ func (s *Setup) createTokenSecret(ctx context.Context) error {
 		return err
 	}
 
-	s.log("Created token Secret %s/%s", ServiceAccountNamespace, TokenSecretName)
+	s.log("Created token Secret %s/%s", s.namespace, TokenSecretName)
 	return nil
 }
 
 func (s *Setup) getToken(ctx context.Context) (string, error) {
 
-	secret, err := s.client.CoreV1().Secrets(ServiceAccountNamespace).Get(ctx, TokenSecretName, metav1.GetOptions{})
+	secret, err := s.client.CoreV1().Secrets(s.namespace).Get(ctx, TokenSecretName, metav1.GetOptions{})
 	if err != nil {
 		return "", fmt.Errorf("failed to get token secret: %w", err)
 	}
@@ -412,9 +519,33 @@ func (s *Setup) getToken(ctx context.Context) (string, error) {
 	return string(token), nil
 }
 
+func (s *Setup) waitForToken(ctx context.Context) (string, error) {
+	var token string
+	var lastErr error
+
+	err := wait.PollUntilContextTimeout(ctx, s.tokenInterval, s.tokenTimeout, true, func(ctx context.Context) (bool, error) {
+		t, err := s.getToken(ctx)
+		if err != nil {
+			lastErr = err
+			s.log("Token not ready: %v", err)
+			return false, nil
+		}
+		token = t
+		return true, nil
+	})
+	if err != nil {
+		if lastErr != nil {
+			return "", fmt.Errorf("token not available after %s: %w", s.tokenTimeout, lastErr)
+		}
+		return "", err
+	}
+
+	return token, nil
+}
+
 func (s *Setup) generateKubeconfig(ctx context.Context, outputPath string, token string) error {
 
-	config, err := clientcmd.LoadFromFile(s.kubeconfigPath)
+	config, err := k8s.LoadingRules(s.kubeconfigPath).Load()
 	if err != nil {
 		return fmt.Errorf("failed to load source kubeconfig: %w", err)
 	}
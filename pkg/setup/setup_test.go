@@ -2,19 +2,27 @@ package setup
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestNewSetup(t *testing.T) {
 	client := fake.NewSimpleClientset()
-	s := NewSetup(client, "/path/to/kubeconfig", true)
+	s := NewSetup(client, nil, "/path/to/kubeconfig", "", true)
 
 	if s == nil {
 		t.Fatal("NewSetup returned nil")
@@ -30,9 +38,137 @@ func TestNewSetup(t *testing.T) {
 	}
 }
 
+func TestGetCRDAPIGroupsUsesProvidedRESTConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/apiextensions.k8s.io/v1/customresourcedefinitions" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"kind":"CustomResourceDefinitionList","apiVersion":"apiextensions.k8s.io/v1","items":[{"metadata":{"name":"widgets.example.com"},"spec":{"group":"example.com"}}]}`))
+	}))
+	defer server.Close()
+
+	restConfig := &rest.Config{Host: server.URL, TLSClientConfig: rest.TLSClientConfig{Insecure: true}}
+	s := NewSetup(fake.NewSimpleClientset(), restConfig, "/nonexistent/config", "", false)
+
+	groups, err := s.getCRDAPIGroups(context.Background())
+	if err != nil {
+		t.Fatalf("getCRDAPIGroups failed: %v", err)
+	}
+	if len(groups) != 1 || groups[0] != "example.com" {
+		t.Errorf("expected [example.com], got %v", groups)
+	}
+}
+
+func TestNewSetupDefaultNamespace(t *testing.T) {
+	s := NewSetup(fake.NewSimpleClientset(), nil, "", "", false)
+	if s.namespace != ServiceAccountNamespace {
+		t.Errorf("expected default namespace %q, got %q", ServiceAccountNamespace, s.namespace)
+	}
+}
+
+func TestSetupCustomNamespace(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	s := NewSetup(client, nil, "", "probe-system", false)
+	ctx := context.Background()
+
+	if err := s.ensureNamespace(ctx); err != nil {
+		t.Fatalf("ensureNamespace failed: %v", err)
+	}
+	if _, err := client.CoreV1().Namespaces().Get(ctx, "probe-system", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected namespace to be created: %v", err)
+	}
+
+	if err := s.createServiceAccount(ctx); err != nil {
+		t.Fatalf("createServiceAccount failed: %v", err)
+	}
+	if _, err := client.CoreV1().ServiceAccounts("probe-system").Get(ctx, ServiceAccountName, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected service account in custom namespace: %v", err)
+	}
+
+	if err := s.createClusterRoleBinding(ctx); err != nil {
+		t.Fatalf("createClusterRoleBinding failed: %v", err)
+	}
+	binding, err := client.RbacV1().ClusterRoleBindings().Get(ctx, ClusterRoleBindingName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get created binding: %v", err)
+	}
+	if binding.Subjects[0].Namespace != "probe-system" {
+		t.Errorf("unexpected subject namespace: %s", binding.Subjects[0].Namespace)
+	}
+
+	if err := s.createTokenSecret(ctx); err != nil {
+		t.Fatalf("createTokenSecret failed: %v", err)
+	}
+	if _, err := client.CoreV1().Secrets("probe-system").Get(ctx, TokenSecretName, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected token secret in custom namespace: %v", err)
+	}
+}
+
+func selfSubjectAccessReactor(denied map[string]bool) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = !denied[attrs.Resource] && !denied[attrs.Verb+" "+attrs.Resource]
+		return true, review, nil
+	}
+}
+
+func TestPreflightAllowed(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", selfSubjectAccessReactor(nil))
+
+	if err := NewSetup(client, nil, "", "probe-system", false).Preflight(context.Background()); err != nil {
+		t.Fatalf("Preflight failed: %v", err)
+	}
+}
+
+func TestPreflightMissingPermission(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", selfSubjectAccessReactor(map[string]bool{"clusterrolebindings": true}))
+
+	err := NewSetup(client, nil, "", "probe-system", false).Preflight(context.Background())
+	if err == nil {
+		t.Fatal("expected an error when clusterrolebindings cannot be created")
+	}
+	if !strings.Contains(err.Error(), "create clusterrolebindings.rbac.authorization.k8s.io") {
+		t.Errorf("expected the missing permission in the error, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "serviceaccounts") || strings.Contains(err.Error(), "create clusterroles.") {
+		t.Errorf("error should only name missing permissions, got: %v", err)
+	}
+}
+
+func TestPreflightChecksSetupReruns(t *testing.T) {
+	for _, permission := range []string{"get namespaces", "create namespaces", "get secrets", "update clusterroles", "update clusterrolebindings"} {
+		client := fake.NewSimpleClientset()
+		client.PrependReactor("create", "selfsubjectaccessreviews", selfSubjectAccessReactor(map[string]bool{permission: true}))
+
+		err := NewSetup(client, nil, "", "probe-system", false).Preflight(context.Background())
+		if err == nil {
+			t.Errorf("expected an error when the user cannot %s", permission)
+			continue
+		}
+		if !strings.Contains(err.Error(), permission) {
+			t.Errorf("expected %q in the error, got: %v", permission, err)
+		}
+	}
+}
+
+func TestEnsureNamespaceExisting(t *testing.T) {
+	existing := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "probe-system"}}
+	client := fake.NewSimpleClientset(existing)
+	s := NewSetup(client, nil, "", "probe-system", false)
+
+	if err := s.ensureNamespace(context.Background()); err != nil {
+		t.Errorf("ensureNamespace should not error when namespace exists: %v", err)
+	}
+}
+
 func TestCreateServiceAccount(t *testing.T) {
 	client := fake.NewSimpleClientset()
-	s := NewSetup(client, "", false)
+	s := NewSetup(client, nil, "", "", false)
 	ctx := context.Background()
 
 	if err := s.createServiceAccount(ctx); err != nil {
@@ -60,7 +196,7 @@ func TestCreateServiceAccountAlreadyExists(t *testing.T) {
 		},
 	}
 	client := fake.NewSimpleClientset(existingSA)
-	s := NewSetup(client, "", false)
+	s := NewSetup(client, nil, "", "", false)
 	ctx := context.Background()
 
 	if err := s.createServiceAccount(ctx); err != nil {
@@ -70,7 +206,7 @@ func TestCreateServiceAccountAlreadyExists(t *testing.T) {
 
 func TestCreateClusterRole(t *testing.T) {
 	client := fake.NewSimpleClientset()
-	s := NewSetup(client, "", false)
+	s := NewSetup(client, nil, "", "", false)
 	ctx := context.Background()
 
 	crdGroups := []string{"custom.example.com", "apps.example.com"}
@@ -108,7 +244,7 @@ func TestCreateClusterRoleAlreadyExists(t *testing.T) {
 		Rules: []rbacv1.PolicyRule{},
 	}
 	client := fake.NewSimpleClientset(existingRole)
-	s := NewSetup(client, "", false)
+	s := NewSetup(client, nil, "", "", false)
 	ctx := context.Background()
 
 	if err := s.createClusterRole(ctx, []string{}); err != nil {
@@ -123,7 +259,7 @@ func TestCreateClusterRoleAlreadyExists(t *testing.T) {
 
 func TestCreateClusterRoleBinding(t *testing.T) {
 	client := fake.NewSimpleClientset()
-	s := NewSetup(client, "", false)
+	s := NewSetup(client, nil, "", "", false)
 	ctx := context.Background()
 
 	if err := s.createClusterRoleBinding(ctx); err != nil {
@@ -153,7 +289,7 @@ func TestCreateClusterRoleBindingAlreadyExists(t *testing.T) {
 		},
 	}
 	client := fake.NewSimpleClientset(existingBinding)
-	s := NewSetup(client, "", false)
+	s := NewSetup(client, nil, "", "", false)
 	ctx := context.Background()
 
 	if err := s.createClusterRoleBinding(ctx); err != nil {
@@ -163,7 +299,7 @@ func TestCreateClusterRoleBindingAlreadyExists(t *testing.T) {
 
 func TestCreateTokenSecret(t *testing.T) {
 	client := fake.NewSimpleClientset()
-	s := NewSetup(client, "", false)
+	s := NewSetup(client, nil, "", "", false)
 	ctx := context.Background()
 
 	if err := s.createTokenSecret(ctx); err != nil {
@@ -191,7 +327,7 @@ func TestCreateTokenSecretAlreadyExists(t *testing.T) {
 		},
 	}
 	client := fake.NewSimpleClientset(existingSecret)
-	s := NewSetup(client, "", false)
+	s := NewSetup(client, nil, "", "", false)
 	ctx := context.Background()
 
 	if err := s.createTokenSecret(ctx); err != nil {
@@ -210,7 +346,7 @@ func TestGetToken(t *testing.T) {
 		},
 	}
 	client := fake.NewSimpleClientset(secret)
-	s := NewSetup(client, "", false)
+	s := NewSetup(client, nil, "", "", false)
 	ctx := context.Background()
 
 	token, err := s.getToken(ctx)
@@ -224,7 +360,7 @@ func TestGetToken(t *testing.T) {
 
 func TestGetTokenMissing(t *testing.T) {
 	client := fake.NewSimpleClientset()
-	s := NewSetup(client, "", false)
+	s := NewSetup(client, nil, "", "", false)
 	ctx := context.Background()
 
 	_, err := s.getToken(ctx)
@@ -242,7 +378,7 @@ func TestGetTokenEmpty(t *testing.T) {
 		Data: map[string][]byte{},
 	}
 	client := fake.NewSimpleClientset(secret)
-	s := NewSetup(client, "", false)
+	s := NewSetup(client, nil, "", "", false)
 	ctx := context.Background()
 
 	_, err := s.getToken(ctx)
@@ -251,6 +387,80 @@ func TestGetTokenEmpty(t *testing.T) {
 	}
 }
 
+func TestWaitForTokenRetriesUntilPopulated(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	attempts := 0
+	client.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		attempts++
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      TokenSecretName,
+				Namespace: ServiceAccountNamespace,
+			},
+			Data: map[string][]byte{},
+		}
+		if attempts >= 2 {
+			secret.Data["token"] = []byte("populated-token")
+		}
+		return true, secret, nil
+	})
+
+	s := NewSetup(client, nil, "", "", false)
+	s.tokenInterval = time.Millisecond
+
+	token, err := s.waitForToken(context.Background())
+	if err != nil {
+		t.Fatalf("waitForToken failed: %v", err)
+	}
+	if token != "populated-token" {
+		t.Errorf("unexpected token: %s", token)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestWaitForTokenAppearsAfterDelay(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	populateAt := time.Now().Add(50 * time.Millisecond)
+	client.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      TokenSecretName,
+				Namespace: ServiceAccountNamespace,
+			},
+			Data: map[string][]byte{},
+		}
+		if time.Now().After(populateAt) {
+			secret.Data["token"] = []byte("delayed-token")
+		}
+		return true, secret, nil
+	})
+
+	s := NewSetup(client, nil, "", "", false)
+	s.tokenInterval = 10 * time.Millisecond
+	s.tokenTimeout = 5 * time.Second
+
+	token, err := s.waitForToken(context.Background())
+	if err != nil {
+		t.Fatalf("waitForToken failed: %v", err)
+	}
+	if token != "delayed-token" {
+		t.Errorf("unexpected token: %s", token)
+	}
+}
+
+func TestWaitForTokenGivesUp(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	s := NewSetup(client, nil, "", "", false)
+	s.tokenInterval = time.Millisecond
+	s.tokenTimeout = 20 * time.Millisecond
+
+	if _, err := s.waitForToken(context.Background()); err == nil {
+		t.Error("expected error when token never becomes available")
+	}
+}
+
 func TestProbeKubeconfigPath(t *testing.T) {
 	path := ProbeKubeconfigPath()
 	if path != ".kube/probe.yaml" {
@@ -307,7 +517,7 @@ users:
 	}
 
 	client := fake.NewSimpleClientset()
-	s := NewSetup(client, sourcePath, false)
+	s := NewSetup(client, nil, sourcePath, "", false)
 	ctx := context.Background()
 
 	outputPath := filepath.Join(tmpDir, "output", "probe.yaml")
@@ -333,7 +543,7 @@ users:
 
 func TestGenerateKubeconfigInvalidSource(t *testing.T) {
 	client := fake.NewSimpleClientset()
-	s := NewSetup(client, "/nonexistent/config", false)
+	s := NewSetup(client, nil, "/nonexistent/config", "", false)
 	ctx := context.Background()
 
 	err := s.generateKubeconfig(ctx, "/tmp/output", "token")